@@ -0,0 +1,188 @@
+// Package balancer spreads outbound requests over a set of upstream endpoints
+// and keeps track of their health, so requests are not sent to endpoints that
+// are known to be down.
+//
+// Example usage:
+//
+//	pool := balancer.NewPool([]string{"http://10.0.0.1:8080", "http://10.0.0.2:8080"})
+//	go pool.Run(ctx)
+//
+//	clienthelper.Configure(clienthelper.WithHost("users", clienthelper.Host{
+//	    BaseURL: "http://users",
+//	    Pool:    pool,
+//	}))
+//	user, err := clienthelper.Call[User](ctx, nil, http.MethodGet, "users/v1/users/"+id, nil)
+package balancer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aeramu/apihelper/exception"
+)
+
+// Pool is a set of upstream endpoints with health tracking.
+// It is safe for concurrent use.
+type Pool struct {
+	cfg       config
+	endpoints []string
+	next      atomic.Uint64
+	ring      *ring
+
+	mu      sync.RWMutex
+	healthy map[string]bool
+}
+
+// EndpointStatus describes the state of an endpoint
+type EndpointStatus struct {
+	Endpoint string `json:"endpoint"`
+	Healthy  bool   `json:"healthy"`
+}
+
+// NewPool creates a Pool with all endpoints initially considered healthy
+func NewPool(endpoints []string, opts ...Option) *Pool {
+	cfg := defaultConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	healthy := make(map[string]bool, len(endpoints))
+	for _, endpoint := range endpoints {
+		healthy[endpoint] = true
+	}
+	return &Pool{
+		cfg:       cfg,
+		endpoints: append([]string(nil), endpoints...),
+		ring:      newRing(endpoints),
+		healthy:   healthy,
+	}
+}
+
+// Pick returns the next healthy endpoint in round-robin order.
+// It returns an UNAVAILABLE exception when no endpoint is healthy.
+func (p *Pool) Pick() (string, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	n := uint64(len(p.endpoints))
+	start := p.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		endpoint := p.endpoints[(start+i)%n]
+		if p.healthy[endpoint] {
+			return endpoint, nil
+		}
+	}
+	return "", errNoHealthyEndpoint
+}
+
+// PickByKey returns the healthy endpoint owning the affinity key, such as a
+// tenant ID, on a consistent hash ring, so requests for the same key keep
+// hitting the same upstream and benefit from its caches. To avoid hot spots,
+// an endpoint is skipped once its in-flight requests exceed the configured
+// load factor times the average; done must be called when the request completes.
+// It returns an UNAVAILABLE exception when no endpoint is healthy.
+func (p *Pool) PickByKey(key string) (endpoint string, done func(), err error) {
+	p.mu.RLock()
+	healthyCount := 0
+	for _, ok := range p.healthy {
+		if ok {
+			healthyCount++
+		}
+	}
+	endpoint, ok := p.ring.pick(key, p.cfg.loadFactor, func(endpoint string) bool {
+		return p.healthy[endpoint]
+	}, healthyCount)
+	p.mu.RUnlock()
+
+	if !ok {
+		return "", func() {}, errNoHealthyEndpoint
+	}
+	var once sync.Once
+	return endpoint, func() {
+		once.Do(func() {
+			p.ring.release(endpoint)
+		})
+	}, nil
+}
+
+// MarkHealthy records the health of an endpoint, e.g. after a failed request
+func (p *Pool) MarkHealthy(endpoint string, healthy bool) {
+	p.mu.Lock()
+	previous, ok := p.healthy[endpoint]
+	if ok {
+		p.healthy[endpoint] = healthy
+	}
+	p.mu.Unlock()
+
+	if ok && previous != healthy && p.cfg.onStateChange != nil {
+		p.cfg.onStateChange(endpoint, healthy)
+	}
+}
+
+// Status returns the state of all endpoints
+func (p *Pool) Status() []EndpointStatus {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	statuses := make([]EndpointStatus, 0, len(p.endpoints))
+	for _, endpoint := range p.endpoints {
+		statuses = append(statuses, EndpointStatus{
+			Endpoint: endpoint,
+			Healthy:  p.healthy[endpoint],
+		})
+	}
+	return statuses
+}
+
+// Run probes the readiness path of every endpoint at the configured interval
+// until ctx is done. Endpoints answering with a 2xx status are healthy.
+func (p *Pool) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.interval)
+	defer ticker.Stop()
+
+	for {
+		p.Probe(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Probe checks the readiness of every endpoint once
+func (p *Pool) Probe(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, endpoint := range p.endpoints {
+		wg.Add(1)
+		go func(endpoint string) {
+			defer wg.Done()
+			p.MarkHealthy(endpoint, p.probe(ctx, endpoint))
+		}(endpoint)
+	}
+	wg.Wait()
+}
+
+func (p *Pool) probe(ctx context.Context, endpoint string) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+p.cfg.healthPath, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := p.cfg.client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode >= 200 && resp.StatusCode < 300
+}
+
+var errNoHealthyEndpoint = exception.New("no healthy upstream endpoint",
+	exception.WithStatus(exception.StatusUnavailable),
+	exception.WithCode(exception.CodeUnavailable),
+	exception.WithMessage("service unavailable"),
+)
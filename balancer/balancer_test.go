@@ -0,0 +1,93 @@
+package balancer_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aeramu/apihelper/balancer"
+	"github.com/aeramu/apihelper/exception"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPool_Probe(t *testing.T) {
+	ready := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ready.Close()
+	notReady := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer notReady.Close()
+
+	var transitions []string
+	pool := balancer.NewPool([]string{ready.URL, notReady.URL},
+		balancer.WithStateChangeHook(func(endpoint string, healthy bool) {
+			transitions = append(transitions, endpoint)
+		}),
+	)
+
+	pool.Probe(context.Background())
+
+	assert.Equal(t, []string{notReady.URL}, transitions)
+	for i := 0; i < 4; i++ {
+		endpoint, err := pool.Pick()
+		assert.NoError(t, err)
+		assert.Equal(t, ready.URL, endpoint)
+	}
+}
+
+func TestPool_NoHealthyEndpoint(t *testing.T) {
+	pool := balancer.NewPool([]string{"http://a", "http://b"})
+	pool.MarkHealthy("http://a", false)
+	pool.MarkHealthy("http://b", false)
+
+	_, err := pool.Pick()
+
+	assert.True(t, exception.HasStatus(err, exception.StatusUnavailable))
+}
+
+func TestPool_PickByKey(t *testing.T) {
+	pool := balancer.NewPool([]string{"http://a", "http://b", "http://c"})
+
+	first, done, err := pool.PickByKey("tenant-1")
+	assert.NoError(t, err)
+	done()
+	for i := 0; i < 5; i++ {
+		endpoint, done, err := pool.PickByKey("tenant-1")
+		assert.NoError(t, err)
+		assert.Equal(t, first, endpoint)
+		done()
+	}
+
+	pool.MarkHealthy(first, false)
+	endpoint, done, err := pool.PickByKey("tenant-1")
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, endpoint)
+	done()
+}
+
+func TestPool_PickByKey_BoundedLoad(t *testing.T) {
+	pool := balancer.NewPool([]string{"http://a", "http://b"}, balancer.WithLoadFactor(1))
+
+	counts := map[string]int{}
+	for i := 0; i < 4; i++ {
+		endpoint, _, err := pool.PickByKey("tenant-1")
+		assert.NoError(t, err)
+		counts[endpoint]++
+	}
+
+	assert.Equal(t, map[string]int{"http://a": 2, "http://b": 2}, counts)
+}
+
+func TestWithLoadFactor_Invalid(t *testing.T) {
+	assert.Panics(t, func() { balancer.WithLoadFactor(0.5) })
+	assert.NotPanics(t, func() { balancer.WithLoadFactor(1) })
+}
+
+func TestWithHealthCheck_Invalid(t *testing.T) {
+	assert.Panics(t, func() { balancer.WithHealthCheck("/ready", 0) })
+	assert.NotPanics(t, func() { balancer.WithHealthCheck("/ready", time.Second) })
+}
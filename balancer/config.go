@@ -0,0 +1,66 @@
+package balancer
+
+import (
+	"net/http"
+	"time"
+)
+
+// Configuration options
+type config struct {
+	client        *http.Client
+	healthPath    string
+	interval      time.Duration
+	onStateChange func(endpoint string, healthy bool)
+	loadFactor    float64
+}
+
+// Option represents a configuration option for the Pool
+type Option func(*config)
+
+// defaultConfig represents the default configuration
+var defaultConfig = config{
+	client:     &http.Client{Timeout: 2 * time.Second},
+	healthPath: "/readyz",
+	interval:   10 * time.Second,
+	loadFactor: 1.25,
+}
+
+// WithHTTPClient sets the HTTP client used for health probes
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *config) {
+		c.client = client
+	}
+}
+
+// WithHealthCheck sets the readiness path probed on every endpoint and the
+// probing interval. It panics when interval is not positive, as Run could
+// not schedule the probes.
+func WithHealthCheck(path string, interval time.Duration) Option {
+	if interval <= 0 {
+		panic("balancer: WithHealthCheck requires a positive interval")
+	}
+	return func(c *config) {
+		c.healthPath = path
+		c.interval = interval
+	}
+}
+
+// WithStateChangeHook sets a hook invoked whenever an endpoint becomes healthy
+// or unhealthy, typically used to record metrics on endpoint state transitions
+func WithStateChangeHook(hook func(endpoint string, healthy bool)) Option {
+	return func(c *config) {
+		c.onStateChange = hook
+	}
+}
+
+// WithLoadFactor sets how far above the average in-flight load an endpoint may
+// go before PickByKey moves keys to the next endpoint on the ring. It panics
+// when factor is below 1, as the endpoints could then not take every request.
+func WithLoadFactor(factor float64) Option {
+	if !(factor >= 1) {
+		panic("balancer: WithLoadFactor requires a factor of at least 1")
+	}
+	return func(c *config) {
+		c.loadFactor = factor
+	}
+}
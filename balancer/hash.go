@@ -0,0 +1,77 @@
+package balancer
+
+import (
+	"hash/crc32"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// replicas is the number of virtual nodes per endpoint on the hash ring
+const replicas = 100
+
+// ring is a consistent hash ring with bounded load
+type ring struct {
+	hashes []uint32
+	owners map[uint32]string
+
+	mu       sync.Mutex
+	load     map[string]int
+	inFlight int
+}
+
+func newRing(endpoints []string) *ring {
+	r := &ring{
+		owners: make(map[uint32]string, len(endpoints)*replicas),
+		load:   make(map[string]int, len(endpoints)),
+	}
+	for _, endpoint := range endpoints {
+		for i := 0; i < replicas; i++ {
+			h := crc32.ChecksumIEEE([]byte(endpoint + "#" + strconv.Itoa(i)))
+			if _, ok := r.owners[h]; ok {
+				continue
+			}
+			r.owners[h] = endpoint
+			r.hashes = append(r.hashes, h)
+		}
+	}
+	sort.Slice(r.hashes, func(i, j int) bool {
+		return r.hashes[i] < r.hashes[j]
+	})
+	return r
+}
+
+// pick walks the ring clockwise from the key position and returns the first
+// eligible endpoint whose load stays within loadFactor times the average load
+func (r *ring) pick(key string, loadFactor float64, eligible func(string) bool, eligibleCount int) (string, bool) {
+	if len(r.hashes) == 0 || eligibleCount == 0 {
+		return "", false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	maxLoad := int(math.Ceil(loadFactor * float64(r.inFlight+1) / float64(eligibleCount)))
+	h := crc32.ChecksumIEEE([]byte(key))
+	start := sort.Search(len(r.hashes), func(i int) bool {
+		return r.hashes[i] >= h
+	})
+	for i := 0; i < len(r.hashes); i++ {
+		endpoint := r.owners[r.hashes[(start+i)%len(r.hashes)]]
+		if !eligible(endpoint) || r.load[endpoint] >= maxLoad {
+			continue
+		}
+		r.load[endpoint]++
+		r.inFlight++
+		return endpoint, true
+	}
+	return "", false
+}
+
+func (r *ring) release(endpoint string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.load[endpoint]--
+	r.inFlight--
+}
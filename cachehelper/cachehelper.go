@@ -0,0 +1,141 @@
+package cachehelper
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/aeramu/apihelper/exception"
+)
+
+// Package cachehelper implements the read-through cache pattern used by read
+// endpoints: concurrent misses are collapsed into a single load, stale entries
+// are served while being revalidated in the background, and NOT_FOUND results
+// are cached so missing keys don't hammer the backing store.
+//
+// Example usage:
+//
+//	user, err := cachehelper.GetOrLoad(ctx, store, "user:"+id, time.Minute,
+//	    func(ctx context.Context) (User, error) {
+//	        return repo.FindUser(ctx, id)
+//	    },
+//	    cachehelper.WithStaleTTL(5*time.Minute),
+//	)
+
+// Configuration options
+type config struct {
+	staleTTL     time.Duration
+	negativeTTL  time.Duration
+	onRevalidate func(ctx context.Context, key string, err error)
+}
+
+// Option represents a configuration option for GetOrLoad
+type Option func(*config)
+
+// WithStaleTTL sets how long an entry may be served stale after its TTL
+// while it is revalidated in the background
+func WithStaleTTL(ttl time.Duration) Option {
+	return func(c *config) {
+		c.staleTTL = ttl
+	}
+}
+
+// WithNegativeTTL sets how long NOT_FOUND results are cached, zero disables negative caching
+func WithNegativeTTL(ttl time.Duration) Option {
+	return func(c *config) {
+		c.negativeTTL = ttl
+	}
+}
+
+// WithRevalidationHook sets a hook called when the background revalidation
+// of a stale entry fails, e.g. to log the error. A panicking loader is
+// reported as an INTERNAL exception, see exception.FromPanic.
+func WithRevalidationHook(hook func(ctx context.Context, key string, err error)) Option {
+	return func(c *config) {
+		c.onRevalidate = hook
+	}
+}
+
+// GetOrLoad returns the value cached under key, calling loader on a miss.
+// Loader errors are returned unchanged; a NOT_FOUND exception from the loader
+// is cached and returned as exception.ErrorNotFound on subsequent hits.
+// Store failures are not fatal: the value is loaded directly instead.
+func GetOrLoad[T any](ctx context.Context, store Store, key string, ttl time.Duration, loader func(ctx context.Context) (T, error), opts ...Option) (T, error) {
+	cfg := config{
+		negativeTTL: ttl,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	loads := groupFor(store)
+	load := func(ctx context.Context) (Entry, error) {
+		return loads.do(key, func() (Entry, error) {
+			return loadEntry(ctx, store, key, ttl, cfg, loader)
+		})
+	}
+
+	entry, ok, err := store.Get(ctx, key)
+	if err != nil || !ok {
+		entry, err = load(ctx)
+		if err != nil {
+			var zero T
+			return zero, err
+		}
+	} else if time.Now().After(entry.FreshUntil) {
+		go revalidate(context.WithoutCancel(ctx), key, cfg, load)
+	}
+
+	return decode[T](entry)
+}
+
+// revalidate reloads a stale entry in the background, recovering loader
+// panics so they do not crash the process
+func revalidate(ctx context.Context, key string, cfg config, load func(ctx context.Context) (Entry, error)) {
+	var err error
+	defer func() {
+		if v := recover(); v != nil {
+			err = exception.FromPanic(v)
+		}
+		if err != nil && cfg.onRevalidate != nil {
+			cfg.onRevalidate(ctx, key, err)
+		}
+	}()
+	_, err = load(ctx)
+}
+
+// loadEntry calls the loader and stores its result
+func loadEntry[T any](ctx context.Context, store Store, key string, ttl time.Duration, cfg config, loader func(ctx context.Context) (T, error)) (Entry, error) {
+	value, err := loader(ctx)
+	if err != nil {
+		if cfg.negativeTTL > 0 && exception.HasStatus(err, exception.StatusNotFound) {
+			store.Set(ctx, key, Entry{
+				NotFound:   true,
+				FreshUntil: time.Now().Add(cfg.negativeTTL),
+			}, cfg.negativeTTL)
+		}
+		return Entry{}, err
+	}
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		return Entry{}, exception.Wrap(err, "failed to encode cache value")
+	}
+	entry := Entry{
+		Value:      b,
+		FreshUntil: time.Now().Add(ttl),
+	}
+	store.Set(ctx, key, entry, ttl+cfg.staleTTL)
+	return entry, nil
+}
+
+func decode[T any](entry Entry) (T, error) {
+	var value T
+	if entry.NotFound {
+		return value, exception.ErrorNotFound
+	}
+	if err := json.Unmarshal(entry.Value, &value); err != nil {
+		return value, exception.Wrap(err, "failed to decode cache value")
+	}
+	return value, nil
+}
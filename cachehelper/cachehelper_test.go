@@ -0,0 +1,181 @@
+package cachehelper_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aeramu/apihelper/cachehelper"
+	"github.com/aeramu/apihelper/exception"
+	"github.com/stretchr/testify/assert"
+)
+
+type User struct {
+	Name string
+}
+
+func TestGetOrLoad_Singleflight(t *testing.T) {
+	store := cachehelper.NewMemoryStore()
+	var calls int32
+	loader := func(ctx context.Context) (User, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(20 * time.Millisecond)
+		return User{Name: "foo"}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			user, err := cachehelper.GetOrLoad(context.Background(), store, "user:1", time.Minute, loader)
+			assert.NoError(t, err)
+			assert.Equal(t, "foo", user.Name)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls)
+}
+
+func TestGetOrLoad_StaleWhileRevalidate(t *testing.T) {
+	store := cachehelper.NewMemoryStore()
+	var calls int32
+	loader := func(ctx context.Context) (int32, error) {
+		return atomic.AddInt32(&calls, 1), nil
+	}
+
+	v, err := cachehelper.GetOrLoad(context.Background(), store, "counter", time.Millisecond, loader, cachehelper.WithStaleTTL(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), v)
+
+	time.Sleep(5 * time.Millisecond)
+	v, err = cachehelper.GetOrLoad(context.Background(), store, "counter", time.Millisecond, loader, cachehelper.WithStaleTTL(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), v)
+
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&calls) == 2
+	}, time.Second, time.Millisecond)
+}
+
+func TestGetOrLoad_NegativeCaching(t *testing.T) {
+	store := cachehelper.NewMemoryStore()
+	var calls int32
+	loader := func(ctx context.Context) (User, error) {
+		atomic.AddInt32(&calls, 1)
+		return User{}, exception.ErrorNotFound
+	}
+
+	_, err := cachehelper.GetOrLoad(context.Background(), store, "user:2", time.Minute, loader)
+	assert.True(t, errors.Is(err, exception.ErrorNotFound))
+
+	_, err = cachehelper.GetOrLoad(context.Background(), store, "user:2", time.Minute, loader)
+	assert.True(t, errors.Is(err, exception.ErrorNotFound))
+	assert.Equal(t, int32(1), calls)
+}
+
+func TestGetOrLoad_LoaderPanic(t *testing.T) {
+	store := cachehelper.NewMemoryStore()
+	started := make(chan struct{})
+	var calls int32
+	loader := func(ctx context.Context) (User, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(started)
+			time.Sleep(20 * time.Millisecond)
+			panic("boom")
+		}
+		return User{Name: "foo"}, nil
+	}
+
+	go func() {
+		defer func() { recover() }()
+		cachehelper.GetOrLoad(context.Background(), store, "user:3", time.Minute, loader)
+	}()
+	<-started
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		cachehelper.GetOrLoad(context.Background(), store, "user:3", time.Minute, loader)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("waiter blocked after the loader panicked")
+	}
+
+	user, err := cachehelper.GetOrLoad(context.Background(), store, "user:3", time.Minute, loader)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", user.Name)
+}
+
+func TestGetOrLoad_RevalidationPanic(t *testing.T) {
+	store := cachehelper.NewMemoryStore()
+	var calls int32
+	loader := func(ctx context.Context) (User, error) {
+		if atomic.AddInt32(&calls, 1) == 2 {
+			panic("boom")
+		}
+		return User{Name: "foo"}, nil
+	}
+	errs := make(chan error, 1)
+	opts := []cachehelper.Option{
+		cachehelper.WithStaleTTL(time.Minute),
+		cachehelper.WithRevalidationHook(func(ctx context.Context, key string, err error) {
+			errs <- err
+		}),
+	}
+
+	_, err := cachehelper.GetOrLoad(context.Background(), store, "user:4", time.Millisecond, loader, opts...)
+	assert.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+
+	user, err := cachehelper.GetOrLoad(context.Background(), store, "user:4", time.Millisecond, loader, opts...)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", user.Name)
+	select {
+	case err := <-errs:
+		assert.True(t, exception.HasStatus(err, exception.StatusInternal))
+	case <-time.After(time.Second):
+		t.Fatal("revalidation panic was not reported")
+	}
+
+	user, err = cachehelper.GetOrLoad(context.Background(), store, "user:4", time.Millisecond, loader, opts...)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", user.Name)
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&calls) == 3
+	}, time.Second, time.Millisecond)
+}
+
+func TestGetOrLoad_PerStore(t *testing.T) {
+	stores := []*cachehelper.MemoryStore{cachehelper.NewMemoryStore(), cachehelper.NewMemoryStore()}
+	var calls int32
+	both := make(chan struct{})
+	loader := func(ctx context.Context) (User, error) {
+		if atomic.AddInt32(&calls, 1) == 2 {
+			close(both)
+		}
+		select {
+		case <-both:
+		case <-time.After(time.Second):
+		}
+		return User{Name: "foo"}, nil
+	}
+
+	var wg sync.WaitGroup
+	for _, store := range stores {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cachehelper.GetOrLoad(context.Background(), store, "user:4", time.Minute, loader)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), calls)
+}
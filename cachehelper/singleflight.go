@@ -0,0 +1,66 @@
+package cachehelper
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/aeramu/apihelper/exception"
+)
+
+// errLoadPanicked is returned to the callers waiting on a load whose loader panicked
+var errLoadPanicked = exception.New("cache load panicked")
+
+// call is an in-flight or completed load
+type call struct {
+	wg  sync.WaitGroup
+	val Entry
+	err error
+}
+
+// group deduplicates concurrent loads of the same key
+type group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// groups holds the group of every comparable store, so loads of equal keys
+// are only merged within the same store
+var groups sync.Map
+
+// groupFor returns the group deduplicating the loads of store. Stores whose
+// type is not comparable cannot be told apart and get a fresh group, so
+// their loads are not deduplicated.
+func groupFor(store Store) *group {
+	if !reflect.TypeOf(store).Comparable() {
+		return &group{}
+	}
+	g, _ := groups.LoadOrStore(store, &group{})
+	return g.(*group)
+}
+
+// do runs fn once for concurrent callers sharing the same key. If fn panics,
+// the waiting callers get errLoadPanicked and the key can be loaded again.
+func (g *group) do(key string, fn func() (Entry, error)) (Entry, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.val, c.err
+	}
+	c := &call{err: errLoadPanicked}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		c.wg.Done()
+	}()
+	c.val, c.err = fn()
+	return c.val, c.err
+}
@@ -0,0 +1,68 @@
+package cachehelper
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Entry is a cached value as persisted in a Store
+type Entry struct {
+	// Value is the JSON encoded value, empty for negative entries
+	Value []byte `json:"value,omitempty"`
+	// NotFound marks a negative entry caching a NOT_FOUND result
+	NotFound bool `json:"not_found,omitempty"`
+	// FreshUntil is when the entry becomes stale and should be revalidated
+	FreshUntil time.Time `json:"fresh_until"`
+}
+
+// Store is a key-value cache backend such as Redis or an in-memory map.
+// Get returns false when the key does not exist or has expired.
+type Store interface {
+	Get(ctx context.Context, key string) (Entry, bool, error)
+	Set(ctx context.Context, key string, entry Entry, ttl time.Duration) error
+}
+
+// MemoryStore is an in-memory Store, mainly useful for tests and single-instance services
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	entry     Entry
+	expiresAt time.Time
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]memoryEntry),
+	}
+}
+
+// Get returns the entry stored under key if it has not expired
+func (s *MemoryStore) Get(_ context.Context, key string) (Entry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok {
+		return Entry{}, false, nil
+	}
+	if time.Now().After(e.expiresAt) {
+		delete(s.entries, key)
+		return Entry{}, false, nil
+	}
+	return e.entry, true, nil
+}
+
+// Set stores the entry under key for the given duration
+func (s *MemoryStore) Set(_ context.Context, key string, entry Entry, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = memoryEntry{
+		entry:     entry,
+		expiresAt: time.Now().Add(ttl),
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package clienthelper
+
+import (
+	"sync"
+
+	"github.com/aeramu/apihelper/exception"
+	"github.com/aeramu/apihelper/httphelper"
+)
+
+// Adapter translates the native response format of an upstream that does
+// not use the standard envelope. It receives the response body and HTTP
+// status code and returns the equivalent envelope.
+type Adapter func(body []byte, status int) (*httphelper.Response, error)
+
+// adapters stores the registered adapters by host
+var (
+	adaptersMu sync.RWMutex
+	adapters   = map[string]Adapter{}
+)
+
+// RegisterAdapter registers adapter for responses from host, so that calls
+// to upstreams with their own response and error formats return data and
+// exceptions like any other upstream. Passing a nil adapter removes the one
+// registered for host.
+//
+// Example usage:
+//
+//	clienthelper.RegisterAdapter("api.stripe.com", func(body []byte, status int) (*httphelper.Response, error) {
+//	    if status < 400 {
+//	        return &httphelper.Response{Status: status, Success: true, Data: json.RawMessage(body)}, nil
+//	    }
+//	    var e struct{ Error struct{ Code, Message string } }
+//	    if err := json.Unmarshal(body, &e); err != nil {
+//	        return nil, err
+//	    }
+//	    return &httphelper.Response{Status: status, ErrorInfo: &httphelper.ErrorInfo{
+//	        Code: e.Error.Code, Message: e.Error.Message,
+//	    }}, nil
+//	})
+//
+// Parameters:
+//   - host: The upstream host, including the port if not the default one
+//   - adapter: The function translating responses from host
+func RegisterAdapter(host string, adapter Adapter) {
+	adaptersMu.Lock()
+	defer adaptersMu.Unlock()
+	if adapter == nil {
+		delete(adapters, host)
+		return
+	}
+	adapters[host] = adapter
+}
+
+// adapt translates body with the adapter registered for host, reporting
+// false if there is none
+func adapt(host string, body []byte, status int) (httphelper.Response, bool, error) {
+	adaptersMu.RLock()
+	adapter, ok := adapters[host]
+	adaptersMu.RUnlock()
+	if !ok {
+		return httphelper.Response{}, false, nil
+	}
+
+	resp, err := adapter(body, status)
+	if err != nil {
+		return httphelper.Response{}, true, exception.Wrap(err, "failed to adapt response",
+			exception.WithStatus(exception.StatusThirdParty),
+			exception.WithCode(exception.CodeThirdParty),
+		)
+	}
+	if resp == nil {
+		return httphelper.Response{Status: status}, true, nil
+	}
+	if resp.Status == 0 {
+		resp.Status = status
+	}
+	return *resp, true, nil
+}
@@ -0,0 +1,161 @@
+package clienthelper
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aeramu/apihelper/exception"
+	"github.com/aeramu/apihelper/httphelper"
+)
+
+// CircuitState is the state of the circuit breaker of a host
+type CircuitState int
+
+const (
+	// CircuitClosed lets requests through while counting failures
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects requests until the open timeout elapses
+	CircuitOpen
+	// CircuitHalfOpen lets a single probe through to decide whether the
+	// host has recovered
+	CircuitHalfOpen
+)
+
+// String returns the name of the state
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreaker describes the circuit breaker kept for every upstream host.
+// A circuit opens after FailureThreshold consecutive failures, rejecting
+// requests with an UNAVAILABLE exception without sending them. Once
+// OpenTimeout elapses a single probe request is let through, closing the
+// circuit on success and opening it again on failure. Only transport errors
+// and 5xx responses count as failures.
+type CircuitBreaker struct {
+	// FailureThreshold is the number of consecutive failures opening the
+	// circuit. Values below 1 disable the breaker.
+	FailureThreshold int
+	// OpenTimeout is how long an open circuit rejects requests before probing
+	OpenTimeout time.Duration
+	// OnStateChange is called whenever the circuit of a host changes state
+	OnStateChange func(host string, from, to CircuitState)
+}
+
+// circuits holds the circuit of every host for a CircuitBreaker
+type circuits struct {
+	policy CircuitBreaker
+	mu     sync.Mutex
+	hosts  map[string]*circuit
+}
+
+// circuit is the breaker state of a single host
+type circuit struct {
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// newCircuits returns the circuits for policy, nil if it is disabled
+func newCircuits(policy CircuitBreaker) *circuits {
+	if policy.FailureThreshold < 1 {
+		return nil
+	}
+	return &circuits{policy: policy, hosts: make(map[string]*circuit)}
+}
+
+// acquire reports whether a request to host may be sent, returning an
+// UNAVAILABLE exception if its circuit is open
+func (c *circuits) acquire(host string, now time.Time) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	h := c.host(host)
+	from := h.state
+	if h.state == CircuitOpen && now.Sub(h.openedAt) >= c.policy.OpenTimeout {
+		h.state = CircuitHalfOpen
+	}
+	allowed := h.state == CircuitClosed || (h.state == CircuitHalfOpen && !h.probing)
+	if allowed && h.state == CircuitHalfOpen {
+		h.probing = true
+	}
+	to := h.state
+	c.mu.Unlock()
+
+	c.changed(host, from, to)
+	if !allowed {
+		return exception.New("circuit breaker open for "+host,
+			exception.WithStatus(exception.StatusUnavailable),
+			exception.WithCode(exception.CodeUnavailable),
+		)
+	}
+	return nil
+}
+
+// record updates the circuit of host with the outcome of a request
+func (c *circuits) record(host string, err error, now time.Time) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	h := c.host(host)
+	from := h.state
+	switch {
+	case errors.Is(err, context.Canceled):
+		// requests canceled by the caller say nothing about the upstream
+	case failure(err):
+		h.failures++
+		if h.state == CircuitHalfOpen || h.failures >= c.policy.FailureThreshold {
+			h.state = CircuitOpen
+			h.openedAt = now
+		}
+	default:
+		h.failures = 0
+		h.state = CircuitClosed
+	}
+	h.probing = false
+	to := h.state
+	c.mu.Unlock()
+
+	c.changed(host, from, to)
+}
+
+// host returns the circuit of host, creating it if needed. The caller must
+// hold c.mu.
+func (c *circuits) host(host string) *circuit {
+	h, ok := c.hosts[host]
+	if !ok {
+		h = &circuit{}
+		c.hosts[host] = h
+	}
+	return h
+}
+
+// changed calls the state change callback if the state moved
+func (c *circuits) changed(host string, from, to CircuitState) {
+	if from != to && c.policy.OnStateChange != nil {
+		c.policy.OnStateChange(host, from, to)
+	}
+}
+
+// failure reports whether err means the upstream is unhealthy
+func failure(err error) bool {
+	if err == nil {
+		return false
+	}
+	httpErr, ok := httphelper.AsHTTPError(err)
+	return !ok || httpErr.HTTPStatus() >= 500
+}
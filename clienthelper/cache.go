@@ -0,0 +1,19 @@
+package clienthelper
+
+import (
+	"net/http"
+
+	"github.com/aeramu/apihelper/httphelper"
+)
+
+// withCache returns a copy of client sending its requests through an
+// httphelper.ConditionalTransport backed by store, or client itself when
+// store is nil
+func withCache(client *http.Client, store httphelper.ConditionalStore) *http.Client {
+	if store == nil {
+		return client
+	}
+	cached := *client
+	cached.Transport = httphelper.NewConditionalTransport(client.Transport, store)
+	return &cached
+}
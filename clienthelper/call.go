@@ -0,0 +1,230 @@
+package clienthelper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/aeramu/apihelper/exception"
+	"github.com/aeramu/apihelper/httphelper"
+)
+
+// CallOption customizes a single request sent by Call
+type CallOption func(*callConfig)
+
+// callConfig holds the per-request settings of Call
+type callConfig struct {
+	header   http.Header
+	retry    RetryPolicy
+	breakers *circuits
+	cache    httphelper.ConditionalStore
+	host     *Host
+	hedge    *Hedge
+}
+
+// WithHeader sets a request header
+func WithHeader(key, value string) CallOption {
+	return func(c *callConfig) {
+		c.header.Set(key, value)
+	}
+}
+
+// WithRetry overrides the configured retry policy for a single request
+func WithRetry(policy RetryPolicy) CallOption {
+	return func(c *callConfig) {
+		c.retry = policy
+	}
+}
+
+// Call sends a request to url with body encoded as JSON, decodes the
+// standard envelope of the response and returns its data as T. Error
+// envelopes are converted into exceptions preserving their code, message
+// and status; failures to reach the server become UNAVAILABLE or
+// DEADLINE_EXCEEDED exceptions. Failed requests are retried according to
+// the retry policy, see RetryPolicy, and rejected without being sent while
+// the circuit breaker of the host is open, see CircuitBreaker. The deadline
+// of ctx, minus the configured margin, bounds all attempts.
+//
+// Example usage:
+//
+//	order, err := clienthelper.Call[Order](ctx, nil, http.MethodPost, ordersURL, createOrderRequest{SKU: "A-1"},
+//	    clienthelper.WithHeader("Idempotency-Key", key))
+//
+// Parameters:
+//   - ctx: The request context
+//   - client: The HTTP client sending the request, nil for the configured client
+//   - method: The HTTP method
+//   - url: The URL to request, or the path on a host registered with WithHost
+//   - body: The request body encoded as JSON, nil for no body
+//   - opts: Options customizing the request, such as WithHeader
+//
+// Returns:
+//   - The response data
+//   - An exception if the request fails or the response is an error envelope
+func Call[T any](ctx context.Context, client *http.Client, method, url string, body any, opts ...CallOption) (T, error) {
+	var data T
+	cfg := defaultConfig.Load()
+	if client == nil {
+		client = cfg.client
+	}
+	call := callConfig{
+		header:   make(http.Header),
+		retry:    cfg.retry,
+		breakers: cfg.breakers,
+		cache:    cfg.cache,
+	}
+	for _, opt := range opts {
+		opt(&call)
+	}
+	url, host, err := cfg.resolve(url)
+	if err != nil {
+		return data, err
+	}
+	call.host = host
+
+	var payload []byte
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return data, exception.Wrap(err, "failed to encode request body")
+		}
+		payload = b
+		call.header.Set("Content-Type", httphelper.ContentTypeJSON)
+	}
+	call.header.Set("Accept", httphelper.ContentTypeJSON)
+
+	ctx, cancel, err := withDeadline(ctx, cfg.margin)
+	if err != nil {
+		return data, err
+	}
+	defer cancel()
+
+	for attempt := 1; ; attempt++ {
+		data, retryAfter, err := sendHedged[T](ctx, client, method, url, payload, &call)
+		if err == nil || attempt >= call.retry.MaxAttempts || !call.retry.retryable(method, call.header, err) {
+			return data, err
+		}
+		if !wait(ctx, call.retry.backoff(attempt, retryAfter)) {
+			return data, err
+		}
+	}
+}
+
+// send makes a single attempt of a Call, returning the delay requested by
+// the server through Retry-After along with the result
+func send[T any](ctx context.Context, client *http.Client, method, url string, payload []byte, call *callConfig) (T, time.Duration, error) {
+	var data T
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return data, 0, exception.Wrap(err, "failed to create request")
+	}
+	req.Header = call.header.Clone()
+	release, err := call.host.apply(req)
+	if err != nil {
+		return data, 0, err
+	}
+
+	if method == http.MethodGet {
+		client = withCache(client, call.cache)
+	}
+	resp, err := roundTrip(client, req, call.breakers)
+	if err != nil {
+		release(req.Context().Err() == nil)
+		return data, 0, err
+	}
+	defer release(false)
+	defer resp.Body.Close()
+
+	envelope, err := decode(resp)
+	if err == nil {
+		data, err = readEnvelope[T](envelope)
+	}
+	call.breakers.record(req.URL.Host, err, time.Now())
+	return data, parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()), err
+}
+
+// roundTrip sends req through the circuit breaker of its host. The caller
+// records the outcome of a returned response once it has been decoded.
+func roundTrip(client *http.Client, req *http.Request, breakers *circuits) (*http.Response, error) {
+	host := req.URL.Host
+	if err := breakers.acquire(host, time.Now()); err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		err = transportError(err)
+		breakers.record(host, err, time.Now())
+		return nil, err
+	}
+	return resp, nil
+}
+
+// decode reads the envelope of resp
+func decode(resp *http.Response) (httphelper.Response, error) {
+	body, err := readBody(resp)
+	if err != nil {
+		return httphelper.Response{}, err
+	}
+	return decodeEnvelope(resp.Request.URL, body, resp.StatusCode)
+}
+
+// readEnvelope returns the data of a successful envelope as T
+func readEnvelope[T any](envelope httphelper.Response) (T, error) {
+	var data T
+	if envelope.Data == nil {
+		return data, nil
+	}
+	data, err := httphelper.ReadData[T](envelope)
+	if err != nil {
+		return data, dataError(err)
+	}
+	return data, nil
+}
+
+// decodeEnvelope decodes body into the standard envelope of a response to
+// u, translating it with the adapter registered for its host if any and
+// falling back to an envelope carrying only status when body is not one, and
+// returns the exception of a failed envelope. Error responses matching a
+// rule of the configured normalizer are mapped to its exception first.
+// Successful statuses without an envelope, such as 204 No Content, succeed
+// without data, and error statuses without an envelope are mapped to
+// exceptions with rawError.
+func decodeEnvelope(u *url.URL, body []byte, status int) (httphelper.Response, error) {
+	host := u.Host
+	if n := defaultConfig.Load().normalizer; n != nil && status >= http.StatusBadRequest {
+		if err := n.Normalize(host, u.Path, status, body); err != nil {
+			return httphelper.Response{Status: status}, err
+		}
+	}
+	if envelope, ok, err := adapt(host, body, status); ok {
+		if err != nil {
+			return envelope, err
+		}
+		return envelope, envelopeError(envelope)
+	}
+	envelope, err := httphelper.DecodeResponse(body)
+	if err != nil || envelope.Status == 0 {
+		envelope = httphelper.Response{Status: status, Success: status < http.StatusBadRequest}
+		if !envelope.Success {
+			return envelope, rawError(defaultConfig.Load().lookup(host), status)
+		}
+		return envelope, nil
+	}
+	return envelope, envelopeError(envelope)
+}
+
+// dataError converts a failure to decode the envelope data into an exception
+func dataError(err error) error {
+	return exception.Wrap(err, "failed to decode response data",
+		exception.WithStatus(exception.StatusThirdParty),
+		exception.WithCode(exception.CodeThirdParty),
+	)
+}
@@ -0,0 +1,151 @@
+// Package clienthelper calls services responding with the standard
+// httphelper envelope and converts error envelopes into exceptions.
+package clienthelper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+
+	"github.com/aeramu/apihelper/exception"
+	"github.com/aeramu/apihelper/httphelper"
+)
+
+// GetOrDefault sends a GET request to url and returns the response data.
+// When the call fails with one of onCodes, or with any error if no codes are
+// given, fallback is returned instead so that an optional dependency being
+// down degrades the caller rather than failing it.
+//
+// Example usage:
+//
+//	recs, err := clienthelper.GetOrDefault(ctx, recommendationsURL, []Recommendation{},
+//	    exception.CodeUnavailable.String(), exception.CodeDeadlineExceeded.String())
+//
+// Parameters:
+//   - ctx: The request context
+//   - url: The URL to request
+//   - fallback: The value returned when the call degrades
+//   - onCodes: The exception codes that degrade to fallback
+//
+// Returns:
+//   - The response data, or fallback when the call degrades
+//   - An error if the call fails with a code not listed in onCodes
+func GetOrDefault[T any](ctx context.Context, url string, fallback T, onCodes ...string) (T, error) {
+	cfg := defaultConfig.Load()
+	data, err := Call[T](ctx, cfg.client, http.MethodGet, url, nil)
+	if err == nil {
+		return data, nil
+	}
+
+	if len(onCodes) > 0 {
+		code, ok := exception.AsErrorCode(err)
+		if !ok || !slices.Contains(onCodes, code.Code()) {
+			return data, err
+		}
+	}
+	if cfg.onDegraded != nil {
+		cfg.onDegraded(ctx, url, err)
+	}
+	return fallback, nil
+}
+
+// envelopeError converts a failed envelope into an exception preserving its
+// code, message and status
+func envelopeError(resp httphelper.Response) error {
+	if resp.IsSuccess() {
+		return nil
+	}
+	return exception.New(resp.Error(),
+		exception.WithStatus(exception.StatusFromHTTP(resp.Status)),
+		exception.WithCode(exception.Code(resp.Code())),
+		exception.WithMessage(resp.Message()),
+	)
+}
+
+// RawStatus returns the exception status of an error response without an
+// envelope, such as a proxy error page: NOT_FOUND for 404,
+// RESOURCE_EXHAUSTED for 429, UNAVAILABLE for 503 and THIRD_PARTY otherwise.
+// Host.StatusMapping overrides it per host.
+func RawStatus(code int) exception.Status {
+	switch code {
+	case http.StatusNotFound:
+		return exception.StatusNotFound
+	case http.StatusTooManyRequests:
+		return exception.StatusResourceExhausted
+	case http.StatusServiceUnavailable:
+		return exception.StatusUnavailable
+	}
+	return exception.StatusThirdParty
+}
+
+// rawError converts an error response without an envelope from host into
+// an exception, mapping its HTTP status with the host StatusMapping or
+// RawStatus
+func rawError(host *Host, code int) error {
+	status, ok := exception.Status(""), false
+	if host != nil {
+		status, ok = host.StatusMapping[code]
+	}
+	if !ok {
+		status = RawStatus(code)
+	}
+	return exception.New(fmt.Sprintf("upstream responded with HTTP %d without an envelope", code),
+		exception.WithStatus(status),
+		exception.WithCode(exception.Code(status)),
+	)
+}
+
+// ResponseTooLargeError is the cause of the exception returned when a
+// response body exceeds the maximum size, see WithMaxResponseSize
+type ResponseTooLargeError struct {
+	// Limit is the maximum size in bytes
+	Limit int64
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("response body exceeds %d bytes", e.Limit)
+}
+
+// readBody reads the body of resp up to the configured maximum size,
+// returning a THIRD_PARTY exception caused by a ResponseTooLargeError when
+// it is larger
+func readBody(resp *http.Response) ([]byte, error) {
+	limit := defaultConfig.Load().maxSize
+	if limit <= 0 {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, transportError(err)
+		}
+		return body, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, transportError(err)
+	}
+	if int64(len(body)) > limit {
+		return nil, exception.Wrap(&ResponseTooLargeError{Limit: limit}, "failed to read response",
+			exception.WithStatus(exception.StatusThirdParty),
+			exception.WithCode(exception.CodeThirdParty),
+		)
+	}
+	return body, nil
+}
+
+// transportError converts a failure to reach the server into an exception
+func transportError(err error) error {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return exception.Wrap(err, "request deadline exceeded",
+			exception.WithStatus(exception.StatusDeadlineExceeded),
+			exception.WithCode(exception.CodeDeadlineExceeded),
+		)
+	}
+	return exception.Wrap(err, "failed to send request",
+		exception.WithStatus(exception.StatusUnavailable),
+		exception.WithCode(exception.CodeUnavailable),
+	)
+}
@@ -0,0 +1,741 @@
+package clienthelper_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aeramu/apihelper/balancer"
+	"github.com/aeramu/apihelper/clienthelper"
+	"github.com/aeramu/apihelper/exception"
+	"github.com/aeramu/apihelper/httphelper"
+	"github.com/aeramu/apihelper/normalize"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type Recommendation struct {
+	ID string
+}
+
+func TestGetOrDefault(t *testing.T) {
+	var degraded []string
+	clienthelper.Configure(clienthelper.WithDegradationHook(func(ctx context.Context, url string, err error) {
+		degraded = append(degraded, url)
+	}))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			httphelper.OK(w, []Recommendation{{ID: "1"}})
+		case "/unavailable":
+			httphelper.Error(w, exception.ErrorUnavailable)
+		default:
+			httphelper.Error(w, exception.ErrorNotFound)
+		}
+	}))
+	defer server.Close()
+
+	fallback := []Recommendation{}
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		recs, err := clienthelper.GetOrDefault(ctx, server.URL+"/ok", fallback, exception.CodeUnavailable.String())
+		assert.NoError(t, err)
+		assert.Equal(t, []Recommendation{{ID: "1"}}, recs)
+	})
+
+	t.Run("degraded", func(t *testing.T) {
+		recs, err := clienthelper.GetOrDefault(ctx, server.URL+"/unavailable", fallback, exception.CodeUnavailable.String())
+		assert.NoError(t, err)
+		assert.Equal(t, fallback, recs)
+		assert.Equal(t, []string{server.URL + "/unavailable"}, degraded)
+	})
+
+	t.Run("other code", func(t *testing.T) {
+		_, err := clienthelper.GetOrDefault(ctx, server.URL+"/missing", fallback, exception.CodeUnavailable.String())
+		assert.True(t, exception.HasStatus(err, exception.StatusNotFound))
+		code, _ := exception.AsErrorCode(err)
+		assert.Equal(t, exception.CodeNotFound.String(), code.Code())
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		recs, err := clienthelper.GetOrDefault(ctx, "http://127.0.0.1:0/", fallback)
+		assert.NoError(t, err)
+		assert.Equal(t, fallback, recs)
+	})
+}
+
+func TestCall(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/recommendations":
+			var rec Recommendation
+			if err := json.NewDecoder(r.Body).Decode(&rec); err != nil {
+				httphelper.Error(w, exception.ErrorInvalidRequest)
+				return
+			}
+			assert.Equal(t, httphelper.ContentTypeJSON, r.Header.Get("Content-Type"))
+			rec.ID = rec.ID + "-" + r.Header.Get("X-Tenant")
+			httphelper.Created(w, rec)
+		default:
+			httphelper.Error(w, exception.New("recommendation not found",
+				exception.WithStatus(exception.StatusNotFound),
+				exception.WithCode(exception.CodeNotFound),
+				exception.WithMessage("recommendation not found"),
+			))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		rec, err := clienthelper.Call[Recommendation](ctx, nil, http.MethodPost, server.URL+"/recommendations",
+			Recommendation{ID: "1"}, clienthelper.WithHeader("X-Tenant", "acme"))
+		assert.NoError(t, err)
+		assert.Equal(t, Recommendation{ID: "1-acme"}, rec)
+	})
+
+	t.Run("error envelope", func(t *testing.T) {
+		_, err := clienthelper.Call[Recommendation](ctx, server.Client(), http.MethodGet, server.URL+"/missing", nil)
+		assert.True(t, exception.HasStatus(err, exception.StatusNotFound))
+		code, _ := exception.AsErrorCode(err)
+		assert.Equal(t, exception.CodeNotFound.String(), code.Code())
+		httpErr, ok := httphelper.AsHTTPError(err)
+		assert.True(t, ok)
+		assert.Equal(t, "recommendation not found", httpErr.Message())
+	})
+}
+
+func TestInstallResty(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			httphelper.OK(w, Recommendation{ID: "1"})
+		case "/malformed":
+			w.Header().Set("Content-Type", httphelper.ContentTypeJSON)
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte(`{"foo": "bar"`))
+		default:
+			httphelper.Error(w, exception.ErrorNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := resty.New()
+	clienthelper.InstallResty(client)
+
+	t.Run("success", func(t *testing.T) {
+		var rec Recommendation
+		resp, err := client.R().SetResult(&rec).Get(server.URL + "/ok")
+		assert.NoError(t, err)
+		assert.Equal(t, Recommendation{ID: "1"}, rec)
+		assert.Equal(t, &rec, resp.Result())
+	})
+
+	t.Run("error envelope", func(t *testing.T) {
+		var rec Recommendation
+		_, err := client.R().SetResult(&rec).Get(server.URL + "/missing")
+		assert.True(t, exception.HasStatus(err, exception.StatusNotFound))
+		code, _ := exception.AsErrorCode(err)
+		assert.Equal(t, exception.CodeNotFound.String(), code.Code())
+		assert.Equal(t, Recommendation{}, rec)
+	})
+
+	t.Run("malformed body", func(t *testing.T) {
+		_, err := client.R().SetResult(&Recommendation{}).Get(server.URL + "/malformed")
+		assert.Error(t, err)
+		assert.True(t, exception.HasStatus(err, exception.StatusThirdParty))
+	})
+}
+
+func TestCall_Retry(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			httphelper.Error(w, exception.ErrorUnavailable)
+			return
+		}
+		httphelper.OK(w, Recommendation{ID: "1"})
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	policy := clienthelper.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+	t.Run("retries idempotent method", func(t *testing.T) {
+		attempts.Store(0)
+		rec, err := clienthelper.Call[Recommendation](ctx, nil, http.MethodGet, server.URL, nil, clienthelper.WithRetry(policy))
+		assert.NoError(t, err)
+		assert.Equal(t, Recommendation{ID: "1"}, rec)
+		assert.EqualValues(t, 3, attempts.Load())
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		attempts.Store(-10)
+		_, err := clienthelper.Call[Recommendation](ctx, nil, http.MethodGet, server.URL, nil, clienthelper.WithRetry(policy))
+		assert.True(t, exception.HasStatus(err, exception.StatusUnavailable))
+		assert.EqualValues(t, -7, attempts.Load())
+	})
+
+	t.Run("skips non-idempotent method", func(t *testing.T) {
+		attempts.Store(0)
+		_, err := clienthelper.Call[Recommendation](ctx, nil, http.MethodPost, server.URL, Recommendation{}, clienthelper.WithRetry(policy))
+		assert.Error(t, err)
+		assert.EqualValues(t, 1, attempts.Load())
+	})
+
+	t.Run("retries with idempotency key", func(t *testing.T) {
+		attempts.Store(0)
+		_, err := clienthelper.Call[Recommendation](ctx, nil, http.MethodPost, server.URL, Recommendation{},
+			clienthelper.WithRetry(policy), clienthelper.WithHeader("Idempotency-Key", "abc"))
+		assert.NoError(t, err)
+		assert.EqualValues(t, 3, attempts.Load())
+	})
+}
+
+func TestCall_RetryAfterLimits(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 2 {
+			w.Header().Set("Retry-After", "3600")
+			httphelper.Error(w, exception.ErrorUnavailable)
+			return
+		}
+		httphelper.OK(w, Recommendation{ID: "1"})
+	}))
+	defer server.Close()
+
+	t.Run("caps at max delay", func(t *testing.T) {
+		attempts.Store(0)
+		policy := clienthelper.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+		start := time.Now()
+		rec, err := clienthelper.Call[Recommendation](context.Background(), nil, http.MethodGet, server.URL, nil, clienthelper.WithRetry(policy))
+		assert.NoError(t, err)
+		assert.Equal(t, Recommendation{ID: "1"}, rec)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("gives up past the deadline", func(t *testing.T) {
+		attempts.Store(0)
+		policy := clienthelper.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		start := time.Now()
+		_, err := clienthelper.Call[Recommendation](ctx, nil, http.MethodGet, server.URL, nil, clienthelper.WithRetry(policy))
+		assert.True(t, exception.HasStatus(err, exception.StatusUnavailable))
+		assert.EqualValues(t, 1, attempts.Load())
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
+
+func TestCall_CircuitBreaker(t *testing.T) {
+	var healthy atomic.Bool
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		if !healthy.Load() {
+			httphelper.Error(w, exception.ErrorInternal)
+			return
+		}
+		httphelper.OK(w, Recommendation{ID: "1"})
+	}))
+	defer server.Close()
+
+	var transitions []string
+	clienthelper.Configure(clienthelper.WithCircuitBreaker(clienthelper.CircuitBreaker{
+		FailureThreshold: 2,
+		OpenTimeout:      10 * time.Millisecond,
+		OnStateChange: func(host string, from, to clienthelper.CircuitState) {
+			transitions = append(transitions, from.String()+"->"+to.String())
+		},
+	}))
+	defer clienthelper.Configure(clienthelper.WithCircuitBreaker(clienthelper.CircuitBreaker{}))
+
+	ctx := context.Background()
+	for range 2 {
+		_, err := clienthelper.Call[Recommendation](ctx, nil, http.MethodGet, server.URL, nil)
+		assert.True(t, exception.HasStatus(err, exception.StatusInternal))
+	}
+
+	_, err := clienthelper.Call[Recommendation](ctx, nil, http.MethodGet, server.URL, nil)
+	assert.True(t, exception.HasStatus(err, exception.StatusUnavailable))
+	assert.EqualValues(t, 2, attempts.Load())
+
+	time.Sleep(20 * time.Millisecond)
+	healthy.Store(true)
+	rec, err := clienthelper.Call[Recommendation](ctx, nil, http.MethodGet, server.URL, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Recommendation{ID: "1"}, rec)
+	assert.Equal(t, []string{"closed->open", "open->half-open", "half-open->closed"}, transitions)
+}
+
+func TestCall_DeadlineMargin(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+		httphelper.OK(w, Recommendation{ID: "1"})
+	}))
+	defer server.Close()
+
+	clienthelper.Configure(clienthelper.WithDeadlineMargin(50 * time.Millisecond))
+	defer clienthelper.Configure(clienthelper.WithDeadlineMargin(0))
+
+	t.Run("times out before the inbound deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		_, err := clienthelper.Call[Recommendation](ctx, nil, http.MethodGet, server.URL, nil)
+		assert.True(t, exception.HasStatus(err, exception.StatusDeadlineExceeded))
+		assert.NoError(t, ctx.Err())
+	})
+
+	t.Run("no time left", func(t *testing.T) {
+		attempts.Store(0)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := clienthelper.Call[Recommendation](ctx, nil, http.MethodGet, server.URL, nil)
+		assert.True(t, exception.HasStatus(err, exception.StatusDeadlineExceeded))
+		assert.EqualValues(t, 0, attempts.Load())
+	})
+}
+
+func TestRegisterAdapter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"type": "NOT_FOUND", "reason": "no such recommendation"}}`))
+			return
+		}
+		w.Write([]byte(`{"ID": "1"}`))
+	}))
+	defer server.Close()
+
+	host := server.Listener.Addr().String()
+	clienthelper.RegisterAdapter(host, func(body []byte, status int) (*httphelper.Response, error) {
+		if status < 400 {
+			return &httphelper.Response{Success: true, Data: json.RawMessage(body)}, nil
+		}
+		var native struct {
+			Error struct{ Type, Reason string }
+		}
+		if err := json.Unmarshal(body, &native); err != nil {
+			return nil, err
+		}
+		return &httphelper.Response{ErrorInfo: &httphelper.ErrorInfo{
+			Code:    native.Error.Type,
+			Message: native.Error.Reason,
+		}}, nil
+	})
+	defer clienthelper.RegisterAdapter(host, nil)
+
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		rec, err := clienthelper.Call[Recommendation](ctx, nil, http.MethodGet, server.URL, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, Recommendation{ID: "1"}, rec)
+	})
+
+	t.Run("native error", func(t *testing.T) {
+		_, err := clienthelper.Call[Recommendation](ctx, nil, http.MethodGet, server.URL+"/missing", nil)
+		assert.True(t, exception.HasStatus(err, exception.StatusNotFound))
+		code, _ := exception.AsErrorCode(err)
+		assert.Equal(t, "NOT_FOUND", code.Code())
+	})
+
+	t.Run("resty", func(t *testing.T) {
+		client := resty.New()
+		clienthelper.InstallResty(client)
+		var rec Recommendation
+		_, err := client.R().SetResult(&rec).Get(server.URL)
+		assert.NoError(t, err)
+		assert.Equal(t, Recommendation{ID: "1"}, rec)
+	})
+}
+
+func TestUpload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, header, err := r.FormFile("document")
+		if err != nil {
+			httphelper.Error(w, exception.ErrorInvalidRequest)
+			return
+		}
+		content, _ := io.ReadAll(file)
+		httphelper.Created(w, map[string]string{
+			"filename": header.Filename,
+			"content":  string(content),
+			"type":     r.FormValue("type"),
+		})
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+
+	resp, err := clienthelper.Upload(ctx, server.URL,
+		map[string]io.Reader{"document": strings.NewReader("%PDF")},
+		map[string]string{"type": "invoice"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.Status)
+	doc, err := httphelper.ReadData[map[string]string](resp)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"filename": "document", "content": "%PDF", "type": "invoice"}, doc)
+
+	_, err = clienthelper.Upload(ctx, server.URL, nil, map[string]string{"type": "invoice"})
+	assert.True(t, exception.HasStatus(err, exception.StatusInvalidRequest))
+}
+
+func TestDownload(t *testing.T) {
+	content := strings.Repeat("0123456789", 10000)
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/report.csv" {
+			httphelper.Error(w, exception.ErrorNotFound)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		if requests.Add(1) == 1 {
+			// drop the connection half way through the first transfer
+			w.Header().Set("Accept-Ranges", "bytes")
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			w.Write([]byte(content[:len(content)/2]))
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		http.ServeContent(w, r, "report.csv", time.Time{}, strings.NewReader(content))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+
+	t.Run("resumes interrupted transfer", func(t *testing.T) {
+		var buf bytes.Buffer
+		var written, total int64
+		err := clienthelper.Download(ctx, server.URL+"/report.csv", &buf, func(w, t int64) {
+			written, total = w, t
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, content, buf.String())
+		assert.EqualValues(t, len(content), written)
+		assert.EqualValues(t, len(content), total)
+		assert.EqualValues(t, 2, requests.Load())
+	})
+
+	t.Run("error envelope", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := clienthelper.Download(ctx, server.URL+"/missing.csv", &buf, nil)
+		assert.True(t, exception.HasStatus(err, exception.StatusNotFound))
+		assert.Zero(t, buf.Len())
+	})
+}
+
+func TestCall_MaxResponseSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httphelper.OK(w, Recommendation{ID: strings.Repeat("x", 1024)})
+	}))
+	defer server.Close()
+
+	clienthelper.Configure(clienthelper.WithMaxResponseSize(512))
+	defer clienthelper.Configure(clienthelper.WithMaxResponseSize(clienthelper.DefaultMaxResponseSize))
+
+	_, err := clienthelper.Call[Recommendation](context.Background(), nil, http.MethodGet, server.URL, nil)
+	var tooLarge *clienthelper.ResponseTooLargeError
+	assert.ErrorAs(t, err, &tooLarge)
+	assert.Equal(t, int64(512), tooLarge.Limit)
+	assert.True(t, exception.HasStatus(err, exception.StatusThirdParty))
+
+	clienthelper.Configure(clienthelper.WithMaxResponseSize(4096))
+	rec, err := clienthelper.Call[Recommendation](context.Background(), nil, http.MethodGet, server.URL, nil)
+	assert.NoError(t, err)
+	assert.Len(t, rec.ID, 1024)
+}
+
+func TestCall_ResponseCache(t *testing.T) {
+	var full, notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full.Add(1)
+		w.Header().Set("ETag", `"v1"`)
+		httphelper.OK(w, []Recommendation{{ID: "1" + r.Header.Get("Authorization")}})
+	}))
+	defer server.Close()
+
+	clienthelper.Configure(clienthelper.WithResponseCache(10))
+	defer clienthelper.Configure(clienthelper.WithResponseCache(0))
+
+	ctx := context.Background()
+	for range 3 {
+		recs, err := clienthelper.Call[[]Recommendation](ctx, nil, http.MethodGet, server.URL, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []Recommendation{{ID: "1"}}, recs)
+	}
+	assert.EqualValues(t, 1, full.Load())
+	assert.EqualValues(t, 2, notModified.Load())
+
+	recs, err := clienthelper.Call[[]Recommendation](ctx, nil, http.MethodGet, server.URL, nil,
+		clienthelper.WithHeader("Authorization", "-bob"))
+	assert.NoError(t, err)
+	assert.Equal(t, []Recommendation{{ID: "1-bob"}}, recs)
+	assert.EqualValues(t, 2, full.Load())
+}
+
+func TestWithHost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httphelper.OK(w, map[string]string{
+			"path":   r.URL.Path,
+			"client": r.Header.Get("X-Client"),
+			"auth":   r.Header.Get("Authorization"),
+		})
+	}))
+	defer server.Close()
+
+	clienthelper.Configure(clienthelper.WithHost("orders", clienthelper.Host{
+		BaseURL: server.URL + "/api/",
+		Header:  http.Header{"X-Client": []string{"checkout"}},
+		Auth: clienthelper.BearerToken(func(ctx context.Context) (string, error) {
+			return "token", nil
+		}),
+	}))
+
+	ctx := context.Background()
+
+	got, err := clienthelper.Call[map[string]string](ctx, nil, http.MethodGet, "orders/v1/orders/1", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"path": "/api/v1/orders/1", "client": "checkout", "auth": "Bearer token"}, got)
+
+	got, err = clienthelper.Call[map[string]string](ctx, nil, http.MethodGet, server.URL+"/health", nil,
+		clienthelper.WithHeader("X-Client", "admin"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"path": "/health", "client": "admin", "auth": "Bearer token"}, got)
+
+	_, err = clienthelper.Call[map[string]string](ctx, nil, http.MethodGet, "payments/v1/charges", nil)
+	assert.Error(t, err)
+}
+
+func TestMock(t *testing.T) {
+	mock := clienthelper.NewMock()
+	mock.On(http.MethodGet, "/v1/recommendations/1").
+		Fail(exception.ErrorUnavailable).
+		Return(Recommendation{ID: "1"})
+	mock.On(http.MethodPost, "/v1/recommendations").ReturnStatus(http.StatusCreated, Recommendation{ID: "2"})
+	mock.On(http.MethodGet, "/v1/slow").Delay(time.Second).Return(Recommendation{})
+
+	ctx := context.Background()
+	client := mock.Client()
+
+	_, err := clienthelper.Call[Recommendation](ctx, client, http.MethodGet, "http://recs/v1/recommendations/1", nil)
+	assert.True(t, exception.HasStatus(err, exception.StatusUnavailable))
+	for range 2 {
+		rec, err := clienthelper.Call[Recommendation](ctx, client, http.MethodGet, "http://recs/v1/recommendations/1", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, Recommendation{ID: "1"}, rec)
+	}
+	assert.Equal(t, 3, mock.Calls(http.MethodGet, "/v1/recommendations/1"))
+
+	rec, err := clienthelper.Call[Recommendation](ctx, client, http.MethodPost, "http://recs/v1/recommendations", Recommendation{})
+	assert.NoError(t, err)
+	assert.Equal(t, Recommendation{ID: "2"}, rec)
+
+	_, err = clienthelper.Call[Recommendation](ctx, client, http.MethodGet, "http://recs/v1/unknown", nil)
+	assert.True(t, exception.HasStatus(err, exception.StatusNotFound))
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = clienthelper.Call[Recommendation](timeout, client, http.MethodGet, "http://recs/v1/slow", nil)
+	assert.True(t, exception.HasStatus(err, exception.StatusDeadlineExceeded))
+}
+
+func TestCall_RawErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		http.Error(w, http.StatusText(status), status)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	tests := map[int]exception.Status{
+		http.StatusNotFound:           exception.StatusNotFound,
+		http.StatusTooManyRequests:    exception.StatusResourceExhausted,
+		http.StatusServiceUnavailable: exception.StatusUnavailable,
+		http.StatusBadGateway:         exception.StatusThirdParty,
+	}
+	for status, want := range tests {
+		_, err := clienthelper.Call[Recommendation](ctx, nil, http.MethodGet, server.URL+"/"+strconv.Itoa(status), nil)
+		assert.True(t, exception.HasStatus(err, want), "status %d: %v", status, err)
+	}
+
+	clienthelper.Configure(clienthelper.WithHost("legacy", clienthelper.Host{
+		BaseURL:       server.URL,
+		StatusMapping: map[int]exception.Status{http.StatusBadGateway: exception.StatusUnavailable},
+	}))
+	_, err := clienthelper.Call[Recommendation](ctx, nil, http.MethodGet, "legacy/502", nil)
+	assert.True(t, exception.HasStatus(err, exception.StatusUnavailable))
+	code, _ := exception.AsErrorCode(err)
+	assert.Equal(t, exception.CodeUnavailable.String(), code.Code())
+}
+
+func TestCall_Normalizer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httphelper.Error(w, exception.New("card declined",
+			exception.WithStatus(exception.StatusInvalidRequest),
+			exception.WithCode("card_declined"),
+		))
+	}))
+	defer server.Close()
+
+	engine, err := normalize.New(normalize.Rule{
+		Path:    "^/v1/charges",
+		Body:    "card_declined",
+		Status:  exception.StatusInvalidRequest,
+		Code:    "PAYMENT_DECLINED",
+		Message: "payment was declined",
+	})
+	assert.NoError(t, err)
+	clienthelper.Configure(clienthelper.WithNormalizer(engine))
+	t.Cleanup(func() { clienthelper.Configure(clienthelper.WithNormalizer(nil)) })
+
+	_, err = clienthelper.Call[Recommendation](context.Background(), nil, http.MethodPost, server.URL+"/v1/charges", nil)
+	code, _ := exception.AsErrorCode(err)
+	assert.Equal(t, "PAYMENT_DECLINED", code.Code())
+	e, _ := httphelper.AsHTTPError(err)
+	assert.Equal(t, "payment was declined", e.Message())
+
+	_, err = clienthelper.Call[Recommendation](context.Background(), nil, http.MethodPost, server.URL+"/v1/refunds", nil)
+	code, _ = exception.AsErrorCode(err)
+	assert.Equal(t, "card_declined", code.Code())
+}
+
+func TestCall_HostPool(t *testing.T) {
+	newServer := func(id string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/recommendations", r.URL.Path)
+			httphelper.OK(w, Recommendation{ID: id})
+		}))
+	}
+	a, b := newServer("a"), newServer("b")
+	defer a.Close()
+	defer b.Close()
+
+	pool := balancer.NewPool([]string{a.URL, b.URL})
+	clienthelper.Configure(clienthelper.WithHost("pooled", clienthelper.Host{
+		BaseURL: "http://pooled/api",
+		Pool:    pool,
+	}))
+	ctx := context.Background()
+
+	seen := map[string]bool{}
+	for i := 0; i < 4; i++ {
+		rec, err := clienthelper.Call[Recommendation](ctx, nil, http.MethodGet, "pooled/recommendations", nil)
+		assert.NoError(t, err)
+		seen[rec.ID] = true
+	}
+	assert.Equal(t, map[string]bool{"a": true, "b": true}, seen)
+
+	affinity := clienthelper.WithAffinityKey(ctx, "tenant-1")
+	first, err := clienthelper.Call[Recommendation](affinity, nil, http.MethodGet, "pooled/recommendations", nil)
+	assert.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		rec, err := clienthelper.Call[Recommendation](affinity, nil, http.MethodGet, "pooled/recommendations", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, first.ID, rec.ID)
+	}
+
+	b.Close()
+	for i := 0; i < 2; i++ {
+		clienthelper.Call[Recommendation](ctx, nil, http.MethodGet, "pooled/recommendations", nil)
+	}
+	assert.Equal(t, []balancer.EndpointStatus{
+		{Endpoint: a.URL, Healthy: true},
+		{Endpoint: b.URL, Healthy: false},
+	}, pool.Status())
+	for i := 0; i < 2; i++ {
+		rec, err := clienthelper.Call[Recommendation](ctx, nil, http.MethodGet, "pooled/recommendations", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "a", rec.ID)
+	}
+}
+
+func TestCall_NoEnvelopeSuccess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	for _, status := range []int{http.StatusOK, http.StatusAccepted, http.StatusNoContent} {
+		rec, err := clienthelper.Call[*Recommendation](context.Background(), nil, http.MethodDelete, server.URL+"/"+strconv.Itoa(status), nil)
+		assert.NoError(t, err, "status %d", status)
+		assert.Nil(t, rec)
+	}
+}
+
+func TestCall_Hedge(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(time.Second):
+		}
+		httphelper.OK(w, Recommendation{ID: "slow"})
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httphelper.OK(w, Recommendation{ID: "fast"})
+	}))
+	defer fast.Close()
+
+	ctx := context.Background()
+	hedge := clienthelper.WithHedge(clienthelper.Hedge{Delay: 10 * time.Millisecond, URL: fast.URL})
+
+	start := time.Now()
+	rec, err := clienthelper.Call[Recommendation](ctx, nil, http.MethodGet, slow.URL, nil, hedge)
+	assert.NoError(t, err)
+	assert.Equal(t, Recommendation{ID: "fast"}, rec)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+	rec, err = clienthelper.Call[Recommendation](ctx, nil, http.MethodGet, fast.URL, nil,
+		clienthelper.WithHedge(clienthelper.Hedge{Delay: time.Second, URL: slow.URL}))
+	assert.NoError(t, err)
+	assert.Equal(t, Recommendation{ID: "fast"}, rec)
+}
+
+func TestConfigure_Concurrent(t *testing.T) {
+	defer clienthelper.Configure(clienthelper.WithDeadlineMargin(0))
+
+	mock := clienthelper.NewMock()
+	mock.On(http.MethodGet, "/v1/recommendations/1").Return(Recommendation{ID: "1"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			clienthelper.Configure(clienthelper.WithDeadlineMargin(time.Duration(i) * time.Millisecond))
+		}(i)
+		go func() {
+			defer wg.Done()
+			rec, err := clienthelper.Call[Recommendation](context.Background(), mock.Client(), http.MethodGet, "http://recs/v1/recommendations/1", nil)
+			assert.NoError(t, err)
+			assert.Equal(t, "1", rec.ID)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,177 @@
+package clienthelper
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aeramu/apihelper/httphelper"
+	"github.com/aeramu/apihelper/normalize"
+)
+
+// Configuration options
+type config struct {
+	client     *http.Client
+	onDegraded func(ctx context.Context, url string, err error)
+	retry      RetryPolicy
+	breakers   *circuits
+	margin     time.Duration
+	cache      httphelper.ConditionalStore
+	hosts      map[string]Host
+	normalizer *normalize.Engine
+	maxSize    int64
+}
+
+// Option represents a configuration option for the clienthelper package
+type Option func(*config)
+
+// defaultConfig holds the configuration changed by Configure. It is replaced
+// as a whole and never mutated, so calls can read it concurrently with
+// Configure.
+var defaultConfig atomic.Pointer[config]
+
+// DefaultMaxResponseSize is the default maximum size of the response bodies
+// read into memory, see WithMaxResponseSize
+const DefaultMaxResponseSize = 10 << 20
+
+// configureMu serializes Configure calls so concurrent updates are not lost
+var configureMu sync.Mutex
+
+func init() {
+	defaultConfig.Store(&config{
+		client:  &http.Client{Timeout: 30 * time.Second},
+		maxSize: DefaultMaxResponseSize,
+	})
+}
+
+// WithHTTPClient sets the HTTP client used to send requests
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *config) {
+		c.client = client
+	}
+}
+
+// WithMaxResponseSize sets the maximum size in bytes of the response bodies
+// read into memory, DefaultMaxResponseSize by default. Larger responses fail
+// with a ResponseTooLargeError. Zero or negative disables the limit.
+func WithMaxResponseSize(size int64) Option {
+	return func(c *config) {
+		c.maxSize = size
+	}
+}
+
+// WithDegradationHook sets a hook called whenever a failed call is replaced
+// by its fallback value, e.g. to count degraded responses
+func WithDegradationHook(hook func(ctx context.Context, url string, err error)) Option {
+	return func(c *config) {
+		c.onDegraded = hook
+	}
+}
+
+// WithRetryPolicy sets the retry policy applied to every Call, see
+// RetryPolicy. Retries are disabled by default.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *config) {
+		c.retry = policy
+	}
+}
+
+// WithCircuitBreaker enables a circuit breaker for every upstream host, see
+// CircuitBreaker. Circuit breaking is disabled by default.
+func WithCircuitBreaker(breaker CircuitBreaker) Option {
+	return func(c *config) {
+		c.breakers = newCircuits(breaker)
+	}
+}
+
+// WithDeadlineMargin sets how long before the deadline of the inbound
+// context outbound requests time out, leaving the caller time to handle the
+// DEADLINE_EXCEEDED exception before its own deadline. Zero by default.
+func WithDeadlineMargin(margin time.Duration) Option {
+	return func(c *config) {
+		c.margin = margin
+	}
+}
+
+// WithResponseCache enables caching the responses of GET calls that carry
+// an ETag or Last-Modified header, up to maxEntries responses evicted in
+// least recently used order. Later calls send If-None-Match and
+// If-Modified-Since, and a 304 Not Modified answer returns the cached data,
+// see httphelper.ConditionalTransport. Responses are cached per credentials
+// and Vary headers, and never when marked no-store or private. Zero disables
+// the cache, the default.
+func WithResponseCache(maxEntries int) Option {
+	return func(c *config) {
+		c.cache = nil
+		if maxEntries > 0 {
+			c.cache = httphelper.NewLRUConditionalStore(maxEntries)
+		}
+	}
+}
+
+// WithResponseCacheStore is like WithResponseCache but keeps the responses
+// in store, e.g. one shared between instances. Nil disables the cache.
+func WithResponseCacheStore(store httphelper.ConditionalStore) Option {
+	return func(c *config) {
+		c.cache = store
+	}
+}
+
+// WithHost registers an upstream host under name, so call sites pass
+// "name/path" URLs resolved against its BaseURL, and its static headers and
+// credentials are added to every request sent to it, see Host.
+//
+// Example usage:
+//
+//	clienthelper.Configure(clienthelper.WithHost("orders", clienthelper.Host{
+//	    BaseURL: "https://orders.internal/api",
+//	    Header:  http.Header{"X-Client": []string{"checkout"}},
+//	    Auth:    clienthelper.BasicAuth("checkout", secret),
+//	}))
+//	order, err := clienthelper.Call[Order](ctx, nil, http.MethodGet, "orders/v1/orders/"+id, nil)
+func WithHost(name string, host Host) Option {
+	return func(c *config) {
+		hosts := make(map[string]Host, len(c.hosts)+1)
+		for k, v := range c.hosts {
+			hosts[k] = v
+		}
+		hosts[name] = host
+		c.hosts = hosts
+	}
+}
+
+// WithNormalizer maps error responses matching the rules of engine to their
+// exceptions, before envelopes, adapters and Host.StatusMapping are
+// considered, so operators can adjust how upstream errors are reported
+// without a deploy. Nil disables normalization, the default.
+//
+// Example usage:
+//
+//	engine, err := normalize.LoadFile("rules.yaml")
+//	if err != nil {
+//	    return err
+//	}
+//	clienthelper.Configure(clienthelper.WithNormalizer(engine))
+func WithNormalizer(engine *normalize.Engine) Option {
+	return func(c *config) {
+		c.normalizer = engine
+	}
+}
+
+// Configure applies the provided options to the default configuration.
+// This function allows customizing the behavior of the clienthelper package.
+//
+// Parameters:
+//   - opts: A variadic list of Option functions to apply
+func Configure(opts ...Option) {
+	configureMu.Lock()
+	defer configureMu.Unlock()
+
+	cfg := *defaultConfig.Load()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	defaultConfig.Store(&cfg)
+}
@@ -0,0 +1,28 @@
+package clienthelper
+
+import (
+	"context"
+	"time"
+
+	"github.com/aeramu/apihelper/exception"
+)
+
+// withDeadline derives the context of an outbound request from the
+// inbound ctx, moving its deadline margin earlier so the caller still has
+// time to handle a timed out upstream before its own deadline. It returns a
+// DEADLINE_EXCEEDED exception when no time is left for the request.
+func withDeadline(ctx context.Context, margin time.Duration) (context.Context, context.CancelFunc, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok || margin <= 0 {
+		return ctx, func() {}, nil
+	}
+	deadline = deadline.Add(-margin)
+	if !time.Now().Before(deadline) {
+		return ctx, func() {}, exception.New("no time left before the request deadline",
+			exception.WithStatus(exception.StatusDeadlineExceeded),
+			exception.WithCode(exception.CodeDeadlineExceeded),
+		)
+	}
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	return ctx, cancel, nil
+}
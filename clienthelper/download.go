@@ -0,0 +1,196 @@
+package clienthelper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aeramu/apihelper/exception"
+)
+
+// maxResumes is the number of times Download resumes an interrupted
+// transfer before giving up
+const maxResumes = 3
+
+// Download sends a GET request to url and streams the response body into w,
+// calling onProgress after every chunk with the bytes written so far and the
+// total size, or -1 if the server did not send a Content-Length. Transfers
+// interrupted mid-way are resumed with a Range request when the server
+// accepts ranges; an If-Range header makes sure the resource did not change
+// in between. When the server refuses the download, its error envelope is
+// converted into an exception preserving its code, message and status.
+//
+// Example usage:
+//
+//	f, err := os.Create("report.csv")
+//	if err != nil {
+//	    return err
+//	}
+//	defer f.Close()
+//	err = clienthelper.Download(ctx, reportURL, f, func(written, total int64) {
+//	    log.Printf("downloaded %d/%d bytes", written, total)
+//	})
+//
+// Parameters:
+//   - ctx: The request context
+//   - url: The URL to download, or the path on a host registered with WithHost
+//   - w: The writer receiving the response body
+//   - onProgress: The progress callback, may be nil
+//
+// Returns:
+//   - An exception if the download fails or the server refuses it
+func Download(ctx context.Context, url string, w io.Writer, onProgress func(written, total int64)) error {
+	cfg := defaultConfig.Load()
+	url, host, err := cfg.resolve(url)
+	if err != nil {
+		return err
+	}
+	ctx, cancel, err := withDeadline(ctx, cfg.margin)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	d := &download{cfg: cfg, url: url, host: host, w: w, onProgress: onProgress, total: -1}
+	for resumes := 0; ; resumes++ {
+		resumable, err := d.attempt(ctx)
+		if err == nil || !resumable || resumes >= maxResumes || ctx.Err() != nil {
+			return err
+		}
+	}
+}
+
+// download is the state of a Download across resumed attempts
+type download struct {
+	cfg        *config
+	url        string
+	host       *Host
+	w          io.Writer
+	onProgress func(written, total int64)
+	written    int64
+	total      int64
+	etag       string
+	ranges     bool
+}
+
+// attempt requests the rest of the body and copies it to the writer,
+// reporting whether a failure may be resumed with another attempt
+func (d *download) attempt(ctx context.Context) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.url, nil)
+	if err != nil {
+		return false, exception.Wrap(err, "failed to create request")
+	}
+	if d.written > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", d.written))
+		if d.etag != "" {
+			req.Header.Set("If-Range", d.etag)
+		}
+	}
+	release, err := d.host.apply(req)
+	if err != nil {
+		return false, err
+	}
+
+	host := req.URL.Host
+	resp, err := roundTrip(d.cfg.client, req, d.cfg.breakers)
+	if err != nil {
+		release(req.Context().Err() == nil)
+		return d.written > 0, err
+	}
+	defer release(false)
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && d.written > 0:
+		if start, total, ok := parseContentRange(resp.Header.Get("Content-Range")); !ok || start != d.written {
+			return false, changedError("unexpected content range " + resp.Header.Get("Content-Range"))
+		} else if total >= 0 {
+			d.total = total
+		}
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		if d.written > 0 {
+			// the server sent the whole body again, either because it
+			// ignores ranges or because the resource changed
+			if d.etag != "" {
+				return false, changedError("resource changed during download")
+			}
+			if _, err := io.CopyN(io.Discard, resp.Body, d.written); err != nil {
+				return true, transportError(err)
+			}
+		} else {
+			d.total = resp.ContentLength
+		}
+		d.etag = resp.Header.Get("ETag")
+		d.ranges = resp.Header.Get("Accept-Ranges") == "bytes"
+	default:
+		body, err := readBody(resp)
+		if err == nil {
+			_, err = decodeEnvelope(req.URL, body, resp.StatusCode)
+		}
+		d.cfg.breakers.record(host, err, time.Now())
+		return false, err
+	}
+
+	resumable, err := d.copy(resp.Body)
+	d.cfg.breakers.record(host, err, time.Now())
+	return resumable, err
+}
+
+// copy writes body to the writer, reporting progress. Read failures may be
+// resumed when the server accepts ranges; write failures may not.
+func (d *download) copy(body io.Reader) (bool, error) {
+	buf := make([]byte, 32<<10)
+	for {
+		n, readErr := body.Read(buf)
+		if n > 0 {
+			if _, err := d.w.Write(buf[:n]); err != nil {
+				return false, exception.Wrap(err, "failed to write download")
+			}
+			d.written += int64(n)
+			if d.onProgress != nil {
+				d.onProgress(d.written, d.total)
+			}
+		}
+		if readErr == io.EOF {
+			return false, nil
+		}
+		if readErr != nil {
+			return d.ranges, transportError(readErr)
+		}
+	}
+}
+
+// parseContentRange parses a "bytes start-end/total" Content-Range header,
+// with a total of -1 when unknown
+func parseContentRange(value string) (start, total int64, ok bool) {
+	spec, found := strings.CutPrefix(value, "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+	byteRange, size, found := strings.Cut(spec, "/")
+	first, _, found2 := strings.Cut(byteRange, "-")
+	if !found || !found2 {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	if size == "*" {
+		return start, -1, true
+	}
+	total, err = strconv.ParseInt(size, 10, 64)
+	return start, total, err == nil
+}
+
+// changedError reports a download that cannot be resumed consistently
+func changedError(reason string) error {
+	return exception.New(reason,
+		exception.WithStatus(exception.StatusThirdParty),
+		exception.WithCode(exception.CodeThirdParty),
+	)
+}
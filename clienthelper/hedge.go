@@ -0,0 +1,85 @@
+package clienthelper
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// Hedge describes hedged requests for latency-sensitive idempotent reads:
+// when the first request has not completed after Delay, a duplicate is sent
+// and the first successful response wins, canceling the other request.
+type Hedge struct {
+	// Delay is how long the first request may take before the duplicate is
+	// sent, typically around the p95 latency of the upstream
+	Delay time.Duration
+	// URL is the endpoint receiving the duplicate, such as a replica in
+	// another zone, or the same endpoint if empty. Like the call URL, it may
+	// be a path on a host registered with WithHost.
+	URL string
+}
+
+// WithHedge enables hedged requests for a single call, see Hedge. Only
+// idempotent methods are hedged.
+func WithHedge(hedge Hedge) CallOption {
+	return func(c *callConfig) {
+		c.hedge = &hedge
+	}
+}
+
+// hedgeResult is the outcome of one of the hedged requests
+type hedgeResult[T any] struct {
+	data       T
+	retryAfter time.Duration
+	err        error
+}
+
+// sendHedged makes a single attempt of a Call, hedged when configured
+func sendHedged[T any](ctx context.Context, client *http.Client, method, url string, payload []byte, call *callConfig) (T, time.Duration, error) {
+	if call.hedge == nil || !slices.Contains(idempotentMethods, method) {
+		return send[T](ctx, client, method, url, payload, call)
+	}
+	hedgeURL, hedgeCall := url, call
+	if call.hedge.URL != "" {
+		resolved, host, err := defaultConfig.Load().resolve(call.hedge.URL)
+		if err != nil {
+			var data T
+			return data, 0, err
+		}
+		copied := *call
+		copied.host = host
+		hedgeURL, hedgeCall = resolved, &copied
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	// canceling once a result is returned stops the request still in flight
+	defer cancel()
+	results := make(chan hedgeResult[T], 2)
+	start := func(url string, call *callConfig) {
+		go func() {
+			data, retryAfter, err := send[T](ctx, client, method, url, payload, call)
+			results <- hedgeResult[T]{data: data, retryAfter: retryAfter, err: err}
+		}()
+	}
+	start(url, call)
+
+	timer := time.NewTimer(call.hedge.Delay)
+	defer timer.Stop()
+	hedgeC, pending := timer.C, 1
+	for {
+		select {
+		case <-hedgeC:
+			hedgeC = nil
+			start(hedgeURL, hedgeCall)
+			pending++
+		case result := <-results:
+			pending--
+			// a failure waits for the other request if it is in flight, but
+			// does not trigger the duplicate, which is left to retries
+			if result.err == nil || pending == 0 {
+				return result.data, result.retryAfter, result.err
+			}
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package clienthelper
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aeramu/apihelper/balancer"
+	"github.com/aeramu/apihelper/exception"
+)
+
+// Host configures the requests sent to an upstream service
+type Host struct {
+	// BaseURL is the absolute URL paths of the host are resolved against,
+	// e.g. "https://orders.internal/api"
+	BaseURL string
+	// Header lists static headers added to every request unless the call
+	// sets them itself
+	Header http.Header
+	// Auth authenticates every request, e.g. BearerToken or BasicAuth
+	Auth Auth
+	// StatusMapping overrides the exception status of error responses
+	// without an envelope by HTTP status code, see RawStatus
+	StatusMapping map[int]exception.Status
+	// Pool spreads the requests over its endpoints, replacing the scheme and
+	// authority of BaseURL with the endpoint picked for every attempt.
+	// Requests carrying a key set with WithAffinityKey use Pool.PickByKey,
+	// others Pool.Pick, and endpoints that cannot be reached are marked
+	// unhealthy until Pool.Run probes them again.
+	Pool *balancer.Pool
+}
+
+type affinityKey struct{}
+
+// WithAffinityKey returns a context whose requests to hosts with a Pool are
+// routed by key, such as a tenant ID, so requests for the same key keep
+// hitting the same endpoint, see balancer.Pool.PickByKey
+func WithAffinityKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, affinityKey{}, key)
+}
+
+// Auth adds credentials to an outbound request
+type Auth func(req *http.Request) error
+
+// BearerToken returns an Auth setting an "Authorization: Bearer" header with
+// the token returned by token, called for every request so it can refresh
+// expiring tokens
+//
+// Example usage:
+//
+//	auth := clienthelper.BearerToken(func(ctx context.Context) (string, error) {
+//	    return tokens.Get(ctx, "orders")
+//	})
+func BearerToken(token func(ctx context.Context) (string, error)) Auth {
+	return func(req *http.Request) error {
+		t, err := token(req.Context())
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+t)
+		return nil
+	}
+}
+
+// BasicAuth returns an Auth setting HTTP basic authentication credentials
+func BasicAuth(username, password string) Auth {
+	return func(req *http.Request) error {
+		req.SetBasicAuth(username, password)
+		return nil
+	}
+}
+
+// resolve returns the absolute URL of target and the host it is sent to.
+// A target without a scheme, such as "orders/v1/orders/1", names a
+// registered host followed by a path relative to its BaseURL. Absolute
+// targets use the host whose BaseURL has the same scheme and authority, if any.
+func (c config) resolve(target string) (string, *Host, error) {
+	if strings.Contains(target, "://") {
+		u, err := url.Parse(target)
+		if err != nil {
+			return "", nil, exception.Wrap(err, "failed to parse request URL")
+		}
+		for _, host := range c.hosts {
+			base, err := url.Parse(host.BaseURL)
+			if err == nil && base.Scheme == u.Scheme && base.Host == u.Host {
+				return target, &host, nil
+			}
+		}
+		return target, nil, nil
+	}
+
+	name, path, _ := strings.Cut(target, "/")
+	host, ok := c.hosts[name]
+	if !ok {
+		return "", nil, exception.New("no host registered for " + name + ", or URL is not absolute: " + target)
+	}
+	return strings.TrimSuffix(host.BaseURL, "/") + "/" + path, &host, nil
+}
+
+// lookup returns the host whose BaseURL, or one of whose Pool endpoints,
+// has the given authority, if any
+func (c config) lookup(authority string) *Host {
+	for _, host := range c.hosts {
+		base, err := url.Parse(host.BaseURL)
+		if err == nil && base.Host == authority {
+			return &host
+		}
+		if host.Pool == nil {
+			continue
+		}
+		for _, status := range host.Pool.Status() {
+			endpoint, err := url.Parse(status.Endpoint)
+			if err == nil && endpoint.Host == authority {
+				return &host
+			}
+		}
+	}
+	return nil
+}
+
+// apply adds the static headers and credentials of host to req, and points
+// it at the endpoint picked from the host Pool. The returned release must be
+// called once the response is handled, with whether the endpoint could not
+// be reached.
+func (h *Host) apply(req *http.Request) (release func(unreachable bool), err error) {
+	release = func(bool) {}
+	if h == nil {
+		return release, nil
+	}
+	if h.Pool != nil {
+		if release, err = h.pick(req); err != nil {
+			return release, err
+		}
+	}
+	for key, values := range h.Header {
+		if _, ok := req.Header[key]; !ok {
+			req.Header[key] = values
+		}
+	}
+	if h.Auth == nil {
+		return release, nil
+	}
+	if err := h.Auth(req); err != nil {
+		release(false)
+		return func(bool) {}, exception.Wrap(err, "failed to authenticate request")
+	}
+	return release, nil
+}
+
+// pick points req at an endpoint of the host Pool
+func (h *Host) pick(req *http.Request) (func(unreachable bool), error) {
+	endpoint, done := "", func() {}
+	var err error
+	if key, ok := req.Context().Value(affinityKey{}).(string); ok && key != "" {
+		endpoint, done, err = h.Pool.PickByKey(key)
+	} else {
+		endpoint, err = h.Pool.Pick()
+	}
+	if err != nil {
+		return func(bool) {}, err
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		done()
+		return func(bool) {}, exception.Wrap(err, "failed to parse upstream endpoint")
+	}
+	req.URL.Scheme, req.URL.Host, req.Host = u.Scheme, u.Host, ""
+	return func(unreachable bool) {
+		done()
+		if unreachable {
+			h.Pool.MarkHealthy(endpoint, false)
+		}
+	}, nil
+}
@@ -0,0 +1,190 @@
+package clienthelper
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/aeramu/apihelper/exception"
+	"github.com/aeramu/apihelper/httphelper"
+)
+
+// Mock is an http.RoundTripper answering requests with canned envelopes
+// instead of sending them, so code calling upstreams through clienthelper
+// can be unit tested without httptest servers. Routes are programmed with
+// On; requests matching no route get a NOT_FOUND envelope.
+//
+// Example usage:
+//
+//	mock := clienthelper.NewMock()
+//	mock.On(http.MethodGet, "/v1/orders/1").
+//	    Fail(exception.ErrorUnavailable).
+//	    Return(Order{ID: "1"})
+//	mock.On(http.MethodPost, "/v1/orders").Delay(50 * time.Millisecond).ReturnStatus(http.StatusCreated, Order{ID: "2"})
+//
+//	order, err := clienthelper.Call[Order](ctx, mock.Client(), http.MethodGet, "http://orders/v1/orders/1", nil)
+type Mock struct {
+	mu     sync.Mutex
+	routes []*MockRoute
+}
+
+// MockRoute is the programmed behaviour of a method and path. Every Return
+// or Fail call appends a response to its sequence; requests are answered in
+// order and the last response repeats once the sequence is exhausted.
+type MockRoute struct {
+	method    string
+	path      string
+	mu        sync.Mutex
+	delay     time.Duration
+	responses []mockResponse
+	calls     int
+}
+
+// mockResponse is a canned response of a MockRoute
+type mockResponse struct {
+	status int
+	data   any
+	err    error
+}
+
+// NewMock creates a Mock without routes
+func NewMock() *Mock {
+	return &Mock{}
+}
+
+// Client returns an HTTP client sending its requests to the mock
+func (m *Mock) Client() *http.Client {
+	return &http.Client{Transport: m}
+}
+
+// On returns the route for method and path, creating it if needed.
+// An empty method matches any method.
+func (m *Mock) On(method, path string) *MockRoute {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, route := range m.routes {
+		if route.method == method && route.path == path {
+			return route
+		}
+	}
+	route := &MockRoute{method: method, path: path}
+	m.routes = append(m.routes, route)
+	return route
+}
+
+// Calls returns the number of requests answered by the route for method and path
+func (m *Mock) Calls(method, path string) int {
+	route := m.On(method, path)
+	route.mu.Lock()
+	defer route.mu.Unlock()
+	return route.calls
+}
+
+// Return appends a 200 response with data to the route sequence
+func (r *MockRoute) Return(data any) *MockRoute {
+	return r.ReturnStatus(http.StatusOK, data)
+}
+
+// ReturnStatus appends a successful response with status and data to the
+// route sequence
+func (r *MockRoute) ReturnStatus(status int, data any) *MockRoute {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.responses = append(r.responses, mockResponse{status: status, data: data})
+	return r
+}
+
+// Fail appends an error envelope rendered from err, as httphelper.Error
+// writes it, to the route sequence
+func (r *MockRoute) Fail(err error) *MockRoute {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.responses = append(r.responses, mockResponse{err: err})
+	return r
+}
+
+// Delay makes the route wait d before answering, or until the request
+// context is done
+func (r *MockRoute) Delay(d time.Duration) *MockRoute {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.delay = d
+	return r
+}
+
+// RoundTrip answers req with the next response of the matching route
+func (m *Mock) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	route := m.route(req)
+	rec := httptest.NewRecorder()
+	if route == nil {
+		httphelper.Error(rec, exception.New("no mock route for "+req.Method+" "+req.URL.Path,
+			exception.WithStatus(exception.StatusNotFound),
+			exception.WithCode(exception.CodeNotFound),
+		))
+		return response(rec, req), nil
+	}
+
+	resp, delay := route.next()
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+	switch {
+	case resp.err != nil:
+		httphelper.Error(rec, resp.err)
+	default:
+		body, err := json.Marshal(httphelper.Response{Status: resp.status, Success: true, Data: resp.data})
+		if err != nil {
+			return nil, err
+		}
+		rec.Header().Set("Content-Type", httphelper.ContentTypeJSON)
+		rec.WriteHeader(resp.status)
+		rec.Write(body)
+	}
+	return response(rec, req), nil
+}
+
+// route returns the route matching req, preferring an exact method match
+func (m *Mock) route(req *http.Request) *MockRoute {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var fallback *MockRoute
+	for _, route := range m.routes {
+		if route.path != req.URL.Path || len(route.responses) == 0 {
+			continue
+		}
+		if route.method == req.Method {
+			return route
+		}
+		if route.method == "" && fallback == nil {
+			fallback = route
+		}
+	}
+	return fallback
+}
+
+// next returns the response to the current call and advances the sequence
+func (r *MockRoute) next() (mockResponse, time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := min(r.calls, len(r.responses)-1)
+	r.calls++
+	return r.responses[i], r.delay
+}
+
+// response converts the recorded envelope into the response to req
+func response(rec *httptest.ResponseRecorder, req *http.Request) *http.Response {
+	resp := rec.Result()
+	resp.Request = req
+	return resp
+}
@@ -0,0 +1,69 @@
+package clienthelper
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/aeramu/apihelper/httphelper"
+	"github.com/go-resty/resty/v2"
+)
+
+// restyResultKey is the context key holding the result set via SetResult
+// while resty sends the request
+type restyResultKey struct{}
+
+// InstallResty registers middlewares on client that decode the standard
+// envelope of every response. Error envelopes are returned as exceptions
+// from the request method, preserving their code, message and status, and
+// the envelope data is decoded into the result set via SetResult.
+//
+// Example usage:
+//
+//	client := resty.New()
+//	clienthelper.InstallResty(client)
+//
+//	var order Order
+//	_, err := client.R().SetResult(&order).Get(orderURL)
+//	if err != nil {
+//	    return err // an exception carrying the upstream code and message
+//	}
+//
+// Parameters:
+//   - client: The resty client to install the middlewares on
+func InstallResty(client *resty.Client) {
+	client.OnBeforeRequest(func(_ *resty.Client, req *resty.Request) error {
+		if req.Header.Get("Accept") == "" {
+			req.SetHeader("Accept", httphelper.ContentTypeJSON)
+		}
+		if req.Result == nil {
+			return nil
+		}
+		// resty would decode the whole envelope into the result, so hold it
+		// back until the envelope has been decoded
+		req.SetContext(context.WithValue(req.Context(), restyResultKey{}, req.Result))
+		req.Result = nil
+		return nil
+	})
+	client.OnAfterResponse(func(_ *resty.Client, resp *resty.Response) error {
+		result := resp.Request.Context().Value(restyResultKey{})
+		if result != nil {
+			resp.Request.Result = result
+		}
+
+		envelope, err := decodeEnvelope(resp.Request.RawRequest.URL, resp.Body(), resp.StatusCode())
+		if err != nil || result == nil || envelope.Data == nil {
+			return err
+		}
+		data, ok := envelope.Data.(json.RawMessage)
+		if !ok {
+			// adapters may return decoded values
+			if data, err = json.Marshal(envelope.Data); err != nil {
+				return dataError(err)
+			}
+		}
+		if err := json.Unmarshal(data, result); err != nil {
+			return dataError(err)
+		}
+		return nil
+	})
+}
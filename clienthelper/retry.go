@@ -0,0 +1,113 @@
+package clienthelper
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/aeramu/apihelper/exception"
+)
+
+// RetryPolicy describes how Call retries failed requests. Only requests
+// with an idempotent method, or carrying an Idempotency-Key header, are
+// retried, and only when they fail with one of the retryable codes.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry, doubled for every
+	// following one
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff between attempts, including the delays
+	// requested by the server through Retry-After
+	MaxDelay time.Duration
+	// Codes lists the retryable exception codes, DefaultRetryCodes if empty
+	Codes []string
+}
+
+// DefaultRetryCodes are the exception codes retried when a RetryPolicy
+// does not list its own
+var DefaultRetryCodes = []string{
+	exception.CodeUnavailable.String(),
+	exception.CodeDeadlineExceeded.String(),
+	exception.CodeResourceExhausted.String(),
+}
+
+// idempotentMethods are the methods safe to send more than once
+var idempotentMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodOptions,
+	http.MethodTrace,
+	http.MethodPut,
+	http.MethodDelete,
+}
+
+// retryable reports whether a request with the given method and header
+// failing with err may be sent again
+func (p RetryPolicy) retryable(method string, header http.Header, err error) bool {
+	if !slices.Contains(idempotentMethods, method) && header.Get("Idempotency-Key") == "" {
+		return false
+	}
+	code, ok := exception.AsErrorCode(err)
+	if !ok {
+		return false
+	}
+	codes := p.Codes
+	if len(codes) == 0 {
+		codes = DefaultRetryCodes
+	}
+	return slices.Contains(codes, code.Code())
+}
+
+// backoff returns the jittered delay before the retry following attempt.
+// A Retry-After requested by the server takes precedence when longer, up
+// to MaxDelay.
+func (p RetryPolicy) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	delay := p.BaseDelay << (attempt - 1)
+	if delay <= 0 || (p.MaxDelay > 0 && delay > p.MaxDelay) {
+		delay = p.MaxDelay
+	}
+	if delay > 0 {
+		delay = delay/2 + rand.N(delay/2+1)
+	}
+	delay = max(delay, retryAfter)
+	if p.MaxDelay > 0 {
+		delay = min(delay, p.MaxDelay)
+	}
+	return delay
+}
+
+// wait sleeps for delay, reporting false if ctx is done first. It gives up
+// right away when the delay would outlast the ctx deadline.
+func wait(ctx context.Context, delay time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return false
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an
+// HTTP date, returning zero if absent or invalid
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0)
+	}
+	return 0
+}
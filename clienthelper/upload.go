@@ -0,0 +1,130 @@
+package clienthelper
+
+import (
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/aeramu/apihelper/exception"
+	"github.com/aeramu/apihelper/httphelper"
+)
+
+// Upload sends a multipart/form-data POST request to url with the given
+// files and form fields and decodes the envelope of the response. The body
+// is streamed, so files are never buffered whole in memory. Files are named
+// after their form field, or after their base name when the reader has a
+// Name method like *os.File. Error envelopes are converted into exceptions
+// preserving their code, message and status.
+//
+// Example usage:
+//
+//	f, err := os.Open("invoice.pdf")
+//	if err != nil {
+//	    return err
+//	}
+//	defer f.Close()
+//	resp, err := clienthelper.Upload(ctx, documentsURL,
+//	    map[string]io.Reader{"document": f},
+//	    map[string]string{"type": "invoice"})
+//	if err != nil {
+//	    return err
+//	}
+//	doc, err := httphelper.ReadData[Document](resp)
+//
+// Parameters:
+//   - ctx: The request context
+//   - url: The URL to upload to, or the path on a host registered with WithHost
+//   - files: The file contents by form field name
+//   - fields: The form field values by name
+//
+// Returns:
+//   - The decoded response envelope
+//   - An exception if the upload fails or the response is an error envelope
+func Upload(ctx context.Context, url string, files map[string]io.Reader, fields map[string]string) (httphelper.Response, error) {
+	cfg := defaultConfig.Load()
+	url, host, err := cfg.resolve(url)
+	if err != nil {
+		return httphelper.Response{}, err
+	}
+	ctx, cancel, err := withDeadline(ctx, cfg.margin)
+	if err != nil {
+		return httphelper.Response{}, err
+	}
+	defer cancel()
+
+	pr, pw := io.Pipe()
+	form := multipart.NewWriter(pw)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, pr)
+	if err != nil {
+		pr.Close()
+		return httphelper.Response{}, exception.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Accept", httphelper.ContentTypeJSON)
+	release, err := host.apply(req)
+	if err != nil {
+		pr.Close()
+		return httphelper.Response{}, err
+	}
+	// the transport closes the body once the request is done, which also
+	// stops the writer when the server answers without reading everything
+	go func() {
+		pw.CloseWithError(writeMultipart(form, files, fields))
+	}()
+
+	resp, err := roundTrip(cfg.client, req, cfg.breakers)
+	if err != nil {
+		pr.Close()
+		release(req.Context().Err() == nil)
+		return httphelper.Response{}, err
+	}
+	defer release(false)
+	defer resp.Body.Close()
+
+	body, err := readBody(resp)
+	if err != nil {
+		cfg.breakers.record(req.URL.Host, err, time.Now())
+		return httphelper.Response{}, err
+	}
+	envelope, err := decodeEnvelope(req.URL, body, resp.StatusCode)
+	cfg.breakers.record(req.URL.Host, err, time.Now())
+	return envelope, err
+}
+
+// writeMultipart writes fields then files to form in name order, closing
+// form once done
+func writeMultipart(form *multipart.Writer, files map[string]io.Reader, fields map[string]string) error {
+	for _, name := range sortedKeys(fields) {
+		if err := form.WriteField(name, fields[name]); err != nil {
+			return err
+		}
+	}
+	for _, name := range sortedKeys(files) {
+		filename := name
+		if named, ok := files[name].(interface{ Name() string }); ok {
+			filename = filepath.Base(named.Name())
+		}
+		part, err := form.CreateFormFile(name, filename)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(part, files[name]); err != nil {
+			return err
+		}
+	}
+	return form.Close()
+}
+
+// sortedKeys returns the keys of m in ascending order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
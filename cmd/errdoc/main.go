@@ -0,0 +1,37 @@
+// Command errdoc prints the registered exception catalog as Markdown or JSON.
+//
+// Usage:
+//
+//	go run github.com/aeramu/apihelper/cmd/errdoc -format markdown > errors.md
+//
+// Only exceptions registered by this module are known to the command; services
+// with their own codes should call the errdoc package from their own tooling.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/aeramu/apihelper/errdoc"
+	"github.com/aeramu/apihelper/exception"
+)
+
+func main() {
+	format := flag.String("format", "markdown", "output format: markdown or json")
+	flag.Parse()
+
+	var err error
+	switch *format {
+	case "markdown", "md":
+		err = errdoc.Markdown(os.Stdout, exception.Catalog())
+	case "json":
+		err = errdoc.JSON(os.Stdout, exception.Catalog())
+	default:
+		err = fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
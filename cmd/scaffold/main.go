@@ -0,0 +1,29 @@
+// Command scaffold generates a minimal service using the apihelper golden path.
+//
+// Usage:
+//
+//	go run github.com/aeramu/apihelper/cmd/scaffold -module github.com/acme/orders -dir ./orders
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/aeramu/apihelper/scaffold"
+)
+
+func main() {
+	var cfg scaffold.Config
+	flag.StringVar(&cfg.Module, "module", "", "Go module path of the service (required)")
+	flag.StringVar(&cfg.Name, "name", "", "service name, defaults to the last element of the module path")
+	flag.StringVar(&cfg.Owner, "owner", "", "team owning the service error codes, defaults to the name")
+	flag.IntVar(&cfg.Port, "port", 8080, "port the service listens on")
+	dir := flag.String("dir", ".", "directory to generate the service in")
+	flag.Parse()
+
+	if err := scaffold.Generate(*dir, cfg); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,61 @@
+package errdoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aeramu/apihelper/exception"
+)
+
+// Package errdoc renders the exception catalog as documentation for API consumers.
+//
+// Example usage:
+//
+//	f, _ := os.Create("errors.md")
+//	defer f.Close()
+//	errdoc.Markdown(f, exception.Catalog())
+
+// Markdown writes the entries as a Markdown table
+func Markdown(w io.Writer, entries []exception.Entry) error {
+	var b strings.Builder
+	b.WriteString("# Error Codes\n\n")
+	b.WriteString("| Code | Message | HTTP Status | gRPC Status | Owner | Stability | Runbook |\n")
+	b.WriteString("| ---- | ------- | ----------- | ----------- | ----- | --------- | ------- |\n")
+	for _, entry := range entries {
+		fmt.Fprintf(&b, "| `%s` | %s | %d | `%s` | %s | %s | %s |\n",
+			entry.Code,
+			escape(entry.Message),
+			entry.HTTPStatus,
+			entry.GRPCStatus,
+			escape(entry.Owner),
+			entry.Stability,
+			runbookLink(entry.Runbook),
+		)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// JSON writes the entries as an indented JSON array
+func JSON(w io.Writer, entries []exception.Entry) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+// runbookLink renders the runbook URL as a Markdown link
+func runbookLink(url string) string {
+	if url == "" {
+		return ""
+	}
+	return fmt.Sprintf("[runbook](%s)", url)
+}
+
+// escape prevents messages from breaking the Markdown table layout
+func escape(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
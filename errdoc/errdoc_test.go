@@ -0,0 +1,44 @@
+package errdoc_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/aeramu/apihelper/errdoc"
+	"github.com/aeramu/apihelper/exception"
+	"github.com/stretchr/testify/assert"
+)
+
+var entries = []exception.Entry{
+	{
+		Code:       "NOT_FOUND",
+		Status:     exception.StatusNotFound.String(),
+		Message:    "data | not found",
+		HTTPStatus: 404,
+		GRPCStatus: "NOT_FOUND",
+		Owner:      "catalog",
+		Runbook:    "https://runbooks.example.com/not-found",
+		Stability:  exception.StabilityStable,
+	},
+}
+
+func TestMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := errdoc.Markdown(&buf, entries)
+
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "| `NOT_FOUND` | data \\| not found | 404 | `NOT_FOUND` | catalog | stable | [runbook](https://runbooks.example.com/not-found) |")
+}
+
+func TestJSON(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := errdoc.JSON(&buf, entries)
+
+	var result []exception.Entry
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	assert.Equal(t, entries, result)
+}
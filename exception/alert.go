@@ -0,0 +1,111 @@
+package exception
+
+import (
+	"errors"
+	"sync"
+)
+
+const (
+	// AnnotationRunbook is the annotation key holding the runbook URL
+	AnnotationRunbook = "runbook_url"
+	// AnnotationOwner is the annotation key holding the owning team
+	AnnotationOwner = "owner"
+	// AnnotationCode is the annotation key holding the exception code
+	AnnotationCode = "code"
+	// AnnotationStatus is the annotation key holding the exception status
+	AnnotationStatus = "status"
+)
+
+// AlertRule attaches alerting annotations to exceptions with the given code
+// or status. Rules matching the code take precedence over rules matching the
+// status only. Runbook and Owner default to the ones registered for the code.
+type AlertRule struct {
+	// Code matches exceptions with this code, empty to match on Status only
+	Code Code
+	// Status matches exceptions with this status when Code is empty
+	Status Status
+	// Runbook is the URL of the runbook for the alert
+	Runbook string
+	// Owner is the team paged for the alert
+	Owner string
+}
+
+var (
+	alertMu    sync.RWMutex
+	alertRules []AlertRule
+)
+
+// AddAlertRule registers a rule used by Annotations
+func AddAlertRule(rule AlertRule) {
+	alertMu.Lock()
+	defer alertMu.Unlock()
+	alertRules = append(alertRules, rule)
+}
+
+// Annotations returns the alerting annotations of err, or nil when no
+// alert rule matches it. The annotations are meant to be attached to logs
+// and error reports so pages created from them reach the right team with
+// the right runbook.
+//
+// Example usage:
+//
+//	exception.AddAlertRule(exception.AlertRule{
+//	    Code:    "PAYMENT_DECLINED",
+//	    Runbook: "https://runbooks.example.com/payments",
+//	    Owner:   "payments",
+//	})
+//	exception.OnCreate(func(err error) {
+//	    if annotations := exception.Annotations(err); annotations != nil {
+//	        reporter.Report(err, annotations)
+//	    }
+//	})
+func Annotations(err error) map[string]string {
+	var e *exception
+	if !errors.As(err, &e) {
+		return nil
+	}
+	rule, ok := matchAlertRule(e.code, e.status)
+	if !ok {
+		return nil
+	}
+
+	annotations := map[string]string{
+		AnnotationCode:   e.code.String(),
+		AnnotationStatus: e.status.String(),
+	}
+	if entry, ok := Lookup(e.code.String()); ok {
+		if rule.Runbook == "" {
+			rule.Runbook = entry.Runbook
+		}
+		if rule.Owner == "" {
+			rule.Owner = entry.Owner
+		}
+	}
+	if rule.Runbook != "" {
+		annotations[AnnotationRunbook] = rule.Runbook
+	}
+	if rule.Owner != "" {
+		annotations[AnnotationOwner] = rule.Owner
+	}
+	return annotations
+}
+
+func matchAlertRule(code Code, status Status) (AlertRule, bool) {
+	alertMu.RLock()
+	defer alertMu.RUnlock()
+
+	var statusRule AlertRule
+	var statusMatched bool
+	for _, rule := range alertRules {
+		if rule.Code != "" {
+			if rule.Code == code {
+				return rule, true
+			}
+			continue
+		}
+		if !statusMatched && rule.Status == status {
+			statusRule, statusMatched = rule, true
+		}
+	}
+	return statusRule, statusMatched
+}
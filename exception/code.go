@@ -1,54 +1,97 @@
 package exception
 
-// Common error codes that are protocol-agnostic
+// Status is a protocol-agnostic error category. It drives how an exception
+// is mapped to transport-level statuses such as HTTP and gRPC codes.
+type Status string
+
+// Code is a machine-readable error identifier exposed to clients
+type Code string
+
+// String returns the status as a plain string for wire formats
+func (s Status) String() string {
+	return string(s)
+}
+
+// String returns the code as a plain string for wire formats
+func (c Code) String() string {
+	return string(c)
+}
+
+// Common error statuses that are protocol-agnostic
+const (
+	// System/Infrastructure errors
+	StatusInternal         Status = "INTERNAL"
+	StatusUnavailable      Status = "UNAVAILABLE"
+	StatusDeadlineExceeded Status = "DEADLINE_EXCEEDED"
+	StatusThirdParty       Status = "THIRD_PARTY"
+
+	// Input/Validation errors
+	StatusInvalidRequest   Status = "INVALID_REQUEST"
+	StatusValidationFailed Status = "VALIDATION_FAILED"
+
+	// Authentication/Authorization errors
+	StatusUnauthenticated  Status = "UNAUTHENTICATED"
+	StatusPermissionDenied Status = "PERMISSION_DENIED"
+
+	// Resource errors
+	StatusNotFound          Status = "NOT_FOUND"
+	StatusAlreadyExists     Status = "ALREADY_EXISTS"
+	StatusRaceCondition     Status = "RACE_CONDITION"
+	StatusResourceExhausted Status = "RESOURCE_EXHAUSTED"
+
+	// Soft errors
+	StatusSoftError Status = "SOFT_ERROR"
+)
+
+// Common error codes, one per status
 const (
 	// System/Infrastructure errors
-	CodeInternal         = "INTERNAL"
-	CodeUnavailable      = "UNAVAILABLE"
-	CodeDeadlineExceeded = "DEADLINE_EXCEEDED"
-	CodeThirdParty       = "THIRD_PARTY"
+	CodeInternal         Code = "INTERNAL"
+	CodeUnavailable      Code = "UNAVAILABLE"
+	CodeDeadlineExceeded Code = "DEADLINE_EXCEEDED"
+	CodeThirdParty       Code = "THIRD_PARTY"
 
 	// Input/Validation errors
-	CodeInvalidRequest   = "INVALID_REQUEST"
-	CodeValidationFailed = "VALIDATION_FAILED"
+	CodeInvalidRequest   Code = "INVALID_REQUEST"
+	CodeValidationFailed Code = "VALIDATION_FAILED"
 
 	// Authentication/Authorization errors
-	CodeUnauthenticated  = "UNAUTHENTICATED"
-	CodePermissionDenied = "PERMISSION_DENIED"
+	CodeUnauthenticated  Code = "UNAUTHENTICATED"
+	CodePermissionDenied Code = "PERMISSION_DENIED"
 
 	// Resource errors
-	CodeNotFound          = "NOT_FOUND"
-	CodeAlreadyExists     = "ALREADY_EXISTS"
-	CodeRaceCondition     = "RACE_CONDITION"
-	CodeResourceExhausted = "RESOURCE_EXHAUSTED"
+	CodeNotFound          Code = "NOT_FOUND"
+	CodeAlreadyExists     Code = "ALREADY_EXISTS"
+	CodeRaceCondition     Code = "RACE_CONDITION"
+	CodeResourceExhausted Code = "RESOURCE_EXHAUSTED"
 
 	// Soft errors
-	CodeSoftError = "SOFT_ERROR"
+	CodeSoftError Code = "SOFT_ERROR"
 )
 
 
 var (
 	// Base errors
-	ErrorInvalidRequest    = newError(CodeInvalidRequest, "invalid request")
-	ErrorValidationFailed  = newError(CodeValidationFailed, "validation failed")
-	ErrorPermissionDenied  = newError(CodePermissionDenied, "permission denied")
-	ErrorNotFound          = newError(CodeNotFound, "data not found")
-	ErrorAlreadyExists     = newError(CodeAlreadyExists, "data already exists")
-	ErrorRaceCondition     = newError(CodeRaceCondition, "race condition")
-	ErrorResourceExhausted = newError(CodeResourceExhausted, "resource exhausted")
-	ErrorUnauthenticated   = newError(CodeUnauthenticated, "unauthenticated")
-	ErrorInternal          = newError(CodeInternal, "internal server error")
-	ErrorUnavailable       = newError(CodeUnavailable, "service unavailable")
-	ErrorDeadlineExceeded  = newError(CodeDeadlineExceeded, "deadline exceeded")
-	ErrorSoftError         = newError(CodeSoftError, "soft error")
+	ErrorInvalidRequest    = newError(StatusInvalidRequest, "invalid request")
+	ErrorValidationFailed  = newError(StatusValidationFailed, "validation failed")
+	ErrorPermissionDenied  = newError(StatusPermissionDenied, "permission denied")
+	ErrorNotFound          = newError(StatusNotFound, "data not found")
+	ErrorAlreadyExists     = newError(StatusAlreadyExists, "data already exists")
+	ErrorRaceCondition     = newError(StatusRaceCondition, "race condition")
+	ErrorResourceExhausted = newError(StatusResourceExhausted, "resource exhausted")
+	ErrorUnauthenticated   = newError(StatusUnauthenticated, "unauthenticated")
+	ErrorInternal          = newError(StatusInternal, "internal server error")
+	ErrorUnavailable       = newError(StatusUnavailable, "service unavailable")
+	ErrorDeadlineExceeded  = newError(StatusDeadlineExceeded, "deadline exceeded")
+	ErrorSoftError         = newError(StatusSoftError, "soft error")
 
 	// Common errors
 )
 
-func newError(status string, message string) error {
-	return New(message,
+func newError(status Status, message string) error {
+	return Register(New(message,
 		WithStatus(status),
-		WithCode(status),
+		WithCode(Code(status)),
 		WithMessage(message),
-	)
+	))
 }
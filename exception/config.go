@@ -0,0 +1,63 @@
+package exception
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// Configuration options
+type config struct {
+	unknownHTTPStatus int
+	unknownStatusHook func(status Status)
+}
+
+// Option represents a configuration option for the exception package
+type Option func(*config)
+
+// defaultConfig holds the configuration changed by Configure. It is replaced
+// as a whole and never mutated, so statuses can be looked up concurrently
+// with Configure.
+var defaultConfig atomic.Pointer[config]
+
+// configureMu serializes Configure calls so concurrent updates are not lost
+var configureMu sync.Mutex
+
+func init() {
+	defaultConfig.Store(&config{
+		unknownHTTPStatus: http.StatusInternalServerError,
+	})
+}
+
+// WithUnknownStatusPolicy sets the HTTP status code used for statuses that
+// are neither defined by this package nor registered with RegisterStatus,
+// 500 by default. hook, if not nil, is called with every unknown status
+// looked up, so typos and unregistered statuses are visible instead of
+// silently turning into server errors.
+//
+// Example usage:
+//
+//	exception.Configure(exception.WithUnknownStatusPolicy(http.StatusInternalServerError, func(status exception.Status) {
+//	    unknownStatuses.WithLabelValues(status.String()).Inc()
+//	}))
+func WithUnknownStatusPolicy(httpStatus int, hook func(status Status)) Option {
+	return func(c *config) {
+		c.unknownHTTPStatus = httpStatus
+		c.unknownStatusHook = hook
+	}
+}
+
+// Configure applies the provided options to the default configuration.
+//
+// Parameters:
+//   - opts: A variadic list of Option functions to apply
+func Configure(opts ...Option) {
+	configureMu.Lock()
+	defer configureMu.Unlock()
+
+	cfg := *defaultConfig.Load()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	defaultConfig.Store(&cfg)
+}
@@ -1,9 +1,14 @@
 package exception_test
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/aeramu/apihelper/exception"
 	"github.com/aeramu/apihelper/httphelper"
@@ -12,7 +17,7 @@ import (
 
 func TestSoftError_HTTP(t *testing.T) {
 	err := exception.New("error",
-		exception.WithStatus(exception.CodeSoftError),
+		exception.WithStatus(exception.StatusSoftError),
 		exception.WithCode("TEST_CODE"),
 		exception.WithMessage("message"),
 	)
@@ -28,7 +33,7 @@ func TestSoftError_HTTP(t *testing.T) {
 
 func TestInvalidArgumentError(t *testing.T) {
 	err := exception.New("error",
-		exception.WithStatus(exception.CodeInvalidRequest),
+		exception.WithStatus(exception.StatusInvalidRequest),
 		exception.WithCode("TEST_CODE"),
 		exception.WithMessage("message"),
 	)
@@ -57,7 +62,7 @@ func TestWrap(t *testing.T) {
 
 func TestAsErrorCode(t *testing.T) {
 	err := exception.New("error",
-		exception.WithStatus(exception.CodeSoftError),
+		exception.WithStatus(exception.StatusSoftError),
 		exception.WithCode("TEST_CODE"),
 		exception.WithMessage("message"),
 	)
@@ -68,3 +73,275 @@ func TestAsErrorCode(t *testing.T) {
 	assert.Equal(t, "TEST_CODE", code.Code())
 	assert.Equal(t, "error", code.Error())
 }
+
+func TestOnCreate(t *testing.T) {
+	var created []string
+	exception.OnCreate(func(err error) {
+		created = append(created, err.Error())
+	})
+
+	base := exception.New("base")
+	_ = exception.Wrap(base, "wrapped")
+	_ = exception.Normalize(errors.New("legacy"))
+
+	assert.Equal(t, []string{"base", "wrapped: base", "legacy"}, created)
+}
+
+func TestOnCreate_Concurrent(t *testing.T) {
+	var calls atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			exception.OnCreate(func(err error) { calls.Add(1) })
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		_ = exception.New("concurrent")
+	}
+	wg.Wait()
+
+	calls.Store(0)
+	_ = exception.New("registered")
+	assert.Equal(t, int32(10), calls.Load())
+}
+
+func TestCatalog(t *testing.T) {
+	exception.Register(exception.New("order not found",
+		exception.WithStatus(exception.StatusNotFound),
+		exception.WithCode("ORDER_NOT_FOUND"),
+		exception.WithMessage("order not found"),
+	))
+
+	var found bool
+	for _, entry := range exception.Catalog() {
+		if entry.Code == "ORDER_NOT_FOUND" {
+			found = true
+			assert.Equal(t, exception.StatusNotFound.String(), entry.Status)
+			assert.Equal(t, http.StatusNotFound, entry.HTTPStatus)
+			assert.Equal(t, "NOT_FOUND", entry.GRPCStatus)
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestToProblem(t *testing.T) {
+	err := exception.New("order 1 not found",
+		exception.WithStatus(exception.StatusNotFound),
+		exception.WithCode("ORDER_NOT_FOUND"),
+		exception.WithMessage("order not found"),
+	)
+
+	problem := exception.ToProblem(err)
+	b, marshalErr := json.Marshal(problem)
+
+	assert.NoError(t, marshalErr)
+	assert.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "order not found",
+		"status": 404,
+		"detail": "order 1 not found",
+		"code": "ORDER_NOT_FOUND"
+	}`, string(b))
+}
+
+func TestOps(t *testing.T) {
+	repo := func() error {
+		return exception.Wrap(exception.ErrorNotFound, "order not found", exception.WithOp("repo.FindOrder"))
+	}
+	service := func() error {
+		return exception.Wrap(fmt.Errorf("load order: %w", repo()), "failed to create order", exception.WithOp("orders.Create"))
+	}
+
+	var opErr interface{ Ops() []string }
+	assert.True(t, errors.As(service(), &opErr))
+	assert.Equal(t, []string{"orders.Create", "repo.FindOrder"}, opErr.Ops())
+}
+
+func TestJoin(t *testing.T) {
+	errOther := errors.New("other")
+
+	err := exception.Join(nil, errOther, exception.ErrorNotFound)
+
+	code, ok := exception.AsErrorCode(err)
+	assert.True(t, ok)
+	assert.Equal(t, exception.CodeNotFound.String(), code.Code())
+	assert.True(t, errors.Is(err, errOther))
+	assert.True(t, errors.Is(err, exception.ErrorNotFound))
+	assert.Nil(t, exception.Join(nil, nil))
+}
+
+func TestRenderMessage(t *testing.T) {
+	message := exception.RenderMessage("order {order_id} not found in {store}", map[string]any{
+		"order_id": "<script>",
+	})
+
+	assert.Equal(t, "order &lt;script&gt; not found in {store}", message)
+}
+
+func TestAnnotations(t *testing.T) {
+	exception.AddAlertRule(exception.AlertRule{
+		Status:  exception.StatusThirdParty,
+		Runbook: "https://runbooks.example.com/third-party",
+		Owner:   "platform",
+	})
+	exception.AddAlertRule(exception.AlertRule{
+		Code:    "PAYMENT_GATEWAY_DOWN",
+		Runbook: "https://runbooks.example.com/payments",
+		Owner:   "payments",
+	})
+
+	err := exception.Wrap(errors.New("timeout"), "charge failed",
+		exception.WithStatus(exception.StatusThirdParty),
+		exception.WithCode("PAYMENT_GATEWAY_DOWN"),
+	)
+	assert.Equal(t, map[string]string{
+		exception.AnnotationCode:    "PAYMENT_GATEWAY_DOWN",
+		exception.AnnotationStatus:  exception.StatusThirdParty.String(),
+		exception.AnnotationRunbook: "https://runbooks.example.com/payments",
+		exception.AnnotationOwner:   "payments",
+	}, exception.Annotations(fmt.Errorf("handler: %w", err)))
+
+	err = exception.New("shipping failed", exception.WithStatus(exception.StatusThirdParty), exception.WithCode("SHIPPING_DOWN"))
+	assert.Equal(t, "platform", exception.Annotations(err)[exception.AnnotationOwner])
+
+	assert.Nil(t, exception.Annotations(exception.ErrorNotFound))
+	assert.Nil(t, exception.Annotations(errors.New("plain")))
+}
+
+func TestRegister_Ownership(t *testing.T) {
+	exception.Register(exception.New("refund failed",
+		exception.WithStatus(exception.StatusThirdParty),
+		exception.WithCode("REFUND_FAILED"),
+	), exception.WithOwner("payments"), exception.WithRunbook("https://runbooks.example.com/refunds"), exception.WithStability(exception.StabilityExperimental))
+
+	entry, ok := exception.Lookup("REFUND_FAILED")
+	assert.True(t, ok)
+	assert.Equal(t, "payments", entry.Owner)
+	assert.Equal(t, "https://runbooks.example.com/refunds", entry.Runbook)
+	assert.Equal(t, exception.StabilityExperimental, entry.Stability)
+
+	entry, _ = exception.Lookup(exception.CodeNotFound.String())
+	assert.Equal(t, exception.StabilityStable, entry.Stability)
+
+	exception.AddAlertRule(exception.AlertRule{Code: "REFUND_FAILED"})
+	annotations := exception.Annotations(exception.New("refund failed", exception.WithCode("REFUND_FAILED")))
+	assert.Equal(t, "payments", annotations[exception.AnnotationOwner])
+	assert.Equal(t, "https://runbooks.example.com/refunds", annotations[exception.AnnotationRunbook])
+}
+
+// legacyError mimics error types from other error packages
+type legacyError struct {
+	code     string
+	httpCode int
+}
+
+func (e legacyError) Error() string   { return "legacy: " + e.code }
+func (e legacyError) Code() string    { return e.code }
+func (e legacyError) Message() string { return "legacy message" }
+func (e legacyError) HTTPCode() int   { return e.httpCode }
+
+func TestNormalize(t *testing.T) {
+	assert.Nil(t, exception.Normalize(nil))
+	assert.Equal(t, exception.ErrorNotFound, exception.Normalize(exception.ErrorNotFound))
+
+	legacy := legacyError{code: "ORDER_MISSING", httpCode: http.StatusNotFound}
+	err := exception.Normalize(fmt.Errorf("handler: %w", legacy))
+	var httpErr httphelper.HTTPError
+	assert.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, "ORDER_MISSING", httpErr.Code())
+	assert.Equal(t, "legacy message", httpErr.Message())
+	assert.Equal(t, http.StatusNotFound, httpErr.HTTPStatus())
+	assert.True(t, exception.HasStatus(err, exception.StatusNotFound))
+	assert.ErrorIs(t, err, legacy)
+
+	err = exception.Normalize(errors.New("boom"))
+	assert.True(t, exception.HasStatus(err, exception.StatusInternal))
+	assert.Equal(t, "boom", err.Error())
+}
+
+func TestHTTPStatusFor(t *testing.T) {
+	const statusPaymentRequired exception.Status = "PAYMENT_REQUIRED"
+	exception.RegisterStatus(statusPaymentRequired, http.StatusPaymentRequired, "FAILED_PRECONDITION")
+
+	var unknown []exception.Status
+	exception.Configure(exception.WithUnknownStatusPolicy(http.StatusBadGateway, func(status exception.Status) {
+		unknown = append(unknown, status)
+	}))
+	defer exception.Configure(exception.WithUnknownStatusPolicy(http.StatusInternalServerError, nil))
+
+	assert.Equal(t, http.StatusNotFound, exception.HTTPStatusFor(exception.StatusNotFound.String()))
+	assert.Equal(t, http.StatusPaymentRequired, exception.HTTPStatusFor("PAYMENT_REQUIRED"))
+	assert.Equal(t, http.StatusBadGateway, exception.HTTPStatusFor("TYPO"))
+	assert.Equal(t, []exception.Status{"TYPO"}, unknown)
+
+	err := exception.New("payment required", exception.WithStatus(statusPaymentRequired))
+	var httpErr httphelper.HTTPError
+	assert.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, http.StatusPaymentRequired, httpErr.HTTPStatus())
+	var grpcErr interface{ GRPCStatus() string }
+	assert.True(t, errors.As(err, &grpcErr))
+	assert.Equal(t, "FAILED_PRECONDITION", grpcErr.GRPCStatus())
+}
+
+func TestHTTPStatusFor_BuiltinStatuses(t *testing.T) {
+	var unknown []exception.Status
+	exception.Configure(exception.WithUnknownStatusPolicy(http.StatusInternalServerError, func(status exception.Status) {
+		unknown = append(unknown, status)
+	}))
+	defer exception.Configure(exception.WithUnknownStatusPolicy(http.StatusInternalServerError, nil))
+
+	statuses := []exception.Status{
+		exception.StatusInternal, exception.StatusUnavailable, exception.StatusDeadlineExceeded,
+		exception.StatusThirdParty, exception.StatusInvalidRequest, exception.StatusValidationFailed,
+		exception.StatusUnauthenticated, exception.StatusPermissionDenied, exception.StatusNotFound,
+		exception.StatusAlreadyExists, exception.StatusRaceCondition, exception.StatusResourceExhausted,
+		exception.StatusSoftError,
+	}
+	for _, status := range statuses {
+		exception.HTTPStatusFor(status.String())
+		var grpcErr interface{ GRPCStatus() string }
+		assert.True(t, errors.As(exception.New("failed", exception.WithStatus(status)), &grpcErr))
+		assert.NotEqual(t, "UNKNOWN", grpcErr.GRPCStatus(), status.String())
+	}
+	assert.Empty(t, unknown)
+	assert.Equal(t, http.StatusBadGateway, exception.HTTPStatusFor(exception.StatusThirdParty.String()))
+}
+
+func TestFromPanic(t *testing.T) {
+	err := exception.FromPanic("boom")
+	assert.True(t, exception.HasStatus(err, exception.StatusInternal))
+	assert.Equal(t, "panic: boom", err.Error())
+
+	cause := errors.New("nil map")
+	err = exception.FromPanic(cause)
+	assert.ErrorIs(t, err, cause)
+}
+
+func TestValidationErrors(t *testing.T) {
+	errs := exception.ValidationErrors{
+		{Field: "name", Code: "required", Message: "name is required"},
+		{Field: "age", Message: "must be positive"},
+	}
+	assert.Equal(t, "validation failed: name: name is required; age: must be positive", errs.Error())
+
+	problem := exception.ToProblem(fmt.Errorf("create user: %w", errs))
+	assert.Equal(t, http.StatusUnprocessableEntity, problem.Status)
+	assert.Equal(t, exception.CodeValidationFailed.String(), problem.Extensions["code"])
+	assert.Equal(t, []exception.FieldError(errs), problem.Extensions["errors"])
+}
+
+func TestWithRetryAfter(t *testing.T) {
+	err := exception.New("rate limited",
+		exception.WithStatus(exception.StatusResourceExhausted),
+		exception.WithRetryAfter(30*time.Second),
+	)
+	var retryErr interface{ RetryAfter() time.Duration }
+	assert.True(t, errors.As(err, &retryErr))
+	assert.Equal(t, 30*time.Second, retryErr.RetryAfter())
+
+	wrapped := exception.New("quota check failed", exception.Inherit(err))
+	assert.True(t, errors.As(wrapped, &retryErr))
+	assert.Equal(t, 30*time.Second, retryErr.RetryAfter())
+}
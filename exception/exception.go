@@ -1,15 +1,22 @@
 package exception
 
 import (
+	"errors"
 	"net/http"
+	"time"
 )
 
 type exception struct {
-	s       string
-	error   error
-	status  string
-	code    string
-	message string
+	s          string
+	error      error
+	status     Status
+	code       Code
+	message    string
+	data       any
+	helpURL    string
+	op         string
+	details    map[string]any
+	retryAfter time.Duration
 }
 
 func (e *exception) Error() string {
@@ -18,7 +25,7 @@ func (e *exception) Error() string {
 
 // Code returns the error code
 func (e *exception) Code() string {
-	return e.code
+	return e.code.String()
 }
 
 // Message returns the human-readable message
@@ -26,6 +33,41 @@ func (e *exception) Message() string {
 	return e.message
 }
 
+// Data returns the partial data carried by the error, if any
+func (e *exception) Data() any {
+	return e.data
+}
+
+// HelpURL returns the link to the documentation of the error, if any
+func (e *exception) HelpURL() string {
+	return e.helpURL
+}
+
+// Details returns the structured context attached with WithDetail
+func (e *exception) Details() map[string]any {
+	return e.details
+}
+
+// RetryAfter returns how long the caller should wait before retrying, as set
+// with WithRetryAfter, zero if unknown
+func (e *exception) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+// Ops returns the operations recorded with WithOp along the wrap chain,
+// starting from the outermost one
+func (e *exception) Ops() []string {
+	var ops []string
+	var err error = e
+	for err != nil {
+		if ex, ok := err.(*exception); ok && ex.op != "" {
+			ops = append(ops, ex.op)
+		}
+		err = errors.Unwrap(err)
+	}
+	return ops
+}
+
 // Unwrap implements the errors.Unwrap interface
 func (e *exception) Unwrap() error {
 	return e.error
@@ -33,57 +75,98 @@ func (e *exception) Unwrap() error {
 
 // ToHTTPStatus converts an AppError code to an HTTP status code
 func (e *exception) HTTPStatus() int {
-	switch e.status {
-	case CodeInternal:
-		return http.StatusInternalServerError // 500
-	case CodeInvalidRequest:
-		return http.StatusBadRequest // 400
-	case CodeValidationFailed:
-		return http.StatusUnprocessableEntity // 422
-	case CodeNotFound:
-		return http.StatusNotFound // 404
-	case CodeAlreadyExists, CodeRaceCondition:
-		return http.StatusConflict // 409
-	case CodeUnauthenticated:
-		return http.StatusUnauthorized
-	case CodePermissionDenied:
-		return http.StatusForbidden // 403
-	case CodeResourceExhausted:
-		return http.StatusTooManyRequests // 429
-	case CodeUnavailable:
-		return http.StatusServiceUnavailable // 503
-	case CodeDeadlineExceeded:
-		return http.StatusGatewayTimeout // 504
-	case CodeSoftError:
-		return http.StatusOK // 200
+	return HTTPStatusFor(e.status.String())
+}
+
+// builtinHTTPStatus returns the HTTP status code of the statuses defined by this package
+func builtinHTTPStatus(status Status) (int, bool) {
+	switch status {
+	case StatusInternal, "":
+		return http.StatusInternalServerError, true // 500
+	case StatusInvalidRequest:
+		return http.StatusBadRequest, true // 400
+	case StatusValidationFailed:
+		return http.StatusUnprocessableEntity, true // 422
+	case StatusNotFound:
+		return http.StatusNotFound, true // 404
+	case StatusAlreadyExists, StatusRaceCondition:
+		return http.StatusConflict, true // 409
+	case StatusUnauthenticated:
+		return http.StatusUnauthorized, true
+	case StatusPermissionDenied:
+		return http.StatusForbidden, true // 403
+	case StatusResourceExhausted:
+		return http.StatusTooManyRequests, true // 429
+	case StatusThirdParty:
+		return http.StatusBadGateway, true // 502
+	case StatusUnavailable:
+		return http.StatusServiceUnavailable, true // 503
+	case StatusDeadlineExceeded:
+		return http.StatusGatewayTimeout, true // 504
+	case StatusSoftError:
+		return http.StatusOK, true // 200
 	default:
-		return http.StatusInternalServerError
+		return 0, false
+	}
+}
+
+// StatusFromHTTP returns the status matching an HTTP status code.
+// Unknown 4xx codes map to INVALID_REQUEST and other codes to INTERNAL.
+func StatusFromHTTP(code int) Status {
+	switch code {
+	case http.StatusBadRequest:
+		return StatusInvalidRequest
+	case http.StatusUnprocessableEntity:
+		return StatusValidationFailed
+	case http.StatusNotFound:
+		return StatusNotFound
+	case http.StatusConflict:
+		return StatusAlreadyExists
+	case http.StatusUnauthorized:
+		return StatusUnauthenticated
+	case http.StatusForbidden:
+		return StatusPermissionDenied
+	case http.StatusTooManyRequests:
+		return StatusResourceExhausted
+	case http.StatusServiceUnavailable:
+		return StatusUnavailable
+	case http.StatusGatewayTimeout:
+		return StatusDeadlineExceeded
+	case http.StatusOK:
+		return StatusSoftError
+	}
+	if code >= 400 && code < 500 {
+		return StatusInvalidRequest
 	}
+	return StatusInternal
 }
 
 func (e *exception) GRPCStatus() string {
 	switch e.status {
-	case CodeInternal:
+	case StatusInternal:
 		return "INTERNAL"
-	case CodeInvalidRequest, CodeValidationFailed:
+	case StatusInvalidRequest, StatusValidationFailed:
 		return "INVALID_ARGUMENT"
-	case CodeNotFound:
+	case StatusNotFound:
 		return "NOT_FOUND"
-	case CodeAlreadyExists, CodeRaceCondition:
+	case StatusAlreadyExists, StatusRaceCondition:
 		return "ALREADY_EXISTS"
-	case CodeUnauthenticated:
+	case StatusUnauthenticated:
 		return "UNAUTHENTICATED"
-	case CodePermissionDenied:
+	case StatusPermissionDenied:
 		return "PERMISSION_DENIED"
-	case CodeResourceExhausted:
+	case StatusResourceExhausted:
 		return "RESOURCE_EXHAUSTED"
-	case CodeUnavailable:
+	case StatusThirdParty, StatusUnavailable:
 		return "UNAVAILABLE"
-	case CodeDeadlineExceeded:
+	case StatusDeadlineExceeded:
 		return "DEADLINE_EXCEEDED"
-	case CodeSoftError:
+	case StatusSoftError:
 		return "OK"
 	default:
+		if mapping, ok := customStatus(e.status); ok {
+			return mapping.grpcStatus
+		}
 		return "UNKNOWN"
 	}
 }
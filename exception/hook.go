@@ -0,0 +1,42 @@
+package exception
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// createHooks holds the hooks invoked whenever an exception is created. The
+// slice is replaced as a whole and never mutated, so exceptions can be
+// created concurrently with OnCreate.
+var createHooks atomic.Pointer[[]func(err error)]
+
+// createHooksMu serializes OnCreate calls so concurrent registrations are not lost
+var createHooksMu sync.Mutex
+
+// OnCreate registers a hook that is invoked on every New, Wrap, Join and Normalize call.
+// Hooks receive the fully configured exception and run in registration order,
+// which makes them a single place to attach metrics, tracing or logging.
+func OnCreate(hook func(err error)) {
+	if hook == nil {
+		return
+	}
+	createHooksMu.Lock()
+	defer createHooksMu.Unlock()
+
+	var hooks []func(err error)
+	if current := createHooks.Load(); current != nil {
+		hooks = append(hooks, *current...)
+	}
+	hooks = append(hooks, hook)
+	createHooks.Store(&hooks)
+}
+
+func runCreateHooks(err error) {
+	hooks := createHooks.Load()
+	if hooks == nil {
+		return
+	}
+	for _, hook := range *hooks {
+		hook(err)
+	}
+}
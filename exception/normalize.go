@@ -0,0 +1,54 @@
+package exception
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Normalize converts err into an exception so errors from other error
+// packages get the same HTTP and gRPC mapping as exceptions.
+//
+// Exceptions are returned unchanged. Errors exposing a Code, and optionally
+// a Message and an HTTPStatus or HTTPCode method, keep their code and message
+// and get the status matching their HTTP status code. Any other error becomes
+// an INTERNAL exception wrapping it. The original error stays reachable with
+// errors.Is and errors.As.
+//
+// Example usage:
+//
+//	err = exception.Normalize(legacyErr)
+//	httphelper.Error(w, err)
+func Normalize(err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *exception
+	if errors.As(err, &e) {
+		return err
+	}
+
+	opts := []ErrorOption{
+		WithStatus(StatusInternal),
+		WithCode(CodeInternal),
+		WithMessage(""),
+	}
+	var coded ErrorCode
+	if errors.As(err, &coded) {
+		httpStatus := http.StatusInternalServerError
+		switch c := coded.(type) {
+		case interface{ HTTPStatus() int }:
+			httpStatus = c.HTTPStatus()
+		case interface{ HTTPCode() int }:
+			httpStatus = c.HTTPCode()
+		}
+		opts = append(opts, WithStatus(StatusFromHTTP(httpStatus)), WithCode(Code(coded.Code())))
+		if m, ok := coded.(interface{ Message() string }); ok {
+			opts = append(opts, WithMessage(m.Message()))
+		}
+	}
+
+	// the error text is kept as is instead of being prefixed like Wrap does
+	normalized := newException(err.Error(), append(opts, WithError(err)))
+	runCreateHooks(normalized)
+	return normalized
+}
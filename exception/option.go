@@ -1,7 +1,9 @@
 package exception
 
 import (
+	"errors"
 	"fmt"
+	"time"
 )
 
 // ErrorOption is a function that configures an error
@@ -9,7 +11,7 @@ type ErrorOption func(*exception)
 
 // defaultOptions stores the global default options
 var defaultOptions []ErrorOption = []ErrorOption{
-	WithStatus(CodeInternal),
+	WithStatus(StatusInternal),
 	WithCode(CodeInternal),
 	func(e *exception) {
 		e.message = e.s
@@ -30,13 +32,13 @@ func WithError(err error) ErrorOption {
 	}
 }
 
-func WithStatus(status string) ErrorOption {
+func WithStatus(status Status) ErrorOption {
 	return func(e *exception) {
 		e.status = status
 	}
 }
 
-func WithCode(code string) ErrorOption {
+func WithCode(code Code) ErrorOption {
 	return func(e *exception) {
 		e.code = code
 	}
@@ -48,6 +50,67 @@ func WithMessage(message string) ErrorOption {
 	}
 }
 
+// WithData attaches a data payload to the error, typically used by soft errors
+// to return partial results alongside the error
+func WithData(data any) ErrorOption {
+	return func(e *exception) {
+		e.data = data
+	}
+}
+
+// WithHelpURL attaches a link to the troubleshooting or documentation page of the error
+func WithHelpURL(url string) ErrorOption {
+	return func(e *exception) {
+		e.helpURL = url
+	}
+}
+
+// WithOp records the logical operation in which the error occurred, e.g. "orders.Create".
+// Operations accumulate as the error is wrapped and are returned by Ops.
+func WithOp(op string) ErrorOption {
+	return func(e *exception) {
+		e.op = op
+	}
+}
+
+// WithDetail attaches a key-value pair of structured context to the error
+func WithDetail(key string, value any) ErrorOption {
+	return func(e *exception) {
+		if e.details == nil {
+			e.details = make(map[string]any)
+		}
+		e.details[key] = value
+	}
+}
+
+// WithRetryAfter hints how long the caller should wait before retrying, e.g.
+// for RESOURCE_EXHAUSTED or UNAVAILABLE errors
+func WithRetryAfter(d time.Duration) ErrorOption {
+	return func(e *exception) {
+		e.retryAfter = d
+	}
+}
+
+// Inherit copies the status, code, message, help URL, retry hint and details
+// of the first exception in err's chain, so wrapping an error keeps its
+// classification
+func Inherit(err error) ErrorOption {
+	return func(e *exception) {
+		var src *exception
+		if !errors.As(err, &src) {
+			return
+		}
+		e.status = src.status
+		e.code = src.code
+		e.message = src.message
+		e.helpURL = src.helpURL
+		e.retryAfter = src.retryAfter
+		for k, v := range src.details {
+			WithDetail(k, v)(e)
+		}
+	}
+}
+
 func WithArgs(args ...any) ErrorOption {
 	return func(e *exception) {
 		e.s = fmt.Sprintf(e.s, args...)
@@ -56,6 +119,20 @@ func WithArgs(args ...any) ErrorOption {
 
 // New creates a new Exception with required code and message, plus optional configurations
 func New(text string, opts ...ErrorOption) error {
+	e := newException(text, opts)
+
+	if e.error != nil {
+		e.s = fmt.Sprintf("%s: %s", e.s, e.error.Error())
+	}
+
+	runCreateHooks(e)
+
+	return e
+}
+
+// newException creates an exception with the default options and then opts
+// applied, leaving it to the caller to run the create hooks
+func newException(text string, opts []ErrorOption) *exception {
 	e := &exception{
 		s: text,
 	}
@@ -69,10 +146,29 @@ func New(text string, opts ...ErrorOption) error {
 	for _, opt := range opts {
 		opt(e)
 	}
+	return e
+}
 
-	if e.error != nil {
-		e.s = fmt.Sprintf("%s: %s", e.s, e.error.Error())
+// Join combines multiple errors into a single exception, ignoring nil errors.
+// The first exception among errs determines the status, code and message, and
+// every error stays reachable through errors.Is and errors.As.
+// Join returns nil if all errors are nil.
+func Join(errs ...error) error {
+	joined := errors.Join(errs...)
+	if joined == nil {
+		return nil
+	}
+
+	e := &exception{
+		s:     joined.Error(),
+		error: joined,
+	}
+	for _, opt := range defaultOptions {
+		opt(e)
 	}
+	Inherit(joined)(e)
+
+	runCreateHooks(e)
 
 	return e
 }
@@ -0,0 +1,24 @@
+package exception
+
+import "fmt"
+
+// FromPanic converts a value recovered from a panic into an INTERNAL exception.
+// Error values stay reachable with errors.Is and errors.As.
+//
+// Example usage:
+//
+//	defer func() {
+//	    if v := recover(); v != nil {
+//	        err = exception.FromPanic(v)
+//	    }
+//	}()
+func FromPanic(v any) error {
+	err, ok := v.(error)
+	if !ok {
+		err = fmt.Errorf("%v", v)
+	}
+	return Wrap(err, "panic",
+		WithStatus(StatusInternal),
+		WithCode(CodeInternal),
+	)
+}
@@ -0,0 +1,112 @@
+package exception
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem documents
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details document
+type Problem struct {
+	// Type is a URI reference identifying the problem type
+	Type string `json:"type"`
+	// Title is a short human-readable summary of the problem type
+	Title string `json:"title"`
+	// Status is the HTTP status code of the problem
+	Status int `json:"status"`
+	// Detail is a human-readable explanation specific to this occurrence
+	Detail string `json:"detail,omitempty"`
+	// Instance is a URI reference identifying this occurrence
+	Instance string `json:"instance,omitempty"`
+	// Extensions holds additional members serialized alongside the standard ones
+	Extensions map[string]any `json:"-"`
+}
+
+// MarshalJSON flattens the extension members into the problem document
+func (p Problem) MarshalJSON() ([]byte, error) {
+	m := make(map[string]any, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		m[k] = v
+	}
+	m["type"] = p.Type
+	m["title"] = p.Title
+	m["status"] = p.Status
+	if p.Detail != "" {
+		m["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		m["instance"] = p.Instance
+	}
+	return json.Marshal(m)
+}
+
+// httpError is implemented by errors carrying their own HTTP status, code and
+// message without being exceptions, such as the request limit errors of
+// httphelper
+type httpError interface {
+	error
+	HTTPStatus() int
+	Code() string
+	Message() string
+}
+
+// ToProblem converts an error into an RFC 7807 problem document.
+// Exceptions keep their HTTP status and message, use their help URL as the
+// problem type, and expose their details and code as extension members.
+// Other errors keep the status, code and message they expose, if any, and
+// are otherwise reported as internal errors.
+// The fields of ValidationErrors are exposed as the "errors" extension member.
+func ToProblem(err error) Problem {
+	var fields ValidationErrors
+	hasFields := errors.As(err, &fields)
+
+	var e *exception
+	var httpStatus int
+	if !errors.As(err, &e) {
+		e = &exception{
+			s:       err.Error(),
+			status:  StatusInternal,
+			code:    CodeInternal,
+			message: http.StatusText(http.StatusInternalServerError),
+		}
+		var coded httpError
+		switch {
+		case hasFields:
+			e.status = StatusValidationFailed
+			e.code = CodeValidationFailed
+			e.message = fields.Message()
+		case errors.As(err, &coded):
+			e.code = Code(coded.Code())
+			e.message = coded.Message()
+			httpStatus = coded.HTTPStatus()
+		}
+	}
+	if httpStatus == 0 {
+		httpStatus = e.HTTPStatus()
+	}
+
+	extensions := make(map[string]any, len(e.details)+1)
+	for k, v := range e.details {
+		extensions[k] = v
+	}
+	extensions["code"] = e.code.String()
+	if hasFields {
+		extensions["errors"] = []FieldError(fields)
+	}
+
+	problemType := "about:blank"
+	if e.helpURL != "" {
+		problemType = e.helpURL
+	}
+
+	return Problem{
+		Type:       problemType,
+		Title:      e.message,
+		Status:     httpStatus,
+		Detail:     e.Error(),
+		Extensions: extensions,
+	}
+}
@@ -0,0 +1,123 @@
+package exception
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// Entry describes a registered exception in the error catalog
+type Entry struct {
+	// Code is the machine-readable error identifier
+	Code string `json:"code"`
+	// Status is the protocol-agnostic error status
+	Status string `json:"status"`
+	// Message is the human-readable error description
+	Message string `json:"message"`
+	// HTTPStatus is the HTTP status code the exception maps to
+	HTTPStatus int `json:"http_status"`
+	// GRPCStatus is the gRPC status code the exception maps to
+	GRPCStatus string `json:"grpc_status"`
+	// Owner is the team owning the error code
+	Owner string `json:"owner,omitempty"`
+	// Runbook is a link to the runbook for the error code
+	Runbook string `json:"runbook,omitempty"`
+	// Stability tells consumers whether they can rely on the error code
+	Stability Stability `json:"stability"`
+}
+
+// Stability is the stability level of a registered error code
+type Stability string
+
+const (
+	// StabilityStable marks error codes consumers can rely on
+	StabilityStable Stability = "stable"
+	// StabilityExperimental marks error codes that may change or be removed
+	StabilityExperimental Stability = "experimental"
+)
+
+// RegisterOption sets ownership metadata on a catalog entry
+type RegisterOption func(*Entry)
+
+// WithOwner sets the team owning the error code
+func WithOwner(owner string) RegisterOption {
+	return func(e *Entry) {
+		e.Owner = owner
+	}
+}
+
+// WithRunbook sets the runbook link of the error code
+func WithRunbook(url string) RegisterOption {
+	return func(e *Entry) {
+		e.Runbook = url
+	}
+}
+
+// WithStability sets the stability level of the error code, stable by default
+func WithStability(stability Stability) RegisterOption {
+	return func(e *Entry) {
+		e.Stability = stability
+	}
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Entry{}
+)
+
+// Register adds the exception to the error catalog and returns it unchanged,
+// so it can be used directly in package-level error declarations.
+// Errors that are not exceptions are returned without being registered.
+//
+// Example usage:
+//
+//	var ErrPaymentDeclined = exception.Register(exception.New("payment declined",
+//	    exception.WithStatus(exception.StatusInvalidRequest),
+//	    exception.WithCode("PAYMENT_DECLINED"),
+//	), exception.WithOwner("payments"), exception.WithRunbook("https://runbooks.example.com/payments"))
+func Register(err error, opts ...RegisterOption) error {
+	var e *exception
+	if !errors.As(err, &e) {
+		return err
+	}
+
+	entry := Entry{
+		Code:       e.code.String(),
+		Status:     e.status.String(),
+		Message:    e.message,
+		HTTPStatus: e.HTTPStatus(),
+		GRPCStatus: e.GRPCStatus(),
+		Stability:  StabilityStable,
+	}
+	for _, opt := range opts {
+		opt(&entry)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[entry.Code] = entry
+	return err
+}
+
+// Lookup returns the catalog entry registered for code
+func Lookup(code string) (Entry, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	entry, ok := registry[code]
+	return entry, ok
+}
+
+// Catalog returns all registered exceptions sorted by code
+func Catalog() []Entry {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	entries := make([]Entry, 0, len(registry))
+	for _, entry := range registry {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Code < entries[j].Code
+	})
+	return entries
+}
@@ -21,4 +21,13 @@ func AsErrorCode(err error) (ErrorCode, bool) {
 		return errorCode, true
 	}
 	return nil, false
-}
\ No newline at end of file
+}
+
+// HasStatus reports whether the first exception found in err's chain has the given status.
+func HasStatus(err error, status Status) bool {
+	var e *exception
+	if !errors.As(err, &e) {
+		return false
+	}
+	return e.status == status
+}
@@ -0,0 +1,55 @@
+package exception
+
+import (
+	"sync"
+)
+
+// statusMapping is the protocol mapping of a custom status
+type statusMapping struct {
+	httpStatus int
+	grpcStatus string
+}
+
+var (
+	statusMu       sync.RWMutex
+	customStatuses = map[Status]statusMapping{}
+)
+
+// RegisterStatus adds a custom status with its HTTP and gRPC mapping, so
+// statuses defined by other teams do not fall back to the unknown status policy.
+//
+// Example usage:
+//
+//	const StatusPaymentRequired exception.Status = "PAYMENT_REQUIRED"
+//
+//	exception.RegisterStatus(StatusPaymentRequired, http.StatusPaymentRequired, "FAILED_PRECONDITION")
+func RegisterStatus(status Status, httpStatus int, grpcStatus string) {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	customStatuses[status] = statusMapping{httpStatus: httpStatus, grpcStatus: grpcStatus}
+}
+
+// HTTPStatusFor returns the HTTP status code of a status, applying the
+// unknown status policy to statuses that are not defined or registered,
+// see WithUnknownStatusPolicy
+func HTTPStatusFor(status string) int {
+	if code, ok := builtinHTTPStatus(Status(status)); ok {
+		return code
+	}
+	if mapping, ok := customStatus(Status(status)); ok {
+		return mapping.httpStatus
+	}
+
+	cfg := defaultConfig.Load()
+	if cfg.unknownStatusHook != nil {
+		cfg.unknownStatusHook(Status(status))
+	}
+	return cfg.unknownHTTPStatus
+}
+
+func customStatus(status Status) (statusMapping, bool) {
+	statusMu.RLock()
+	defer statusMu.RUnlock()
+	mapping, ok := customStatuses[status]
+	return mapping, ok
+}
@@ -0,0 +1,27 @@
+package exception
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+)
+
+// placeholder matches named placeholders such as {order_id}
+var placeholder = regexp.MustCompile(`\{([A-Za-z0-9_.-]+)\}`)
+
+// RenderMessage replaces the named placeholders of a message template, such as
+// "order {order_id} not found", with the matching details. Values are
+// HTML-escaped so user-controlled details cannot inject markup into clients
+// that render messages. Placeholders without a matching detail are kept as is.
+func RenderMessage(template string, details map[string]any) string {
+	if len(details) == 0 {
+		return template
+	}
+	return placeholder.ReplaceAllStringFunc(template, func(match string) string {
+		value, ok := details[match[1:len(match)-1]]
+		if !ok {
+			return match
+		}
+		return html.EscapeString(fmt.Sprint(value))
+	})
+}
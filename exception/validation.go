@@ -0,0 +1,55 @@
+package exception
+
+import "strings"
+
+// FieldError describes why a single field failed validation
+type FieldError struct {
+	// Field is the name or path of the invalid field, e.g. "items[0].quantity"
+	Field string `json:"field"`
+	// Code is a machine-readable reason, e.g. "required"
+	Code string `json:"code,omitempty"`
+	// Message is a human-readable description of the problem
+	Message string `json:"message"`
+}
+
+// ValidationErrors is a VALIDATION_FAILED error listing every invalid field,
+// rendered by httphelper as a structured array in the error details.
+//
+// Example usage:
+//
+//	var errs exception.ValidationErrors
+//	if req.Name == "" {
+//	    errs = append(errs, exception.FieldError{Field: "name", Code: "required", Message: "name is required"})
+//	}
+//	if len(errs) > 0 {
+//	    return errs
+//	}
+type ValidationErrors []FieldError
+
+func (v ValidationErrors) Error() string {
+	fields := make([]string, len(v))
+	for i, f := range v {
+		fields[i] = f.Field + ": " + f.Message
+	}
+	return "validation failed: " + strings.Join(fields, "; ")
+}
+
+// FieldErrors returns the per-field breakdown of the error
+func (v ValidationErrors) FieldErrors() []FieldError {
+	return v
+}
+
+// Code returns the VALIDATION_FAILED error code
+func (v ValidationErrors) Code() string {
+	return CodeValidationFailed.String()
+}
+
+// Message returns the human-readable message
+func (v ValidationErrors) Message() string {
+	return "validation failed"
+}
+
+// HTTPStatus returns the HTTP status code of VALIDATION_FAILED
+func (v ValidationErrors) HTTPStatus() int {
+	return HTTPStatusFor(StatusValidationFailed.String())
+}
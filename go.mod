@@ -1,6 +1,6 @@
 module github.com/aeramu/apihelper
 
-go 1.21.2
+go 1.22
 
 require (
 	github.com/stretchr/testify v1.10.0
@@ -11,5 +11,5 @@ require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/go-resty/resty/v2 v2.16.3
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
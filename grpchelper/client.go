@@ -0,0 +1,177 @@
+package grpchelper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aeramu/apihelper/exception"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statuses maps gRPC status codes to exception statuses
+var statuses = map[codes.Code]exception.Status{
+	codes.Unknown:            exception.StatusThirdParty,
+	codes.InvalidArgument:    exception.StatusInvalidRequest,
+	codes.DeadlineExceeded:   exception.StatusDeadlineExceeded,
+	codes.NotFound:           exception.StatusNotFound,
+	codes.AlreadyExists:      exception.StatusAlreadyExists,
+	codes.PermissionDenied:   exception.StatusPermissionDenied,
+	codes.ResourceExhausted:  exception.StatusResourceExhausted,
+	codes.FailedPrecondition: exception.StatusInvalidRequest,
+	codes.Aborted:            exception.StatusRaceCondition,
+	codes.OutOfRange:         exception.StatusInvalidRequest,
+	codes.Unimplemented:      exception.StatusThirdParty,
+	codes.Internal:           exception.StatusThirdParty,
+	codes.Unavailable:        exception.StatusUnavailable,
+	codes.DataLoss:           exception.StatusThirdParty,
+	codes.Unauthenticated:    exception.StatusUnauthenticated,
+}
+
+// FromStatus converts a gRPC status received from an upstream into an
+// exception. The exception status follows the gRPC code, with failures of
+// the upstream itself (UNKNOWN, INTERNAL, UNIMPLEMENTED, DATA_LOSS) becoming
+// StatusThirdParty, and a CANCELED status wrapping context.Canceled so it is
+// handled like a locally cancelled call. The exception code is the reason of
+// the errdetails.ErrorInfo attached by ToStatus, or the exception status when
+// there is none, and its metadata become the details.
+// An errdetails.RetryInfo becomes the WithRetryAfter hint, and an
+// errdetails.BadRequest a wrapped exception.ValidationErrors, and the ID of
+// an errdetails.RequestInfo the "request_id" detail.
+//
+// Example usage:
+//
+//	err := grpchelper.FromStatus(status.Convert(err))
+//	if exception.HasStatus(err, exception.StatusNotFound) {
+//	    ...
+//	}
+//
+// Parameters:
+//   - st: The gRPC status to convert
+//
+// Returns:
+//   - The exception, nil for an OK status
+func FromStatus(st *status.Status) error {
+	if st.Code() == codes.OK {
+		return nil
+	}
+	s, ok := statuses[st.Code()]
+	if !ok {
+		s = exception.StatusInternal
+	}
+	opts := []exception.ErrorOption{
+		exception.WithStatus(s),
+		exception.WithCode(exception.Code(s)),
+		exception.WithMessage(st.Message()),
+	}
+	err := st.Err()
+	if st.Code() == codes.Canceled {
+		err = fmt.Errorf("%w: %w", err, context.Canceled)
+	}
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if detail.Reason != "" {
+				opts = append(opts, exception.WithCode(exception.Code(detail.Reason)))
+			}
+			for k, v := range detail.Metadata {
+				opts = append(opts, exception.WithDetail(k, v))
+			}
+		case *errdetails.RetryInfo:
+			opts = append(opts, exception.WithRetryAfter(detail.GetRetryDelay().AsDuration()))
+		case *errdetails.RequestInfo:
+			opts = append(opts, exception.WithDetail("request_id", detail.GetRequestId()))
+		case *errdetails.BadRequest:
+			err = validationErrors(detail)
+			opts = append(opts, exception.WithStatus(exception.StatusValidationFailed))
+		}
+	}
+	return exception.Wrap(err, st.Message(), opts...)
+}
+
+// validationErrors converts the field violations of a bad request back into
+// ValidationErrors
+func validationErrors(req *errdetails.BadRequest) exception.ValidationErrors {
+	fields := make(exception.ValidationErrors, len(req.GetFieldViolations()))
+	for i, v := range req.GetFieldViolations() {
+		fields[i] = exception.FieldError{
+			Field:   v.GetField(),
+			Code:    v.GetReason(),
+			Message: v.GetDescription(),
+		}
+	}
+	return fields
+}
+
+// FromError converts an error returned by a gRPC client into an exception
+// with FromStatus. Errors without a gRPC status, such as io.EOF, are
+// returned as is.
+func FromError(err error) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	return FromStatus(st)
+}
+
+// UnaryClientInterceptor returns a client interceptor converting the errors
+// of unary calls into exceptions with FromError, so callers handle upstream
+// failures with the same error model regardless of the transport.
+//
+// Example usage:
+//
+//	conn, err := grpc.NewClient(target, grpc.WithChainUnaryInterceptor(grpchelper.UnaryClientInterceptor()))
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return FromError(invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
+
+// StreamClientInterceptor returns the streaming counterpart of
+// UnaryClientInterceptor, converting the errors of opening the stream and of
+// sending and receiving messages. io.EOF is returned as is.
+//
+// Example usage:
+//
+//	conn, err := grpc.NewClient(target, grpc.WithChainStreamInterceptor(grpchelper.StreamClientInterceptor()))
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, FromError(err)
+		}
+		return &clientStream{ClientStream: stream}, nil
+	}
+}
+
+// clientStream converts the errors of a client stream into exceptions
+type clientStream struct {
+	grpc.ClientStream
+}
+
+func (s *clientStream) SendMsg(m any) error {
+	return streamError(s.ClientStream.SendMsg(m))
+}
+
+func (s *clientStream) RecvMsg(m any) error {
+	return streamError(s.ClientStream.RecvMsg(m))
+}
+
+func (s *clientStream) CloseSend() error {
+	return streamError(s.ClientStream.CloseSend())
+}
+
+// streamError converts a stream error, keeping io.EOF comparable
+func streamError(err error) error {
+	if err == nil || errors.Is(err, io.EOF) {
+		return err
+	}
+	return FromError(err)
+}
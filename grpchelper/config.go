@@ -0,0 +1,60 @@
+package grpchelper
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Configuration options
+type config struct {
+	domain    string
+	requestID func() string
+}
+
+// Option represents a configuration option for the grpchelper package
+type Option func(*config)
+
+// defaultConfig holds the configuration changed by Configure. It is replaced
+// as a whole and never mutated, so interceptors can read it concurrently
+// with Configure.
+var defaultConfig atomic.Pointer[config]
+
+// configureMu serializes Configure calls so concurrent updates are not lost
+var configureMu sync.Mutex
+
+func init() {
+	defaultConfig.Store(&config{
+		requestID: newRequestID,
+	})
+}
+
+// WithDomain sets the domain reported in the errdetails.ErrorInfo of the
+// emitted statuses, typically the service name, e.g. "orders.example.com"
+func WithDomain(domain string) Option {
+	return func(c *config) {
+		c.domain = domain
+	}
+}
+
+// WithRequestIDGenerator sets the function generating the request ID of
+// calls received without one, a random 32 characters hex string by default
+func WithRequestIDGenerator(generate func() string) Option {
+	return func(c *config) {
+		c.requestID = generate
+	}
+}
+
+// Configure applies the provided options to the default configuration.
+//
+// Parameters:
+//   - opts: A variadic list of Option functions to apply
+func Configure(opts ...Option) {
+	configureMu.Lock()
+	defer configureMu.Unlock()
+
+	cfg := *defaultConfig.Load()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	defaultConfig.Store(&cfg)
+}
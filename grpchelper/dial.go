@@ -0,0 +1,137 @@
+package grpchelper
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/aeramu/apihelper/exception"
+	"google.golang.org/grpc"
+)
+
+// DefaultRetryableCodes are the status codes retried by the retry policies
+// of a service config that do not list their own retryableStatusCodes: the
+// codes of the exception statuses worth retrying, as for clienthelper
+var DefaultRetryableCodes = []string{"UNAVAILABLE", "DEADLINE_EXCEEDED", "RESOURCE_EXHAUSTED"}
+
+type dialOptions struct {
+	serviceConfig func() ([]byte, error)
+	grpcOptions   []grpc.DialOption
+}
+
+// DialOption configures Dial
+type DialOption func(*dialOptions)
+
+// WithServiceConfig sets the default service config of the connection, a
+// standard gRPC service config JSON document declaring, e.g., method-level
+// timeouts and retry policies
+func WithServiceConfig(config string) DialOption {
+	return func(o *dialOptions) {
+		o.serviceConfig = func() ([]byte, error) {
+			return []byte(config), nil
+		}
+	}
+}
+
+// WithServiceConfigFile is like WithServiceConfig but reads the service
+// config from the file at path when dialing
+func WithServiceConfigFile(path string) DialOption {
+	return func(o *dialOptions) {
+		o.serviceConfig = func() ([]byte, error) {
+			return os.ReadFile(path)
+		}
+	}
+}
+
+// WithServiceConfigEnv is like WithServiceConfig but reads the service
+// config from the environment variable name when dialing. An unset or empty
+// variable leaves the connection without a default service config.
+func WithServiceConfigEnv(name string) DialOption {
+	return func(o *dialOptions) {
+		o.serviceConfig = func() ([]byte, error) {
+			return []byte(os.Getenv(name)), nil
+		}
+	}
+}
+
+// WithDialOptions adds gRPC dial options, such as the transport credentials
+func WithDialOptions(opts ...grpc.DialOption) DialOption {
+	return func(o *dialOptions) {
+		o.grpcOptions = append(o.grpcOptions, opts...)
+	}
+}
+
+// Dial creates a client connection to target converting the errors of its
+// calls into exceptions and propagating request IDs, see
+// UnaryClientInterceptor and RequestIDUnaryClientInterceptor. The service
+// config set with WithServiceConfig, WithServiceConfigFile or
+// WithServiceConfigEnv is the default of the connection: gRPC enforces its
+// timeouts and retries failed attempts according to its retry policies
+// before the interceptors convert the final status, and retry policies
+// without retryableStatusCodes retry DefaultRetryableCodes.
+//
+// Example usage:
+//
+//	conn, err := grpchelper.Dial("dns:///orders.internal:443",
+//	    grpchelper.WithServiceConfigEnv("ORDERS_SERVICE_CONFIG"),
+//	    grpchelper.WithDialOptions(grpc.WithTransportCredentials(creds)),
+//	)
+//
+// Parameters:
+//   - target: The target to connect to, see grpc.NewClient
+//   - opts: Options customizing the connection, such as WithServiceConfig
+//
+// Returns:
+//   - The client connection
+//   - An error if the service config is invalid or the connection cannot be created
+func Dial(target string, opts ...DialOption) (*grpc.ClientConn, error) {
+	var o dialOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	grpcOpts := []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(RequestIDUnaryClientInterceptor(), UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(RequestIDStreamClientInterceptor(), StreamClientInterceptor()),
+	}
+	if o.serviceConfig != nil {
+		raw, err := o.serviceConfig()
+		if err != nil {
+			return nil, exception.Wrap(err, "failed to read service config")
+		}
+		if len(raw) > 0 {
+			config, err := withDefaultRetryableCodes(raw)
+			if err != nil {
+				return nil, exception.Wrap(err, "invalid service config")
+			}
+			grpcOpts = append(grpcOpts, grpc.WithDefaultServiceConfig(config))
+		}
+	}
+
+	conn, err := grpc.NewClient(target, append(grpcOpts, o.grpcOptions...)...)
+	if err != nil {
+		return nil, exception.Wrap(err, "failed to create client connection")
+	}
+	return conn, nil
+}
+
+// withDefaultRetryableCodes sets DefaultRetryableCodes on the retry policies
+// of the service config that do not list their retryable codes
+func withDefaultRetryableCodes(raw []byte) (string, error) {
+	var config map[string]any
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return "", err
+	}
+	methods, _ := config["methodConfig"].([]any)
+	for _, method := range methods {
+		method, _ := method.(map[string]any)
+		policy, ok := method["retryPolicy"].(map[string]any)
+		if !ok {
+			continue
+		}
+		if codes, _ := policy["retryableStatusCodes"].([]any); len(codes) == 0 {
+			policy["retryableStatusCodes"] = DefaultRetryableCodes
+		}
+	}
+	b, err := json.Marshal(config)
+	return string(b), err
+}
@@ -0,0 +1,186 @@
+// Package grpchelper converts exceptions into gRPC statuses and back, so
+// gRPC services and clients share the error model of the HTTP helpers.
+//
+// It is a separate module, so services that only use the HTTP helpers do not
+// depend on gRPC nor on the Go version it requires:
+//
+//	go get github.com/aeramu/apihelper/grpchelper
+//
+// Within the repository, a go.work file using both modules builds grpchelper
+// against the local packages instead of the released version:
+//
+//	go work init . ./grpchelper
+package grpchelper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aeramu/apihelper/exception"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// internalMessage is the message of statuses converted from errors whose
+// text must not reach clients
+const internalMessage = "internal server error"
+
+// grpcError is an exception exposing its gRPC status code name
+type grpcError interface {
+	Code() string
+	Message() string
+	Details() map[string]any
+	GRPCStatus() string
+	RetryAfter() time.Duration
+}
+
+// ToStatus converts an error into a gRPC status. Exceptions keep their
+// status mapping, e.g. NOT_FOUND, and message, their code, details and the
+// configured domain are attached as an errdetails.ErrorInfo, and their
+// WithRetryAfter hint as an errdetails.RetryInfo. ValidationErrors become
+// INVALID_ARGUMENT listing the invalid fields in an errdetails.BadRequest,
+// errors wrapping context.Canceled CANCELED even inside an exception, like
+// httphelper reports them as 499, context deadlines DEADLINE_EXCEEDED, errors
+// already carrying a gRPC status are kept as is unless an exception wraps
+// them, and other errors, like
+// internal exceptions without a message, are reported as INTERNAL without
+// exposing their text.
+//
+// Example usage:
+//
+//	st := grpchelper.ToStatus(exception.ErrorNotFound)
+//	// st.Code() == codes.NotFound
+//
+// Parameters:
+//   - err: The error to convert
+//
+// Returns:
+//   - The gRPC status of err, OK for a nil error
+func ToStatus(err error) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+	var fields exception.ValidationErrors
+	if errors.As(err, &fields) {
+		return withDetails(status.New(codes.InvalidArgument, fields.Message()), errorInfo(fields.Code(), nil), badRequest(fields))
+	}
+	if errors.Is(err, context.Canceled) {
+		return status.New(codes.Canceled, err.Error())
+	}
+	var e grpcError
+	if errors.As(err, &e) {
+		code := grpcCode(e.GRPCStatus())
+		message := exception.RenderMessage(e.Message(), e.Details())
+		if message == "" {
+			message = err.Error()
+			if code == codes.Internal || code == codes.Unknown {
+				message = internalMessage
+			}
+		}
+		details := []protoadapt.MessageV1{errorInfo(e.Code(), e.Details())}
+		if d := e.RetryAfter(); d > 0 {
+			details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(d)})
+		}
+		return withDetails(status.New(code, message), details...)
+	}
+	if st, ok := status.FromError(err); ok {
+		return st
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return status.New(codes.DeadlineExceeded, err.Error())
+	}
+	return withDetails(status.New(codes.Internal, internalMessage), errorInfo(exception.CodeInternal.String(), nil))
+}
+
+// UnaryServerInterceptor returns a server interceptor converting the errors
+// returned by unary handlers into gRPC statuses with ToStatus. Soft errors,
+// whose gRPC status is OK, are not errors in gRPC: the handler response is
+// returned without error.
+//
+// Example usage:
+//
+//	server := grpc.NewServer(grpc.ChainUnaryInterceptor(grpchelper.UnaryServerInterceptor()))
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+		return resp, ToStatus(err).Err()
+	}
+}
+
+// grpcCode parses a gRPC status code name, UNKNOWN if invalid
+func grpcCode(name string) codes.Code {
+	var code codes.Code
+	if err := code.UnmarshalJSON([]byte(`"` + name + `"`)); err != nil {
+		return codes.Unknown
+	}
+	return code
+}
+
+// withDetails attaches the error details to st, unless st is OK
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) *status.Status {
+	if st.Code() == codes.OK {
+		return st
+	}
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		return st
+	}
+	return withDetails
+}
+
+// errorInfo describes an exception code and details in the configured domain
+func errorInfo(code string, details map[string]any) *errdetails.ErrorInfo {
+	info := &errdetails.ErrorInfo{Reason: code, Domain: defaultConfig.Load().domain}
+	if len(details) > 0 {
+		info.Metadata = make(map[string]string, len(details))
+		for k, v := range details {
+			info.Metadata[k] = fmt.Sprint(v)
+		}
+	}
+	return info
+}
+
+// badRequest lists the invalid fields of a validation error
+func badRequest(fields exception.ValidationErrors) *errdetails.BadRequest {
+	violations := make([]*errdetails.BadRequest_FieldViolation, len(fields))
+	for i, f := range fields {
+		violations[i] = &errdetails.BadRequest_FieldViolation{
+			Field:       f.Field,
+			Description: f.Message,
+			Reason:      f.Code,
+		}
+	}
+	return &errdetails.BadRequest{FieldViolations: violations}
+}
+
+// StreamServerInterceptor returns a server interceptor converting the errors
+// returned by streaming handlers into gRPC statuses with ToStatus, and
+// recovering their panics into INTERNAL statuses so a failing stream does not
+// crash the server.
+//
+// Example usage:
+//
+//	server := grpc.NewServer(grpc.ChainStreamInterceptor(grpchelper.StreamServerInterceptor()))
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if v := recover(); v != nil {
+				err = ToStatus(exception.FromPanic(v)).Err()
+			}
+		}()
+		if err := handler(srv, ss); err != nil {
+			return ToStatus(err).Err()
+		}
+		return nil
+	}
+}
@@ -0,0 +1,520 @@
+package grpchelper_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aeramu/apihelper/exception"
+	"github.com/aeramu/apihelper/grpchelper"
+	"github.com/aeramu/apihelper/httphelper"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// healthServer fails its RPCs according to the requested service name
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+}
+
+func (healthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if req.Service == "missing" {
+		return nil, exception.ErrorNotFound
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func (healthServer) Watch(req *healthpb.HealthCheckRequest, stream grpc.ServerStreamingServer[healthpb.HealthCheckResponse]) error {
+	if err := stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}); err != nil {
+		return err
+	}
+	switch req.Service {
+	case "panic":
+		panic("boom")
+	case "unavailable":
+		return exception.ErrorUnavailable
+	}
+	return nil
+}
+
+// newHealthClient serves healthServer over an in-memory connection
+func newHealthClient(t *testing.T, serverOpts []grpc.ServerOption, dialOpts ...grpc.DialOption) healthpb.HealthClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(serverOpts...)
+	healthpb.RegisterHealthServer(server, healthServer{})
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	dialOpts = append(dialOpts,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	conn, err := grpc.NewClient("passthrough:///bufnet", dialOpts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+func TestToStatus(t *testing.T) {
+	errOrder := exception.New("order 1 not found",
+		exception.WithStatus(exception.StatusNotFound),
+		exception.WithCode("ORDER_NOT_FOUND"),
+		exception.WithMessage("order not found"),
+		exception.WithDetail("order_id", 1),
+	)
+
+	st := grpchelper.ToStatus(errOrder)
+	assert.Equal(t, codes.NotFound, st.Code())
+	assert.Equal(t, "order not found", st.Message())
+	assert.Len(t, st.Details(), 1)
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	assert.True(t, ok)
+	assert.Equal(t, "ORDER_NOT_FOUND", info.Reason)
+	assert.Equal(t, map[string]string{"order_id": "1"}, info.Metadata)
+
+	st = grpchelper.ToStatus(exception.ValidationErrors{{Field: "name", Message: "name is required"}})
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+
+	st = grpchelper.ToStatus(errors.New("db password is hunter2"))
+	assert.Equal(t, codes.Internal, st.Code())
+	assert.Equal(t, "internal server error", st.Message())
+
+	st = grpchelper.ToStatus(context.DeadlineExceeded)
+	assert.Equal(t, codes.DeadlineExceeded, st.Code())
+
+	st = grpchelper.ToStatus(status.Error(codes.Aborted, "aborted"))
+	assert.Equal(t, codes.Aborted, st.Code())
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := grpchelper.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/orders.v1.Orders/GetOrder"}
+
+	resp, err := interceptor(context.Background(), "req", info, func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+
+	_, err = interceptor(context.Background(), "req", info, func(ctx context.Context, req any) (any, error) {
+		return nil, exception.ErrorPermissionDenied
+	})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, "permission denied", status.Convert(err).Message())
+
+	resp, err = interceptor(context.Background(), "req", info, func(ctx context.Context, req any) (any, error) {
+		return "partial", exception.ErrorSoftError
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "partial", resp)
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	client := newHealthClient(t, []grpc.ServerOption{
+		grpc.ChainStreamInterceptor(grpchelper.StreamServerInterceptor()),
+	})
+	ctx := context.Background()
+
+	tests := map[string]codes.Code{
+		"":            codes.OK,
+		"unavailable": codes.Unavailable,
+		"panic":       codes.Internal,
+	}
+	for service, want := range tests {
+		stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: service})
+		assert.NoError(t, err)
+		_, err = stream.Recv()
+		assert.NoError(t, err)
+		_, err = stream.Recv()
+		if want == codes.OK {
+			assert.Equal(t, io.EOF, err)
+			continue
+		}
+		assert.Equal(t, want, status.Code(err), service)
+	}
+
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "panic"})
+	assert.NoError(t, err)
+	stream.Recv()
+	_, err = stream.Recv()
+	assert.NotContains(t, status.Convert(err).Message(), "boom")
+}
+
+func TestFromStatus(t *testing.T) {
+	assert.NoError(t, grpchelper.FromStatus(status.New(codes.OK, "")))
+
+	err := grpchelper.FromStatus(status.New(codes.NotFound, "order not found"))
+	assert.True(t, exception.HasStatus(err, exception.StatusNotFound))
+	e, ok := httphelper.AsHTTPError(err)
+	assert.True(t, ok)
+	assert.Equal(t, "order not found", e.Message())
+
+	st := grpchelper.ToStatus(exception.New("order not found",
+		exception.WithStatus(exception.StatusNotFound),
+		exception.WithCode("ORDER_NOT_FOUND"),
+		exception.WithMessage("order not found"),
+		exception.WithDetail("order_id", 1),
+	))
+	err = grpchelper.FromStatus(st)
+	e, _ = httphelper.AsHTTPError(err)
+	assert.True(t, exception.HasStatus(err, exception.StatusNotFound))
+	assert.Equal(t, "ORDER_NOT_FOUND", e.Code())
+	var detailsErr interface{ Details() map[string]any }
+	assert.True(t, errors.As(err, &detailsErr))
+	assert.Equal(t, "1", detailsErr.Details()["order_id"])
+
+	for _, code := range []codes.Code{codes.Unknown, codes.Internal, codes.Unimplemented, codes.DataLoss} {
+		err = grpchelper.FromStatus(status.New(code, "upstream failed"))
+		assert.True(t, exception.HasStatus(err, exception.StatusThirdParty), code.String())
+	}
+
+	canceled := grpchelper.FromStatus(status.New(codes.Canceled, "canceled"))
+	assert.ErrorIs(t, canceled, context.Canceled)
+	assert.False(t, exception.HasStatus(canceled, exception.StatusUnavailable))
+	assert.Equal(t, codes.Canceled, grpchelper.ToStatus(canceled).Code())
+	rec := httptest.NewRecorder()
+	httphelper.Error(rec, canceled)
+	assert.Equal(t, 499, rec.Code)
+
+	wrapped := exception.Wrap(err, "upstream failed", exception.WithStatus(exception.StatusUnavailable))
+	assert.Equal(t, codes.Unavailable, grpchelper.ToStatus(wrapped).Code())
+	assert.Equal(t, io.EOF, grpchelper.FromError(io.EOF))
+}
+
+func TestClientInterceptors(t *testing.T) {
+	client := newHealthClient(t, []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(grpchelper.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(grpchelper.StreamServerInterceptor()),
+	},
+		grpc.WithChainUnaryInterceptor(grpchelper.UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(grpchelper.StreamClientInterceptor()),
+	)
+	ctx := context.Background()
+
+	_, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "missing"})
+	assert.True(t, exception.HasStatus(err, exception.StatusNotFound))
+
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "unavailable"})
+	assert.NoError(t, err)
+	_, err = stream.Recv()
+	assert.NoError(t, err)
+	_, err = stream.Recv()
+	assert.True(t, exception.HasStatus(err, exception.StatusUnavailable))
+
+	stream, err = client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+	stream.Recv()
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestErrorDetails(t *testing.T) {
+	grpchelper.Configure(grpchelper.WithDomain("orders.example.com"))
+	defer grpchelper.Configure(grpchelper.WithDomain(""))
+
+	st := grpchelper.ToStatus(exception.New("rate limited",
+		exception.WithStatus(exception.StatusResourceExhausted),
+		exception.WithRetryAfter(30*time.Second),
+	))
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+	assert.Len(t, st.Details(), 2)
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	assert.True(t, ok)
+	assert.Equal(t, "orders.example.com", info.Domain)
+	retry, ok := st.Details()[1].(*errdetails.RetryInfo)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, retry.RetryDelay.AsDuration())
+
+	err := grpchelper.FromStatus(st)
+	var retryErr interface{ RetryAfter() time.Duration }
+	assert.True(t, errors.As(err, &retryErr))
+	assert.Equal(t, 30*time.Second, retryErr.RetryAfter())
+
+	fields := exception.ValidationErrors{{Field: "name", Code: "required", Message: "name is required"}}
+	st = grpchelper.ToStatus(fields)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Len(t, st.Details(), 2)
+	req, ok := st.Details()[1].(*errdetails.BadRequest)
+	assert.True(t, ok)
+	assert.Equal(t, "name", req.FieldViolations[0].Field)
+	assert.Equal(t, "required", req.FieldViolations[0].Reason)
+
+	err = grpchelper.FromStatus(st)
+	assert.True(t, exception.HasStatus(err, exception.StatusValidationFailed))
+	var got exception.ValidationErrors
+	assert.True(t, errors.As(err, &got))
+	assert.Equal(t, fields, got)
+	assert.Equal(t, codes.InvalidArgument, grpchelper.ToStatus(err).Code())
+}
+
+func TestRequestID(t *testing.T) {
+	var got string
+	capture := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		got = grpchelper.RequestIDFromContext(ctx)
+		return handler(ctx, req)
+	}
+	client := newHealthClient(t, []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(grpchelper.RequestIDUnaryServerInterceptor(), capture, grpchelper.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(grpchelper.RequestIDStreamServerInterceptor(), grpchelper.StreamServerInterceptor()),
+	},
+		grpc.WithChainUnaryInterceptor(grpchelper.UnaryClientInterceptor(), grpchelper.RequestIDUnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(grpchelper.StreamClientInterceptor(), grpchelper.RequestIDStreamClientInterceptor()),
+	)
+
+	var header metadata.MD
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}, grpc.Header(&header))
+	assert.NoError(t, err)
+	assert.Len(t, got, 32)
+	assert.Equal(t, []string{got}, header.Get(grpchelper.RequestIDKey))
+
+	ctx := grpchelper.WithRequestID(context.Background(), "req-1")
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{Service: "missing"})
+	assert.Equal(t, "req-1", got)
+	var detailsErr interface{ Details() map[string]any }
+	assert.True(t, errors.As(err, &detailsErr))
+	assert.Equal(t, "req-1", detailsErr.Details()["request_id"])
+
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "unavailable"})
+	assert.NoError(t, err)
+	stream.Recv()
+	_, err = stream.Recv()
+	assert.True(t, exception.HasStatus(err, exception.StatusUnavailable))
+	assert.True(t, errors.As(err, &detailsErr))
+	assert.Equal(t, "req-1", detailsErr.Details()["request_id"])
+}
+
+func TestConfigure_Concurrent(t *testing.T) {
+	defer grpchelper.Configure(grpchelper.WithDomain(""))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			grpchelper.Configure(grpchelper.WithDomain("orders.example.com"))
+		}()
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, codes.NotFound, grpchelper.ToStatus(exception.ErrorNotFound).Code())
+		}()
+	}
+	wg.Wait()
+}
+
+// webCall sends a gRPC-Web health check for service to handler and returns
+// the decoded response message, if any, and the trailers
+func webCall(t *testing.T, handler http.Handler, contentType, service string) (*healthpb.HealthCheckResponse, http.Header) {
+	t.Helper()
+	msg, err := proto.Marshal(&healthpb.HealthCheckRequest{Service: service})
+	assert.NoError(t, err)
+	body := append([]byte{0, 0, 0, 0, 0}, msg...)
+	binary.BigEndian.PutUint32(body[1:5], uint32(len(msg)))
+	text := contentType == grpchelper.ContentTypeGRPCWebText
+	if text {
+		body = []byte(base64.StdEncoding.EncodeToString(body))
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/grpc.health.v1.Health/Check", bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, contentType, rec.Header().Get("Content-Type"))
+
+	resp := rec.Body.Bytes()
+	if text {
+		var decoded []byte
+		// every write is encoded on its own, so the body is a sequence of padded chunks
+		for len(resp) > 0 {
+			end := bytes.Index(resp, []byte("=")) + 1
+			for end > 0 && end < len(resp) && resp[end] == '=' {
+				end++
+			}
+			if end <= 0 {
+				end = len(resp)
+			}
+			chunk, err := base64.StdEncoding.DecodeString(string(resp[:end]))
+			assert.NoError(t, err)
+			decoded, resp = append(decoded, chunk...), resp[end:]
+		}
+		resp = decoded
+	}
+
+	var reply *healthpb.HealthCheckResponse
+	trailers := make(http.Header)
+	for len(resp) >= 5 {
+		flag, size := resp[0], binary.BigEndian.Uint32(resp[1:5])
+		frame := resp[5 : 5+size]
+		resp = resp[5+size:]
+		if flag&0x80 == 0 {
+			reply = &healthpb.HealthCheckResponse{}
+			assert.NoError(t, proto.Unmarshal(frame, reply))
+			continue
+		}
+		for _, line := range strings.Split(strings.TrimSpace(string(frame)), "\r\n") {
+			key, value, _ := strings.Cut(line, ": ")
+			trailers.Add(key, value)
+		}
+	}
+	return reply, trailers
+}
+
+func TestWebHandler(t *testing.T) {
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(grpchelper.UnaryServerInterceptor()))
+	healthpb.RegisterHealthServer(server, healthServer{})
+	handler := grpchelper.WebHandler(server, grpchelper.WithWebCORS(httphelper.CORS{
+		AllowedOrigins: []string{"https://app.example.com"},
+	}))
+
+	for _, contentType := range []string{grpchelper.ContentTypeGRPCWeb, grpchelper.ContentTypeGRPCWebText} {
+		t.Run(contentType, func(t *testing.T) {
+			reply, trailers := webCall(t, handler, contentType, "")
+			assert.Equal(t, healthpb.HealthCheckResponse_SERVING, reply.GetStatus())
+			assert.Equal(t, "0", trailers.Get("grpc-status"))
+
+			reply, trailers = webCall(t, handler, contentType, "missing")
+			assert.Nil(t, reply)
+			want := grpchelper.ToStatus(exception.ErrorNotFound)
+			assert.Equal(t, strconv.Itoa(int(want.Code())), trailers.Get("grpc-status"))
+			assert.Equal(t, want.Message(), trailers.Get("grpc-message"))
+			assert.NotEmpty(t, trailers.Get("grpc-status-details-bin"))
+		})
+	}
+
+	t.Run("preflight", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodOptions, "/grpc.health.v1.Health/Check", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		req.Header.Set("Access-Control-Request-Headers", "content-type,x-grpc-web")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, http.MethodPost, rec.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "content-type,x-grpc-web", rec.Header().Get("Access-Control-Allow-Headers"))
+	})
+
+	t.Run("exposed headers", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/grpc.health.v1.Health/Check", bytes.NewReader([]byte{0, 0, 0, 0, 0}))
+		req.Header.Set("Origin", "https://app.example.com")
+		req.Header.Set("Content-Type", grpchelper.ContentTypeGRPCWeb)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Contains(t, rec.Header().Get("Access-Control-Expose-Headers"), "Grpc-Status")
+	})
+}
+
+// flakyHealthServer fails the first failures checks with UNAVAILABLE
+type flakyHealthServer struct {
+	healthpb.UnimplementedHealthServer
+	failures int32
+	calls    atomic.Int32
+}
+
+func (s *flakyHealthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if s.calls.Add(1) <= s.failures {
+		return nil, exception.ErrorUnavailable
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func TestDial_ServiceConfig(t *testing.T) {
+	const config = `{"methodConfig": [{
+		"name": [{"service": "grpc.health.v1.Health"}],
+		"timeout": "5s",
+		"retryPolicy": {"maxAttempts": 3, "initialBackoff": "0.001s", "maxBackoff": "0.001s", "backoffMultiplier": 1}
+	}]}`
+
+	dial := func(t *testing.T, failures int32, opts ...grpchelper.DialOption) (healthpb.HealthClient, *flakyHealthServer) {
+		t.Helper()
+		lis := bufconn.Listen(1 << 20)
+		health := &flakyHealthServer{failures: failures}
+		server := grpc.NewServer(grpc.ChainUnaryInterceptor(grpchelper.UnaryServerInterceptor()))
+		healthpb.RegisterHealthServer(server, health)
+		go server.Serve(lis)
+		t.Cleanup(server.Stop)
+
+		conn, err := grpchelper.Dial("passthrough:///bufnet", append(opts, grpchelper.WithDialOptions(
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return healthpb.NewHealthClient(conn), health
+	}
+
+	t.Run("retries with the default codes", func(t *testing.T) {
+		client, health := dial(t, 2, grpchelper.WithServiceConfig(config))
+		_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, int32(3), health.calls.Load())
+	})
+
+	t.Run("exhausted retries return an exception", func(t *testing.T) {
+		client, health := dial(t, 5, grpchelper.WithServiceConfig(config))
+		_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		assert.True(t, exception.HasStatus(err, exception.StatusUnavailable))
+		assert.Equal(t, int32(3), health.calls.Load())
+	})
+
+	t.Run("file and environment", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "service_config.json")
+		assert.NoError(t, os.WriteFile(path, []byte(config), 0o600))
+		client, _ := dial(t, 1, grpchelper.WithServiceConfigFile(path))
+		_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		assert.NoError(t, err)
+
+		t.Setenv("HEALTH_SERVICE_CONFIG", config)
+		client, _ = dial(t, 1, grpchelper.WithServiceConfigEnv("HEALTH_SERVICE_CONFIG"))
+		_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("without a config", func(t *testing.T) {
+		client, health := dial(t, 1, grpchelper.WithServiceConfigEnv("UNSET_SERVICE_CONFIG"))
+		_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		assert.True(t, exception.HasStatus(err, exception.StatusUnavailable))
+		assert.Equal(t, int32(1), health.calls.Load())
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		_, err := grpchelper.Dial("passthrough:///bufnet", grpchelper.WithServiceConfig("{"),
+			grpchelper.WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())))
+		assert.Error(t, err)
+		_, err = grpchelper.Dial("passthrough:///bufnet", grpchelper.WithServiceConfigFile("missing.json"),
+			grpchelper.WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())))
+		assert.Error(t, err)
+	})
+}
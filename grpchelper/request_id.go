@@ -0,0 +1,171 @@
+package grpchelper
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RequestIDKey is the metadata key carrying the request ID, the gRPC
+// counterpart of the X-Request-Id HTTP header
+const RequestIDKey = "x-request-id"
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request ID, propagated to
+// upstreams by the request ID client interceptors.
+//
+// Example usage:
+//
+//	ctx := grpchelper.WithRequestID(r.Context(), r.Header.Get("X-Request-Id"))
+//	resp, err := orders.GetOrder(ctx, req)
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, empty if none.
+//
+// Example usage:
+//
+//	logger.Info("order created", "request_id", grpchelper.RequestIDFromContext(ctx))
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestIDUnaryServerInterceptor returns a server interceptor reading the
+// request ID from the incoming metadata, or generating one when missing,
+// storing it in the handler context and echoing it in the response header.
+// Handler errors are converted with ToStatus and carry the request ID as an
+// errdetails.RequestInfo.
+//
+// Example usage:
+//
+//	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+//	    grpchelper.RequestIDUnaryServerInterceptor(),
+//	    grpchelper.UnaryServerInterceptor(),
+//	))
+func RequestIDUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, id := incomingRequestID(ctx)
+		grpc.SetHeader(ctx, metadata.Pairs(RequestIDKey, id))
+		resp, err := handler(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+		return resp, withRequestInfo(ToStatus(err), id).Err()
+	}
+}
+
+// RequestIDStreamServerInterceptor is the streaming counterpart of
+// RequestIDUnaryServerInterceptor.
+//
+// Example usage:
+//
+//	server := grpc.NewServer(grpc.ChainStreamInterceptor(
+//	    grpchelper.RequestIDStreamServerInterceptor(),
+//	    grpchelper.StreamServerInterceptor(),
+//	))
+func RequestIDStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, id := incomingRequestID(ss.Context())
+		ss.SetHeader(metadata.Pairs(RequestIDKey, id))
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		if err == nil {
+			return nil
+		}
+		return withRequestInfo(ToStatus(err), id).Err()
+	}
+}
+
+// RequestIDUnaryClientInterceptor returns a client interceptor propagating
+// the request ID carried by the context to upstreams through the outgoing
+// metadata, unless the metadata already sets one.
+//
+// Example usage:
+//
+//	conn, err := grpc.NewClient(target, grpc.WithChainUnaryInterceptor(grpchelper.RequestIDUnaryClientInterceptor()))
+func RequestIDUnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoingRequestID(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// RequestIDStreamClientInterceptor is the streaming counterpart of
+// RequestIDUnaryClientInterceptor.
+//
+// Example usage:
+//
+//	conn, err := grpc.NewClient(target, grpc.WithChainStreamInterceptor(grpchelper.RequestIDStreamClientInterceptor()))
+func RequestIDStreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoingRequestID(ctx), desc, cc, method, opts...)
+	}
+}
+
+// serverStream overrides the context of a server stream
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// incomingRequestID stores the request ID of the incoming metadata in ctx,
+// generating one if missing
+func incomingRequestID(ctx context.Context) (context.Context, string) {
+	var id string
+	if ids := metadata.ValueFromIncomingContext(ctx, RequestIDKey); len(ids) > 0 {
+		id = ids[0]
+	}
+	if id == "" {
+		id = defaultConfig.Load().requestID()
+	}
+	return WithRequestID(ctx, id), id
+}
+
+// outgoingRequestID adds the request ID of ctx to the outgoing metadata
+func outgoingRequestID(ctx context.Context) context.Context {
+	id := RequestIDFromContext(ctx)
+	if id == "" {
+		return ctx
+	}
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(RequestIDKey)) > 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, RequestIDKey, id)
+}
+
+// withRequestInfo attaches the request ID to st, unless it already carries one
+func withRequestInfo(st *status.Status, id string) *status.Status {
+	for _, detail := range st.Details() {
+		if _, ok := detail.(*errdetails.RequestInfo); ok {
+			return st
+		}
+	}
+	return withDetails(st, &errdetails.RequestInfo{RequestId: id})
+}
+
+// fallbackSequence numbers the request IDs generated without randomness
+var fallbackSequence atomic.Uint64
+
+// newRequestID returns a random request ID. Should the random source fail,
+// it falls back to an ID that is still unique within the process rather
+// than an empty or all-zero one shared by every request.
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16) + "-" + strconv.FormatUint(fallbackSequence.Add(1), 16)
+	}
+	return hex.EncodeToString(b)
+}
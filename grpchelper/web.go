@@ -0,0 +1,206 @@
+package grpchelper
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/aeramu/apihelper/httphelper"
+	"google.golang.org/grpc"
+)
+
+const (
+	// ContentTypeGRPCWeb is the content type of binary gRPC-Web requests
+	ContentTypeGRPCWeb = "application/grpc-web"
+	// ContentTypeGRPCWebText is the content type of base64 encoded gRPC-Web requests
+	ContentTypeGRPCWebText = "application/grpc-web-text"
+)
+
+// webTrailerFlag marks the frame carrying the trailers of a gRPC-Web response
+const webTrailerFlag = 0x80
+
+// webHeaders are the response headers browsers must be allowed to read for
+// gRPC-Web clients to get the status of trailers-only responses
+var webHeaders = []string{"Grpc-Status", "Grpc-Message", "Grpc-Status-Details-Bin"}
+
+type webOptions struct {
+	cors *httphelper.CORS
+}
+
+// WebOption configures the handler returned by WebHandler
+type WebOption func(*webOptions)
+
+// WithWebCORS applies cors to the requests of WebHandler, so browser clients
+// served from other origins can call the services. The gRPC status headers
+// are always exposed, and the allowed methods default to POST.
+func WithWebCORS(cors httphelper.CORS) WebOption {
+	return func(o *webOptions) {
+		o.cors = &cors
+	}
+}
+
+// WebHandler serves the gRPC-Web requests of browser clients with server,
+// translating them into gRPC requests and the final status of every call,
+// including the exceptions converted by the server interceptors, into the
+// trailer frame of the response, so browser and native clients get the same
+// statuses. Both the binary and the base64 text formats are supported.
+// Other requests, such as HTTP/2 gRPC requests, are passed to server as is.
+//
+// Example usage:
+//
+//	server := grpc.NewServer(grpc.ChainUnaryInterceptor(grpchelper.UnaryServerInterceptor()))
+//	orderspb.RegisterOrdersServer(server, orders)
+//	http.ListenAndServe(":8080", grpchelper.WebHandler(server, grpchelper.WithWebCORS(httphelper.CORS{
+//	    AllowedOrigins: []string{"https://app.example.com"},
+//	})))
+//
+// Parameters:
+//   - server: The gRPC server handling the calls
+//   - opts: Options customizing the handler, such as WithWebCORS
+//
+// Returns:
+//   - The HTTP handler
+func WebHandler(server *grpc.Server, opts ...WebOption) http.Handler {
+	var o webOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType := r.Header.Get("Content-Type")
+		if r.Method != http.MethodPost || !strings.HasPrefix(contentType, ContentTypeGRPCWeb) {
+			server.ServeHTTP(w, r)
+			return
+		}
+		serveWeb(server, w, r, contentType)
+	})
+	if o.cors != nil {
+		cors := *o.cors
+		if len(cors.AllowedMethods) == 0 {
+			cors.AllowedMethods = []string{http.MethodPost}
+		}
+		cors.ExposedHeaders = append(append([]string(nil), cors.ExposedHeaders...), webHeaders...)
+		handler = cors.Middleware(handler)
+	}
+	return handler
+}
+
+// serveWeb serves a gRPC-Web request as a gRPC request, then writes the
+// trailers set by server as the last frame of the response
+func serveWeb(server *grpc.Server, w http.ResponseWriter, r *http.Request, contentType string) {
+	text := strings.HasPrefix(contentType, ContentTypeGRPCWebText)
+	subtype := strings.TrimPrefix(strings.TrimPrefix(contentType, ContentTypeGRPCWebText), ContentTypeGRPCWeb)
+
+	req := r.Clone(r.Context())
+	req.ProtoMajor, req.ProtoMinor, req.Proto = 2, 0, "HTTP/2"
+	req.Header.Set("Content-Type", "application/grpc"+subtype)
+	req.Header.Del("Content-Length")
+	req.ContentLength = -1
+	if text {
+		req.Body = struct {
+			io.Reader
+			io.Closer
+		}{base64.NewDecoder(base64.StdEncoding, r.Body), r.Body}
+	}
+
+	ww := &webResponseWriter{
+		w:           w,
+		header:      make(http.Header),
+		contentType: contentType,
+		text:        text,
+	}
+	server.ServeHTTP(ww, req)
+	ww.writeTrailers()
+}
+
+// webResponseWriter translates the gRPC response written by the server into
+// a gRPC-Web response: headers are written as is, body frames are base64
+// encoded for text requests, and trailers are kept for writeTrailers
+type webResponseWriter struct {
+	w           http.ResponseWriter
+	header      http.Header
+	contentType string
+	text        bool
+	wroteHeader bool
+}
+
+func (w *webResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *webResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	header := w.w.Header()
+	for key, values := range w.header {
+		if key == "Trailer" || strings.HasPrefix(key, http.TrailerPrefix) {
+			continue
+		}
+		header[key] = values
+	}
+	header.Set("Content-Type", w.contentType)
+	header.Del("Content-Length")
+	w.w.WriteHeader(status)
+}
+
+func (w *webResponseWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	if !w.text {
+		return w.w.Write(b)
+	}
+	if _, err := io.WriteString(w.w, base64.StdEncoding.EncodeToString(b)); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (w *webResponseWriter) Flush() {
+	w.WriteHeader(http.StatusOK)
+	if f, ok := w.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter
+func (w *webResponseWriter) Unwrap() http.ResponseWriter {
+	return w.w
+}
+
+// writeTrailers writes the trailers declared or set by the server, such as
+// Grpc-Status, as a trailer frame of lower-cased "name: value" lines
+func (w *webResponseWriter) writeTrailers() {
+	trailers := make(map[string][]string)
+	for _, key := range w.header.Values("Trailer") {
+		if values := w.header.Values(key); len(values) > 0 {
+			trailers[strings.ToLower(key)] = values
+		}
+	}
+	for key, values := range w.header {
+		if name, ok := strings.CutPrefix(key, http.TrailerPrefix); ok {
+			trailers[strings.ToLower(name)] = values
+		}
+	}
+	keys := make([]string, 0, len(trailers))
+	for key := range trailers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var block bytes.Buffer
+	for _, key := range keys {
+		for _, value := range trailers[key] {
+			block.WriteString(key + ": " + value + "\r\n")
+		}
+	}
+	frame := make([]byte, 5, 5+block.Len())
+	frame[0] = webTrailerFlag
+	binary.BigEndian.PutUint32(frame[1:], uint32(block.Len()))
+	w.Write(append(frame, block.Bytes()...))
+	w.Flush()
+}
@@ -0,0 +1,277 @@
+package httphelper
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aeramu/apihelper/exception"
+)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	// Subject identifies the caller, e.g. a user or service ID
+	Subject string
+	// Roles lists the roles granted to the caller
+	Roles []string
+	// Claims holds any additional attributes provided by the verifier
+	Claims map[string]any
+}
+
+// HasRole reports whether the principal has been granted role
+func (p Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Verifier authenticates a request, returning its principal.
+// Returning an HTTPError such as exception.ErrorPermissionDenied writes that
+// error as is; any other error is reported as UNAUTHENTICATED.
+type Verifier func(r *http.Request) (Principal, error)
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the principal stored by Auth, if any
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// Auth returns a middleware authenticating every request with verifier and
+// storing the principal in the request context, see PrincipalFromContext.
+// Failed requests are rejected with an UNAUTHENTICATED envelope, or with the
+// HTTPError returned by the verifier, e.g. PERMISSION_DENIED.
+//
+// Example usage:
+//
+//	auth := httphelper.Auth(httphelper.JWTVerifier(secret))
+//	http.ListenAndServe(":8080", auth(mux))
+//
+//	func getProfile(w http.ResponseWriter, r *http.Request) {
+//	    principal, _ := httphelper.PrincipalFromContext(r.Context())
+//	    ...
+//	}
+func Auth(verifier Verifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, err := verifier(r)
+			if err != nil {
+				if _, ok := AsHTTPError(err); !ok {
+					err = exception.Wrap(err, "authentication failed",
+						exception.WithStatus(exception.StatusUnauthenticated),
+						exception.WithCode(exception.CodeUnauthenticated),
+						exception.WithMessage("unauthenticated"),
+					)
+				}
+				if exception.HasStatus(err, exception.StatusUnauthenticated) {
+					w.Header().Set("WWW-Authenticate", "Bearer")
+				}
+				writeError(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// APIKeyVerifier returns a Verifier looking up the value of the given header
+// in keys, mapping every valid API key to its principal.
+//
+// Example usage:
+//
+//	verifier := httphelper.APIKeyVerifier("X-API-Key", map[string]httphelper.Principal{
+//	    os.Getenv("BILLING_API_KEY"): {Subject: "billing"},
+//	})
+func APIKeyVerifier(header string, keys map[string]Principal) Verifier {
+	return func(r *http.Request) (Principal, error) {
+		key := r.Header.Get(header)
+		if key == "" {
+			return Principal{}, errors.New("missing api key")
+		}
+		var (
+			principal Principal
+			found     bool
+		)
+		for k, p := range keys {
+			if subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 {
+				principal, found = p, true
+			}
+		}
+		if !found {
+			return Principal{}, errors.New("invalid api key")
+		}
+		return principal, nil
+	}
+}
+
+// jwtOptions configures JWTVerifier
+type jwtOptions struct {
+	audience string
+	issuer   string
+}
+
+// JWTOption represents an option for JWTVerifier
+type JWTOption func(*jwtOptions)
+
+// WithAudience requires the aud claim of tokens to contain audience
+func WithAudience(audience string) JWTOption {
+	return func(o *jwtOptions) {
+		o.audience = audience
+	}
+}
+
+// WithIssuer requires the iss claim of tokens to equal issuer
+func WithIssuer(issuer string) JWTOption {
+	return func(o *jwtOptions) {
+		o.issuer = issuer
+	}
+}
+
+// JWTVerifier returns a Verifier accepting HS256 signed JWT bearer tokens.
+// Tokens must carry a numeric exp claim, and the exp and nbf claims are
+// enforced, as well as the aud and iss claims when WithAudience and
+// WithIssuer are given. The sub claim becomes the principal subject, the
+// roles claim its roles, and all claims are kept in Claims. JWTVerifier
+// panics if secret is empty, since anyone could then sign tokens.
+//
+// Example usage:
+//
+//	auth := httphelper.Auth(httphelper.JWTVerifier([]byte(os.Getenv("JWT_SECRET")),
+//	    httphelper.WithIssuer("https://auth.example.com"),
+//	    httphelper.WithAudience("orders"),
+//	))
+func JWTVerifier(secret []byte, opts ...JWTOption) Verifier {
+	if len(secret) == 0 {
+		panic("httphelper: JWTVerifier requires a non-empty secret")
+	}
+	var o jwtOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return func(r *http.Request) (Principal, error) {
+		scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !strings.EqualFold(scheme, "Bearer") || token == "" {
+			return Principal{}, errors.New("missing bearer token")
+		}
+		claims, err := verifyJWT(token, secret, o)
+		if err != nil {
+			return Principal{}, err
+		}
+
+		principal := Principal{Claims: claims}
+		principal.Subject, _ = claims["sub"].(string)
+		if roles, ok := claims["roles"].([]any); ok {
+			for _, role := range roles {
+				if s, ok := role.(string); ok {
+					principal.Roles = append(principal.Roles, s)
+				}
+			}
+		}
+		return principal, nil
+	}
+}
+
+// verifyJWT checks the signature, validity window, audience and issuer of an
+// HS256 token, returning its claims
+func verifyJWT(token string, secret []byte, o jwtOptions) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if header.Alg != "HS256" {
+		return nil, errors.New("unsupported token algorithm")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("malformed token signature")
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, errors.New("invalid token signature")
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	now := float64(time.Now().Unix())
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return nil, errors.New("token has no valid expiration")
+	}
+	if now >= exp {
+		return nil, errors.New("token expired")
+	}
+	if v, found := claims["nbf"]; found {
+		nbf, ok := v.(float64)
+		if !ok {
+			return nil, errors.New("malformed token not before")
+		}
+		if now < nbf {
+			return nil, errors.New("token not yet valid")
+		}
+	}
+	if o.issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != o.issuer {
+			return nil, errors.New("invalid token issuer")
+		}
+	}
+	if o.audience != "" && !hasAudience(claims["aud"], o.audience) {
+		return nil, errors.New("invalid token audience")
+	}
+	return claims, nil
+}
+
+// hasAudience reports whether the aud claim, a string or an array of
+// strings, contains audience
+func hasAudience(aud any, audience string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == audience
+	case []any:
+		for _, a := range aud {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// decodeSegment decodes a base64url encoded JSON token segment into v
+func decodeSegment(segment string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return errors.New("malformed token")
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return errors.New("malformed token")
+	}
+	return nil
+}
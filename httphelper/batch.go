@@ -0,0 +1,166 @@
+package httphelper
+
+import (
+	"net/http"
+
+	"github.com/aeramu/apihelper/exception"
+)
+
+// BATCH_FAILED is the error code of a batch whose items all failed with the
+// same status but different codes
+const BATCH_FAILED = "BATCH_FAILED"
+
+// BatchItem is the outcome of a single item of a batch operation
+type BatchItem struct {
+	// ID identifies the item within the batch
+	ID string `json:"id"`
+	// Status is the HTTP status code of the item outcome
+	Status int `json:"status"`
+	// Success indicates whether the item was processed successfully
+	Success bool `json:"success"`
+	// Data contains the item payload for successful items
+	Data any `json:"data,omitempty"`
+	// ErrorInfo contains error details for failed items
+	ErrorInfo *ErrorInfo `json:"error,omitempty"`
+}
+
+// BatchData is the data of a batch response
+type BatchData struct {
+	Items []BatchItem `json:"items"`
+}
+
+// BatchSuccess creates a successful batch item
+func BatchSuccess(id string, data any) BatchItem {
+	return BatchItem{
+		ID:      id,
+		Status:  http.StatusOK,
+		Success: true,
+		Data:    data,
+	}
+}
+
+// BatchFailure creates a failed batch item from err, using the package configuration
+func BatchFailure(id string, err error) BatchItem {
+	return std().BatchFailure(id, err)
+}
+
+// BatchFailure creates a failed batch item from err
+func (h *Helper) BatchFailure(id string, err error) BatchItem {
+	errInfo, status := h.errorInfo(err)
+	return BatchItem{
+		ID:        id,
+		Status:    status,
+		Success:   false,
+		ErrorInfo: &errInfo,
+	}
+}
+
+// Batch writes the outcomes of a batch operation. The response is a 200 OK
+// unless multi-status is enabled with WithMultiStatus. Then items with mixed
+// outcomes are a 207 Multi-Status, and items that all failed with the same
+// status are an error response with that status, whose error is the shared
+// code of the items, or BATCH_FAILED, and lists the items in its "items"
+// detail for PartitionBatch.
+//
+// Parameters:
+//   - w: The HTTP response writer
+//   - items: The outcome of every item of the batch
+func Batch(w http.ResponseWriter, items []BatchItem) {
+	std().Batch(w, items)
+}
+
+// Batch writes the outcomes of a batch operation
+func (h *Helper) Batch(w http.ResponseWriter, items []BatchItem) {
+	status := http.StatusOK
+	if h.cfg.multiStatus && mixedOutcomes(items) {
+		status = http.StatusMultiStatus
+	} else if h.cfg.multiStatus && len(items) > 0 && items[0].Status >= http.StatusBadRequest {
+		h.writeResponse(w, Response{
+			Status:    items[0].Status,
+			Success:   false,
+			ErrorInfo: batchError(items),
+		}, nil)
+		return
+	}
+	h.writeSuccess(w, status, BatchData{Items: items}, nil)
+}
+
+// batchError describes items that all failed with the same status, keeping
+// the items in the "items" detail
+func batchError(items []BatchItem) *ErrorInfo {
+	errInfo := ErrorInfo{
+		Code:    BATCH_FAILED,
+		Message: "every batch item failed",
+	}
+	if first := items[0].ErrorInfo; first != nil {
+		errInfo.Code, errInfo.Message = first.Code, first.Message
+		for _, item := range items {
+			if item.ErrorInfo == nil || item.ErrorInfo.Code != first.Code {
+				errInfo.Code, errInfo.Message = BATCH_FAILED, "every batch item failed"
+				break
+			}
+		}
+	}
+	errInfo.Details = map[string]any{"items": items}
+	return &errInfo
+}
+
+func mixedOutcomes(items []BatchItem) bool {
+	for _, item := range items {
+		if item.Status != items[0].Status {
+			return true
+		}
+	}
+	return false
+}
+
+// PartitionBatch splits the items of a batch response into the decoded data
+// of successful items and exceptions for failed items, both keyed by item ID.
+// Failed items keep their code and message, and their HTTP status is mapped
+// back to an exception status. Error responses written by Batch when every
+// item failed are partitioned from their "items" detail.
+func PartitionBatch[T any](r Response) (map[string]T, map[string]error, error) {
+	if !r.Success && r.ErrorInfo != nil {
+		if details, ok := r.ErrorInfo.Details.(map[string]any); ok && details["items"] != nil {
+			r = Response{Status: http.StatusOK, Success: true, Data: details}
+		}
+	}
+	batch, err := ReadData[BatchData](r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	successes := make(map[string]T)
+	failures := make(map[string]error)
+	for _, item := range batch.Items {
+		if !item.Success {
+			failures[item.ID] = itemError(item)
+			continue
+		}
+		data, err := ReadData[T](Response{Status: item.Status, Success: true, Data: item.Data})
+		if err != nil {
+			return nil, nil, err
+		}
+		successes[item.ID] = data
+	}
+	return successes, failures, nil
+}
+
+func itemError(item BatchItem) error {
+	errInfo := ErrorInfo{
+		Code:    UNKNOWN_ERROR,
+		Message: UNKNOWN_DETAIL,
+	}
+	if item.ErrorInfo != nil {
+		errInfo = *item.ErrorInfo
+	}
+	text := errInfo.Detail
+	if text == "" {
+		text = errInfo.Message
+	}
+	return exception.New(text,
+		exception.WithStatus(exception.StatusFromHTTP(item.Status)),
+		exception.WithCode(exception.Code(errInfo.Code)),
+		exception.WithMessage(errInfo.Message),
+	)
+}
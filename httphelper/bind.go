@@ -0,0 +1,242 @@
+package httphelper
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aeramu/apihelper/exception"
+)
+
+// valueGetter returns the raw values of a parameter, nil when absent
+type valueGetter func(name string) []string
+
+// BindQuery maps the request query parameters onto the fields of T tagged
+// with `query:"name"`. Supported field types are strings, booleans, integers,
+// floats, time.Duration, time.Time (RFC 3339), pointers to them for optional
+// parameters and slices of them for repeated parameters. Adding ",required"
+// to the tag rejects requests without the parameter.
+//
+// Invalid parameters are reported together in a VALIDATION_FAILED exception
+// with one detail per parameter.
+//
+// Example usage:
+//
+//	type listOrdersQuery struct {
+//	    Status []string   `query:"status"`
+//	    Limit  int        `query:"limit"`
+//	    Since  *time.Time `query:"since"`
+//	    UserID string     `query:"user_id,required"`
+//	}
+//
+//	q, err := httphelper.BindQuery[listOrdersQuery](r)
+//	if err != nil {
+//	    httphelper.Error(w, err)
+//	    return
+//	}
+//
+// Parameters:
+//   - r: The HTTP request
+//
+// Returns:
+//   - The bound value
+//   - An error if a parameter is missing or cannot be converted
+func BindQuery[T any](r *http.Request) (T, error) {
+	query := r.URL.Query()
+	var target T
+	invalid := bind(&target, "query", func(name string) []string {
+		return query[name]
+	})
+	if len(invalid) > 0 {
+		return target, bindError(invalid, exception.StatusValidationFailed, exception.CodeValidationFailed, "invalid query parameters")
+	}
+	return target, nil
+}
+
+// bind sets the fields of the struct pointed to by target tagged with tag
+// from the values returned by get, and returns the reason of each invalid
+// parameter keyed by its name
+func bind(target any, tag string, get valueGetter) map[string]string {
+	v := reflect.ValueOf(target).Elem()
+	if v.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("httphelper: cannot bind %s parameters into %s", tag, v.Type()))
+	}
+
+	invalid := map[string]string{}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, ok := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+		required := ok && opts == "required"
+
+		values := get(name)
+		if len(values) == 0 || (len(values) == 1 && values[0] == "") {
+			if required {
+				invalid[name] = "is required"
+			}
+			continue
+		}
+		if err := setField(v.Field(i), values); err != nil {
+			invalid[name] = err.Error()
+		}
+	}
+	return invalid
+}
+
+// setField converts values into the type of field and sets it
+func setField(field reflect.Value, values []string) error {
+	switch field.Kind() {
+	case reflect.Slice:
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setValue(slice.Index(i), value); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	case reflect.Pointer:
+		ptr := reflect.New(field.Type().Elem())
+		if err := setValue(ptr.Elem(), values[0]); err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	default:
+		return setValue(field, values[0])
+	}
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// setValue converts a single value into the type of field and sets it
+func setValue(field reflect.Value, value string) error {
+	switch field.Type() {
+	case timeType:
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return fmt.Errorf("must be an RFC 3339 time")
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("must be a duration")
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("must be a boolean")
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be an integer")
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a non-negative integer")
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a number")
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("has unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+// bindError builds the exception reporting the invalid parameters
+func bindError(invalid map[string]string, status exception.Status, code exception.Code, message string) error {
+	names := make([]string, 0, len(invalid))
+	for name := range invalid {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	reasons := make([]string, 0, len(names))
+	opts := []exception.ErrorOption{
+		exception.WithStatus(status),
+		exception.WithCode(code),
+		exception.WithMessage(message),
+	}
+	for _, name := range names {
+		reasons = append(reasons, name+" "+invalid[name])
+		opts = append(opts, exception.WithDetail(name, invalid[name]))
+	}
+	return exception.New(message+": "+strings.Join(reasons, ", "), opts...)
+}
+
+// PathExtractor returns the value of the named path parameter of r, empty when absent
+type PathExtractor func(r *http.Request, name string) string
+
+// pathValue extracts path parameters matched by the http.ServeMux patterns
+func pathValue(r *http.Request, name string) string {
+	return r.PathValue(name)
+}
+
+// BindPath maps the request path parameters onto the fields of T tagged with
+// `path:"name"`, converting them like BindQuery. Parameters are extracted
+// with r.PathValue by default; routers such as chi or gorilla/mux can be
+// plugged in with WithPathExtractor.
+//
+// Invalid parameters are reported together in an INVALID_REQUEST exception
+// with one detail per parameter.
+//
+// Example usage:
+//
+//	httphelper.Configure(httphelper.WithPathExtractor(func(r *http.Request, name string) string {
+//	    return chi.URLParam(r, name)
+//	}))
+//
+//	type orderPath struct {
+//	    OrderID int64 `path:"order_id,required"`
+//	}
+//
+//	p, err := httphelper.BindPath[orderPath](r)
+//
+// Parameters:
+//   - r: The HTTP request
+//
+// Returns:
+//   - The bound value
+//   - An error if a parameter is missing or cannot be converted
+func BindPath[T any](r *http.Request) (T, error) {
+	extract := std().cfg.pathExtractor
+	var target T
+	invalid := bind(&target, "path", func(name string) []string {
+		if value := extract(r, name); value != "" {
+			return []string{value}
+		}
+		return nil
+	})
+	if len(invalid) > 0 {
+		return target, bindError(invalid, exception.StatusInvalidRequest, exception.CodeInvalidRequest, "invalid path parameters")
+	}
+	return target, nil
+}
@@ -0,0 +1,72 @@
+package httphelper
+
+import (
+	"net/http"
+)
+
+// ResponseBuilder builds a successful response step by step, so combinations
+// of status, warnings and metadata don't require a dedicated helper each.
+//
+// Example usage:
+//
+//	httphelper.NewResponse().
+//	    Status(http.StatusCreated).
+//	    Data(order).
+//	    Meta("request_id", requestID).
+//	    Write(w)
+type ResponseBuilder struct {
+	response Response
+}
+
+// NewResponse creates a ResponseBuilder for a 200 OK response
+func NewResponse() *ResponseBuilder {
+	return &ResponseBuilder{
+		response: Response{
+			Status:  http.StatusOK,
+			Success: true,
+		},
+	}
+}
+
+// Data sets the response payload
+func (b *ResponseBuilder) Data(data any) *ResponseBuilder {
+	b.response.Data = data
+	return b
+}
+
+// Status sets the HTTP status code of the response
+func (b *ResponseBuilder) Status(status int) *ResponseBuilder {
+	b.response.Status = status
+	return b
+}
+
+// Warning appends a non-fatal issue to the response
+func (b *ResponseBuilder) Warning(warning ErrorInfo) *ResponseBuilder {
+	b.response.Warnings = append(b.response.Warnings, warning)
+	return b
+}
+
+// Page sets the pagination information of the response
+func (b *ResponseBuilder) Page(page Page) *ResponseBuilder {
+	b.response.Pagination = &page
+	return b
+}
+
+// Meta sets a metadata entry of the response
+func (b *ResponseBuilder) Meta(key string, value any) *ResponseBuilder {
+	if b.response.Meta == nil {
+		b.response.Meta = make(map[string]any)
+	}
+	b.response.Meta[key] = value
+	return b
+}
+
+// Build returns the built Response
+func (b *ResponseBuilder) Build() Response {
+	return b.response
+}
+
+// Write writes the built response in JSON format
+func (b *ResponseBuilder) Write(w http.ResponseWriter) {
+	std().writeResponse(w, b.response, nil)
+}
@@ -0,0 +1,48 @@
+package httphelper
+
+import (
+	"context"
+	"errors"
+)
+
+const (
+	// CLIENT_CLOSED_REQUEST is the error code used when the client cancelled the request
+	CLIENT_CLOSED_REQUEST = "CLIENT_CLOSED_REQUEST"
+	// StatusClientClosedRequest is the non-standard status written for cancelled requests
+	StatusClientClosedRequest = 499
+)
+
+// clientClosedError replaces errors caused by the client cancelling the
+// request, so they are reported as 499 instead of polluting 5xx metrics
+type clientClosedError struct {
+	err error
+}
+
+func (e clientClosedError) Error() string {
+	return e.err.Error()
+}
+
+func (e clientClosedError) Unwrap() error {
+	return e.err
+}
+
+func (e clientClosedError) HTTPStatus() int {
+	return StatusClientClosedRequest
+}
+
+func (e clientClosedError) Message() string {
+	return "client closed request"
+}
+
+func (e clientClosedError) Code() string {
+	return CLIENT_CLOSED_REQUEST
+}
+
+// clientClosed wraps err in a clientClosedError when it derives from
+// context.Canceled or ctx has been cancelled
+func clientClosed(ctx context.Context, err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled) {
+		return clientClosedError{err: err}
+	}
+	return err
+}
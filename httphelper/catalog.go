@@ -0,0 +1,18 @@
+package httphelper
+
+import (
+	"net/http"
+
+	"github.com/aeramu/apihelper/exception"
+)
+
+// ListErrorCodes is an HTTP handler listing the registered exception catalog,
+// including ownership metadata, so governance tooling and API consumers can
+// discover the error codes of a service.
+//
+// Example usage:
+//
+//	mux.HandleFunc("GET /errors", httphelper.ListErrorCodes)
+func ListErrorCodes(w http.ResponseWriter, r *http.Request) {
+	OK(w, exception.Catalog())
+}
@@ -0,0 +1,73 @@
+package httphelper
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+)
+
+// Codec encodes response envelopes for a content type.
+// Registering a codec with WithCodec lets NegotiationMiddleware serve
+// clients sending the matching Accept header, e.g. msgpack or protobuf,
+// and DecodeResponseAs read such responses back.
+//
+// Example usage:
+//
+//	type msgpackCodec struct{}
+//
+//	func (msgpackCodec) ContentType() string             { return "application/msgpack" }
+//	func (msgpackCodec) Marshal(v any) ([]byte, error)   { return msgpack.Marshal(v) }
+//	func (msgpackCodec) Unmarshal(b []byte, v any) error { return msgpack.Unmarshal(b, v) }
+//
+//	httphelper.Configure(httphelper.WithCodec(msgpackCodec{}))
+type Codec interface {
+	// ContentType returns the media type handled by the codec
+	ContentType() string
+	// Marshal encodes the response body
+	Marshal(v any) ([]byte, error)
+	// Unmarshal decodes data into v
+	Unmarshal(data []byte, v any) error
+}
+
+// jsonCodec encodes bodies as JSON
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string {
+	return ContentTypeJSON
+}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// xmlCodec encodes bodies as XML with a response root element
+type xmlCodec struct{}
+
+func (xmlCodec) ContentType() string {
+	return ContentTypeXML
+}
+
+func (xmlCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	err := xml.NewEncoder(&buf).EncodeElement(v, xml.StartElement{Name: xml.Name{Local: "response"}})
+	return buf.Bytes(), err
+}
+
+func (xmlCodec) Unmarshal(data []byte, v any) error {
+	return xml.Unmarshal(data, v)
+}
+
+// codec returns the configured codec for contentType
+func (h *Helper) codec(contentType string) (Codec, bool) {
+	for _, c := range h.cfg.codecs {
+		if c.ContentType() == contentType {
+			return c, true
+		}
+	}
+	return nil, false
+}
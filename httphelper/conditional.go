@@ -0,0 +1,269 @@
+package httphelper
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CachedResponse is a response stored for conditional requests
+type CachedResponse struct {
+	// ETag is the entity tag returned by the server
+	ETag string
+	// LastModified is the Last-Modified header returned by the server
+	LastModified string
+	// Header contains the headers of the cached response
+	Header http.Header
+	// Body is the body of the cached response
+	Body []byte
+	// Vary holds the values of the request headers listed by the Vary
+	// header of the response; the response is only reused for requests
+	// sending the same values
+	Vary http.Header
+}
+
+// ConditionalStore stores cached responses keyed by URL and credentials
+type ConditionalStore interface {
+	Get(key string) (CachedResponse, bool)
+	Set(key string, resp CachedResponse)
+}
+
+// MemoryConditionalStore is an in-memory ConditionalStore, optionally
+// bounded with least recently used eviction
+type MemoryConditionalStore struct {
+	mu      sync.Mutex
+	max     int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+// memoryEntry is an element of the MemoryConditionalStore recency list
+type memoryEntry struct {
+	key  string
+	resp CachedResponse
+}
+
+// NewMemoryConditionalStore creates an empty, unbounded MemoryConditionalStore
+func NewMemoryConditionalStore() *MemoryConditionalStore {
+	return NewLRUConditionalStore(0)
+}
+
+// NewLRUConditionalStore creates an empty MemoryConditionalStore holding up
+// to maxEntries responses, evicting the least recently used one when full.
+// Zero means unbounded.
+func NewLRUConditionalStore(maxEntries int) *MemoryConditionalStore {
+	return &MemoryConditionalStore{
+		max:     maxEntries,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Get returns the cached response for key
+func (s *MemoryConditionalStore) Get(key string) (CachedResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.entries[key]
+	if !ok {
+		return CachedResponse{}, false
+	}
+	s.order.MoveToFront(elem)
+	return elem.Value.(*memoryEntry).resp, true
+}
+
+// Set stores the response under key
+func (s *MemoryConditionalStore) Set(key string, resp CachedResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if elem, ok := s.entries[key]; ok {
+		elem.Value.(*memoryEntry).resp = resp
+		s.order.MoveToFront(elem)
+		return
+	}
+	s.entries[key] = s.order.PushFront(&memoryEntry{key: key, resp: resp})
+	if s.max > 0 && s.order.Len() > s.max {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*memoryEntry).key)
+	}
+}
+
+// ConditionalTransport is an http.RoundTripper that remembers the ETag and
+// Last-Modified validators of successful GET responses, sends them as
+// If-None-Match and If-Modified-Since on subsequent requests, and replays the
+// cached body as a 200 response when the server answers 304 Not Modified.
+// Clients decoding the envelope (net/http or resty) are unaware of the cache.
+//
+// Responses are cached per URL and credentials, so a response fetched with
+// one Authorization or Cookie header is never replayed for another, and
+// only reused for requests matching the headers listed by their Vary
+// header. Requests and responses with Cache-Control no-store, and private
+// or Vary: * responses, are not cached.
+//
+// Example usage:
+//
+//	client := &http.Client{
+//	    Transport: httphelper.NewConditionalTransport(nil, httphelper.NewMemoryConditionalStore()),
+//	}
+type ConditionalTransport struct {
+	base  http.RoundTripper
+	store ConditionalStore
+}
+
+// NewConditionalTransport creates a ConditionalTransport.
+// A nil base uses http.DefaultTransport.
+func NewConditionalTransport(base http.RoundTripper, store ConditionalStore) *ConditionalTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &ConditionalTransport{
+		base:  base,
+		store: store,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *ConditionalTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" ||
+		hasCacheDirective(req.Header, "no-store") {
+		return t.base.RoundTrip(req)
+	}
+
+	key := conditionalKey(req)
+	cached, ok := t.store.Get(key)
+	if ok && !sameVary(cached.Vary, req.Header) {
+		ok = false
+	}
+	if ok {
+		req = req.Clone(req.Context())
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok && resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		header := cached.Header.Clone()
+		header.Set("Content-Length", strconv.Itoa(len(cached.Body)))
+		return &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Proto:         resp.Proto,
+			ProtoMajor:    resp.ProtoMajor,
+			ProtoMinor:    resp.ProtoMinor,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(cached.Body)),
+			ContentLength: int64(len(cached.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	etag := resp.Header.Get("ETag")
+	lastModified := resp.Header.Get("Last-Modified")
+	if resp.StatusCode != http.StatusOK || (etag == "" && lastModified == "") || !cacheable(resp.Header) {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	t.store.Set(key, CachedResponse{
+		ETag:         etag,
+		LastModified: lastModified,
+		Header:       resp.Header.Clone(),
+		Body:         body,
+		Vary:         varyValues(resp.Header, req.Header),
+	})
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// conditionalKey returns the store key of req: its URL, plus a digest of
+// its credentials so responses are never shared between callers
+func conditionalKey(req *http.Request) string {
+	auth, cookie := req.Header.Get("Authorization"), req.Header.Get("Cookie")
+	if auth == "" && cookie == "" {
+		return req.URL.String()
+	}
+	sum := sha256.Sum256([]byte(auth + "\n" + cookie))
+	return req.URL.String() + " " + hex.EncodeToString(sum[:16])
+}
+
+// cacheable reports whether a response with header may be stored
+func cacheable(header http.Header) bool {
+	if hasCacheDirective(header, "no-store") || hasCacheDirective(header, "private") {
+		return false
+	}
+	for _, name := range varyNames(header) {
+		if name == "*" {
+			return false
+		}
+	}
+	return true
+}
+
+// hasCacheDirective reports whether the Cache-Control header contains directive
+func hasCacheDirective(header http.Header, directive string) bool {
+	for _, value := range header.Values("Cache-Control") {
+		for _, d := range strings.Split(value, ",") {
+			name, _, _ := strings.Cut(strings.TrimSpace(d), "=")
+			if strings.EqualFold(name, directive) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// varyNames returns the header names listed by the Vary header
+func varyNames(header http.Header) []string {
+	var names []string
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	return names
+}
+
+// varyValues returns the values sent in reqHeader for the headers listed by
+// the Vary header of the response
+func varyValues(respHeader, reqHeader http.Header) http.Header {
+	names := varyNames(respHeader)
+	if len(names) == 0 {
+		return nil
+	}
+	vary := make(http.Header, len(names))
+	for _, name := range names {
+		vary[name] = reqHeader.Values(name)
+	}
+	return vary
+}
+
+// sameVary reports whether header sends the values recorded in vary
+func sameVary(vary, header http.Header) bool {
+	for name, values := range vary {
+		if strings.Join(header.Values(name), ",") != strings.Join(values, ",") {
+			return false
+		}
+	}
+	return true
+}
@@ -1,10 +1,33 @@
 package httphelper
 
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
 // Configuration options
 type config struct {
 	defaultErrorCode    string
 	defaultErrorMessage string
 	includeDetails      bool
+	internalDetailRate  float64
+	problemDetails      bool
+	metaExtractor       MetaExtractor
+	envelope            Envelope
+	multiStatus         bool
+	messageTemplates    map[string]string
+	strictEnvelope      bool
+	strictDecoding      bool
+	fallback            Fallback
+	onDegraded          func(ctx context.Context, err error)
+	codecs              []Codec
+	cacheControl        string
+	pathExtractor       PathExtractor
+	maxFormSize         int64
+	maxResponseSize     int64
+	statusMapping       map[string]int
+	translator          Translator
 }
 
 const (
@@ -17,11 +40,29 @@ const (
 // Option represents a configuration option for the httphelper package
 type Option func(*config)
 
-// DefaultConfig represents the default configuration
-var defaultConfig = config{
+// initialConfig represents the built-in configuration used by New
+var initialConfig = config{
 	defaultErrorCode:    INTERNAL_SERVER_ERROR,
 	defaultErrorMessage: INTERNAL_SERVER_MESSAGE,
 	includeDetails:      true,
+	internalDetailRate:  1,
+	envelope:            defaultEnvelope{},
+	codecs:              []Codec{jsonCodec{}, xmlCodec{}},
+	pathExtractor:       pathValue,
+	maxFormSize:         32 << 20,
+	maxResponseSize:     10 << 20,
+}
+
+// defaultHelper holds the Helper using the package-level configuration
+// changed by Configure. It is replaced as a whole and never mutated, so
+// writers can read it concurrently with Configure.
+var defaultHelper atomic.Pointer[Helper]
+
+// configureMu serializes Configure calls so concurrent updates are not lost
+var configureMu sync.Mutex
+
+func init() {
+	defaultHelper.Store(&Helper{cfg: initialConfig})
 }
 
 // WithDefaultErrorCode sets the default error code for non-HTTPError errors
@@ -45,11 +86,171 @@ func WithIncludeDetails(include bool) Option {
 	}
 }
 
-// Configure applies the given options to the package configuration
+// WithInternalDetailRate sets the fraction (0 to 1) of internal server error
+// responses that include error details. Other error responses are unaffected.
+func WithInternalDetailRate(rate float64) Option {
+	return func(c *config) {
+		c.internalDetailRate = rate
+	}
+}
+
+// WithProblemDetails makes Error write RFC 7807 problem documents
+// (application/problem+json) instead of the standard envelope
+func WithProblemDetails(enabled bool) Option {
+	return func(c *config) {
+		c.problemDetails = enabled
+	}
+}
+
+// WithMetaExtractor sets the function used to populate the response meta
+// from the request context, see MetaMiddleware
+func WithMetaExtractor(extractor MetaExtractor) Option {
+	return func(c *config) {
+		c.metaExtractor = extractor
+	}
+}
+
+// WithEnvelope sets the envelope used to build the response bodies.
+// A nil envelope restores the default Response format.
+func WithEnvelope(envelope Envelope) Option {
+	return func(c *config) {
+		if envelope == nil {
+			envelope = defaultEnvelope{}
+		}
+		c.envelope = envelope
+	}
+}
+
+// WithMultiStatus makes Batch respond with 207 Multi-Status when the items
+// have mixed outcomes, and with the shared status when they all failed with
+// the same one
+func WithMultiStatus(enabled bool) Option {
+	return func(c *config) {
+		c.multiStatus = enabled
+	}
+}
+
+// WithMessageTemplates sets message templates by error code, overriding the
+// message of matching errors. Templates may contain named placeholders such
+// as "order {order_id} not found", rendered from the error details.
+func WithMessageTemplates(templates map[string]string) Option {
+	return func(c *config) {
+		c.messageTemplates = templates
+	}
+}
+
+// WithStrictEnvelope makes DecodeResponse reject envelopes with unknown
+// top-level fields, inconsistent success and status, or both data and error set
+func WithStrictEnvelope(strict bool) Option {
+	return func(c *config) {
+		c.strictEnvelope = strict
+	}
+}
+
+// WithStrictDecoding makes ReadData and the JSON body binding of Handle
+// reject payloads with fields unknown to the target type, or missing fields
+// that are neither pointers nor tagged omitempty, with a VALIDATION_FAILED error
+// listing every offending field. This catches contract drift between client
+// and server instead of silently dropping fields.
+func WithStrictDecoding(strict bool) Option {
+	return func(c *config) {
+		c.strictDecoding = strict
+	}
+}
+
+// WithFallback sets the hook deciding whether an error degrades to a
+// successful response with a default value instead of an error response
+func WithFallback(fallback Fallback) Option {
+	return func(c *config) {
+		c.fallback = fallback
+	}
+}
+
+// WithDegradationHook sets a hook called whenever an error is replaced by a
+// fallback value, e.g. to count degraded responses
+func WithDegradationHook(hook func(ctx context.Context, err error)) Option {
+	return func(c *config) {
+		c.onDegraded = hook
+	}
+}
+
+// WithCodec adds a codec used by NegotiationMiddleware and DecodeResponseAs.
+// A codec for an already registered content type replaces it.
+func WithCodec(codec Codec) Option {
+	return func(c *config) {
+		codecs := make([]Codec, 0, len(c.codecs)+1)
+		for _, existing := range c.codecs {
+			if existing.ContentType() != codec.ContentType() {
+				codecs = append(codecs, existing)
+			}
+		}
+		c.codecs = append(codecs, codec)
+	}
+}
+
+// WithDefaultCacheControl sets the Cache-Control header of successful
+// responses that do not set one with WithCacheControl
+func WithDefaultCacheControl(value string) Option {
+	return func(c *config) {
+		c.cacheControl = value
+	}
+}
+
+// WithPathExtractor sets the function BindPath uses to read path parameters,
+// nil to restore r.PathValue
+func WithPathExtractor(extractor PathExtractor) Option {
+	return func(c *config) {
+		if extractor == nil {
+			extractor = pathValue
+		}
+		c.pathExtractor = extractor
+	}
+}
+
+// WithMaxFormSize sets the maximum size in bytes of form bodies parsed by
+// BindForm and Files, 32 MB by default
+func WithMaxFormSize(size int64) Option {
+	return func(c *config) {
+		c.maxFormSize = size
+	}
+}
+
+// WithMaxResponseSize sets the maximum size in bytes of response bodies read
+// by FromHTTPResponse, 10 MB by default
+func WithMaxResponseSize(size int64) Option {
+	return func(c *config) {
+		c.maxResponseSize = size
+	}
+}
+
+// WithStatusMapping overrides the HTTP status written for specific error
+// codes, e.g. to respond to VALIDATION_FAILED with 400 instead of 422.
+// The mapping applies to both the envelope and problem details.
+func WithStatusMapping(mapping map[string]int) Option {
+	return func(c *config) {
+		c.statusMapping = mapping
+	}
+}
+
+// WithTranslator sets the Translator used to localize error messages for
+// requests served through LocaleMiddleware
+func WithTranslator(translator Translator) Option {
+	return func(c *config) {
+		c.translator = translator
+	}
+}
+
+// Configure applies the given options to the package configuration.
+// It is safe to call while other goroutines write responses: each response
+// uses a snapshot of the configuration taken when it starts being written,
+// so an in-flight response sees either all or none of the changes.
 func Configure(opts ...Option) {
-	cfg := defaultConfig
+	configureMu.Lock()
+	defer configureMu.Unlock()
+
+	cfg := defaultHelper.Load().cfg
 	for _, opt := range opts {
 		opt(&cfg)
 	}
-	defaultConfig = cfg
+	defaultHelper.Store(&Helper{cfg: cfg})
 }
@@ -0,0 +1,80 @@
+package httphelper
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/aeramu/apihelper/exception"
+)
+
+// RequireContentType returns a middleware rejecting requests whose
+// Content-Type is not in allowed, defaulting to application/json, with an
+// INVALID_REQUEST envelope and status 415. Entries may use a wildcard
+// subtype such as "multipart/*". A charset parameter, when present, must be
+// UTF-8. GET, HEAD, DELETE and OPTIONS requests without a body are exempt.
+//
+// Example usage:
+//
+//	api := httphelper.RequireContentType(httphelper.ContentTypeJSON, "multipart/form-data")(mux)
+func RequireContentType(allowed ...string) func(http.Handler) http.Handler {
+	if len(allowed) == 0 {
+		allowed = []string{ContentTypeJSON}
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if emptyBodyExempt(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if err := checkContentType(r.Header.Get("Content-Type"), allowed); err != nil {
+				writeError(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// emptyBodyExempt reports whether r is a bodiless request that doesn't need a Content-Type
+func emptyBodyExempt(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete, http.MethodOptions:
+		return r.ContentLength == 0 && len(r.TransferEncoding) == 0
+	}
+	return false
+}
+
+// checkContentType returns an error if contentType doesn't match allowed
+func checkContentType(contentType string, allowed []string) *limitError {
+	unsupported := func(detail string) *limitError {
+		return &limitError{
+			code:       exception.CodeInvalidRequest.String(),
+			httpStatus: http.StatusUnsupportedMediaType,
+			message:    "unsupported content type",
+			detail:     detail,
+		}
+	}
+
+	if contentType == "" {
+		return unsupported("missing content type")
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return unsupported(fmt.Sprintf("malformed content type %q", contentType))
+	}
+	if charset, ok := params["charset"]; ok && !strings.EqualFold(charset, "utf-8") {
+		return unsupported(fmt.Sprintf("unsupported charset %q", charset))
+	}
+	for _, a := range allowed {
+		if prefix, ok := strings.CutSuffix(a, "/*"); ok {
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return nil
+			}
+		} else if strings.EqualFold(a, mediaType) {
+			return nil
+		}
+	}
+	return unsupported(fmt.Sprintf("content type %q is not one of %s", mediaType, strings.Join(allowed, ", ")))
+}
@@ -0,0 +1,81 @@
+package httphelper
+
+import (
+	"context"
+	"net/http"
+)
+
+type contextOptionsKey struct{}
+
+// WithContextOptions returns a copy of ctx carrying configuration overrides
+// for the current request. Overrides are applied on top of the package or
+// Helper configuration by OKContext and ErrorContext, e.g. to include error
+// details only for internal callers.
+//
+// Example usage:
+//
+//	func internalOnly(next http.Handler) http.Handler {
+//	    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//	        ctx := httphelper.WithContextOptions(r.Context(), httphelper.WithIncludeDetails(true))
+//	        next.ServeHTTP(w, r.WithContext(ctx))
+//	    })
+//	}
+func WithContextOptions(ctx context.Context, opts ...Option) context.Context {
+	existing := contextOptions(ctx)
+	merged := make([]Option, 0, len(existing)+len(opts))
+	merged = append(merged, existing...)
+	merged = append(merged, opts...)
+	return context.WithValue(ctx, contextOptionsKey{}, merged)
+}
+
+func contextOptions(ctx context.Context) []Option {
+	opts, _ := ctx.Value(contextOptionsKey{}).([]Option)
+	return opts
+}
+
+// forContext returns a Helper with the overrides carried by ctx applied
+func (h *Helper) forContext(ctx context.Context) *Helper {
+	opts := contextOptions(ctx)
+	if len(opts) == 0 {
+		return h
+	}
+	cfg := h.cfg
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Helper{cfg: cfg}
+}
+
+// OKContext is like OK but applies the configuration overrides carried by ctx
+func (h *Helper) OKContext(ctx context.Context, w http.ResponseWriter, data any, opts ...WriteOption) {
+	h.forContext(ctx).OK(w, data, opts...)
+}
+
+// ErrorContext is like Error but applies the configuration overrides carried by ctx
+func (h *Helper) ErrorContext(ctx context.Context, w http.ResponseWriter, err error, opts ...WriteOption) {
+	h.forContext(ctx).error(ctx, w, err, opts)
+}
+
+// OKContext writes a successful JSON response like OK, applying the
+// configuration overrides carried by ctx, see WithContextOptions.
+//
+// Parameters:
+//   - ctx: The request context
+//   - w: The HTTP response writer
+//   - data: The data to include in the response
+//   - opts: Options customizing the response headers, such as WithCacheControl
+func OKContext(ctx context.Context, w http.ResponseWriter, data any, opts ...WriteOption) {
+	std().OKContext(ctx, w, data, opts...)
+}
+
+// ErrorContext writes an error response like Error, applying the
+// configuration overrides carried by ctx, see WithContextOptions.
+//
+// Parameters:
+//   - ctx: The request context
+//   - w: The HTTP response writer
+//   - err: The error to include in the response
+//   - opts: Options customizing the response headers, such as WithHeader
+func ErrorContext(ctx context.Context, w http.ResponseWriter, err error, opts ...WriteOption) {
+	std().ErrorContext(ctx, w, err, opts...)
+}
@@ -0,0 +1,53 @@
+package httphelper
+
+import (
+	"net/http"
+	"time"
+)
+
+// WithCookie adds a Set-Cookie header to the response.
+// Invalid cookies are dropped, as with http.SetCookie.
+func WithCookie(cookie *http.Cookie) WriteOption {
+	return func(h http.Header) {
+		if v := cookie.String(); v != "" {
+			h.Add("Set-Cookie", v)
+		}
+	}
+}
+
+// SecureCookie returns a copy of cookie with Secure and HttpOnly set, and
+// SameSite and Path defaulting to Lax and "/" when unset
+func SecureCookie(cookie *http.Cookie) *http.Cookie {
+	c := *cookie
+	c.Secure = true
+	c.HttpOnly = true
+	if c.SameSite == 0 {
+		c.SameSite = http.SameSiteLaxMode
+	}
+	if c.Path == "" {
+		c.Path = "/"
+	}
+	return &c
+}
+
+// SetSecureCookie adds cookie to the response with the defaults of
+// SecureCookie. Call it before the writer, or pass WithCookie(SecureCookie(c))
+// to the writer instead.
+//
+// Example usage:
+//
+//	httphelper.SetSecureCookie(w, &http.Cookie{Name: "session", Value: token, MaxAge: 3600})
+//	httphelper.OK(w, user)
+func SetSecureCookie(w http.ResponseWriter, cookie *http.Cookie) {
+	WithCookie(SecureCookie(cookie))(w.Header())
+}
+
+// ClearCookie instructs the client to delete the cookie with the given name
+// set on path "/"
+func ClearCookie(w http.ResponseWriter, name string) {
+	SetSecureCookie(w, &http.Cookie{
+		Name:    name,
+		MaxAge:  -1,
+		Expires: time.Unix(0, 0),
+	})
+}
@@ -0,0 +1,158 @@
+package httphelper
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aeramu/apihelper/exception"
+)
+
+// defaultCORSMethods are the methods allowed when CORS.AllowedMethods is empty
+var defaultCORSMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// CORS handles cross-origin requests, answering preflight requests and
+// rejecting disallowed origins with a PERMISSION_DENIED envelope instead of
+// a bare status code, so browser clients get the same error shape as any
+// other failure.
+//
+// Example usage:
+//
+//	cors := httphelper.CORS{
+//	    AllowedOrigins:   []string{"https://app.example.com"},
+//	    AllowedHeaders:   []string{"Authorization", "Content-Type"},
+//	    AllowCredentials: true,
+//	    MaxAge:           10 * time.Minute,
+//	}
+//	http.ListenAndServe(":8080", cors.Middleware(mux))
+type CORS struct {
+	// AllowedOrigins lists the allowed origins; "*" allows any origin
+	AllowedOrigins []string
+	// AllowedMethods lists the allowed methods, defaulting to the common REST methods
+	AllowedMethods []string
+	// AllowedHeaders lists the allowed request headers; empty allows the headers requested by the preflight
+	AllowedHeaders []string
+	// ExposedHeaders lists the response headers readable by the client
+	ExposedHeaders []string
+	// AllowCredentials allows cookies and authorization headers on cross-origin
+	// requests; it requires AllowedOrigins to list the origins explicitly
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache preflight results; zero omits the header
+	MaxAge time.Duration
+}
+
+// Middleware returns a handler applying the CORS policy before calling next.
+// Requests without an Origin header are passed through unchanged. It panics
+// if AllowCredentials is combined with the "*" origin, which would let any
+// site make credentialed requests.
+func (c CORS) Middleware(next http.Handler) http.Handler {
+	if c.AllowCredentials && c.wildcard() {
+		panic(`httphelper: CORS cannot allow credentials for the "*" origin`)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		header := w.Header()
+		header.Add("Vary", "Origin")
+		if !c.allowOrigin(origin) {
+			writeError(w, r, exception.New("origin not allowed",
+				exception.WithStatus(exception.StatusPermissionDenied),
+				exception.WithCode(exception.CodePermissionDenied),
+				exception.WithMessage("origin not allowed"),
+				exception.WithDetail("origin", origin),
+			))
+			return
+		}
+
+		if c.wildcard() {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+		if c.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		method := r.Header.Get("Access-Control-Request-Method")
+		if r.Method != http.MethodOptions || method == "" {
+			if len(c.ExposedHeaders) > 0 {
+				header.Set("Access-Control-Expose-Headers", strings.Join(c.ExposedHeaders, ", "))
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		header.Add("Vary", "Access-Control-Request-Method")
+		header.Add("Vary", "Access-Control-Request-Headers")
+		if !c.allowMethod(method) {
+			writeError(w, r, exception.New("method not allowed",
+				exception.WithStatus(exception.StatusPermissionDenied),
+				exception.WithCode(exception.CodePermissionDenied),
+				exception.WithMessage("method not allowed"),
+				exception.WithDetail("method", method),
+			))
+			return
+		}
+
+		methods := c.AllowedMethods
+		if len(methods) == 0 {
+			methods = defaultCORSMethods
+		}
+		header.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+		if len(c.AllowedHeaders) > 0 {
+			header.Set("Access-Control-Allow-Headers", strings.Join(c.AllowedHeaders, ", "))
+		} else if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+			header.Set("Access-Control-Allow-Headers", requested)
+		}
+		if c.MaxAge > 0 {
+			header.Set("Access-Control-Max-Age", strconv.Itoa(int(c.MaxAge.Seconds())))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// wildcard reports whether any origin is allowed
+func (c CORS) wildcard() bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// allowOrigin reports whether origin is allowed
+func (c CORS) allowOrigin(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowMethod reports whether method is allowed
+func (c CORS) allowMethod(method string) bool {
+	methods := c.AllowedMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	for _, allowed := range methods {
+		if strings.EqualFold(allowed, method) {
+			return true
+		}
+	}
+	return false
+}
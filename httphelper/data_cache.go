@@ -0,0 +1,108 @@
+package httphelper
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+)
+
+// dataCache memoizes the values decoded from a response Data by target
+// type. It is shared by the copies of a Response, so ReadData calls on a
+// response passed along a middleware chain decode its Data only once.
+// Cached values are only used while Data is still the decoded payload, and
+// are deep copied in and out so callers never share slices, maps or pointers.
+type dataCache struct {
+	raw    json.RawMessage
+	mu     sync.Mutex
+	values map[reflect.Type]reflect.Value
+}
+
+// valid reports whether data is the payload the cache was created for
+func (c *dataCache) valid(data any) bool {
+	if c == nil {
+		return false
+	}
+	raw, ok := data.(json.RawMessage)
+	if !ok || len(raw) != len(c.raw) {
+		return false
+	}
+	return len(raw) == 0 || &raw[0] == &c.raw[0]
+}
+
+// load sets target to the cached value of its type when data is the cached
+// payload, reporting whether a value was found
+func (c *dataCache) load(data any, target any) bool {
+	if !c.valid(data) {
+		return false
+	}
+	v := reflect.ValueOf(target)
+	c.mu.Lock()
+	cached, ok := c.values[v.Type()]
+	c.mu.Unlock()
+	if ok {
+		v.Elem().Set(deepCopy(cached))
+	}
+	return ok
+}
+
+// store caches a copy of the value target points to, decoded from data
+func (c *dataCache) store(data any, target any) {
+	if !c.valid(data) {
+		return
+	}
+	v := reflect.ValueOf(target)
+	cached := deepCopy(v.Elem())
+	c.mu.Lock()
+	if c.values == nil {
+		c.values = make(map[reflect.Type]reflect.Value)
+	}
+	c.values[v.Type()] = cached
+	c.mu.Unlock()
+}
+
+// deepCopy returns a copy of v that shares no pointers, slices or maps with it.
+// Unexported struct fields are copied as is since decoding never sets them.
+func deepCopy(v reflect.Value) reflect.Value {
+	out := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			elem := reflect.New(v.Type().Elem())
+			elem.Elem().Set(deepCopy(v.Elem()))
+			out.Set(elem)
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			out.Set(deepCopy(v.Elem()))
+		}
+	case reflect.Slice:
+		if !v.IsNil() {
+			out.Set(reflect.MakeSlice(v.Type(), v.Len(), v.Len()))
+			for i := 0; i < v.Len(); i++ {
+				out.Index(i).Set(deepCopy(v.Index(i)))
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(deepCopy(v.Index(i)))
+		}
+	case reflect.Map:
+		if !v.IsNil() {
+			out.Set(reflect.MakeMapWithSize(v.Type(), v.Len()))
+			iter := v.MapRange()
+			for iter.Next() {
+				out.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+			}
+		}
+	case reflect.Struct:
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if out.Field(i).CanSet() {
+				out.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+	default:
+		out.Set(v)
+	}
+	return out
+}
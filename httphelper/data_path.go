@@ -0,0 +1,106 @@
+package httphelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ReadDataPath extracts the field at path in the response Data field and
+// unmarshals it into the specified type T. Path segments are separated by
+// dots; a segment names an object field, or an index into an array. An
+// empty path selects the whole payload, like ReadData.
+//
+// Example usage:
+//
+//	// data: {"result": {"user": {"id": "1"}, "items": [{"sku": "A-1"}]}}
+//	user, err := ReadDataPath[User](response, "result.user")
+//	sku, err := ReadDataPath[string](response, "result.items.0.sku")
+//
+// Parameters:
+//   - r: The Response object containing the data to extract
+//   - path: The dot-separated path of the field to extract
+//
+// Returns:
+//   - The unmarshaled field of type T
+//   - An error if the response contains an error, the path does not exist
+//     or unmarshaling fails
+func ReadDataPath[T any](r Response, path string) (T, error) {
+	var data T
+	if path == "" {
+		return ReadData[T](r)
+	}
+	if err := r.Err(); err != nil {
+		return data, err
+	}
+	if r.Data == nil {
+		return data, fmt.Errorf("response data is nil")
+	}
+	jsonBytes, err := dataJSON(r.Data)
+	if err != nil {
+		return data, err
+	}
+
+	field, err := selectPath(jsonBytes, path)
+	if err != nil {
+		return data, err
+	}
+	if std().cfg.strictDecoding {
+		if err := checkFields(field, &data); err != nil {
+			return data, err
+		}
+	}
+	if err := json.Unmarshal(field, &data); err != nil {
+		return data, fmt.Errorf("failed to unmarshal response data at %q: %w", path, err)
+	}
+	return data, nil
+}
+
+// selectPath returns the JSON value at the dot-separated path in data
+func selectPath(data json.RawMessage, path string) (json.RawMessage, error) {
+	segments := strings.Split(path, ".")
+	for i, segment := range segments {
+		at := strings.Join(segments[:i+1], ".")
+		switch firstByte(data) {
+		case '{':
+			var object map[string]json.RawMessage
+			if err := json.Unmarshal(data, &object); err != nil {
+				return nil, fmt.Errorf("failed to decode response data at %q: %w", at, err)
+			}
+			value, ok := object[segment]
+			if !ok {
+				return nil, fmt.Errorf("response data has no field at %q", at)
+			}
+			data = value
+		case '[':
+			index, err := strconv.Atoi(segment)
+			if err != nil {
+				return nil, fmt.Errorf("response data at %q is an array, not an object", strings.Join(segments[:i], "."))
+			}
+			var array []json.RawMessage
+			if err := json.Unmarshal(data, &array); err != nil {
+				return nil, fmt.Errorf("failed to decode response data at %q: %w", at, err)
+			}
+			if index < 0 || index >= len(array) {
+				return nil, fmt.Errorf("response data has no element at %q", at)
+			}
+			data = array[index]
+		default:
+			return nil, fmt.Errorf("response data has no field at %q", at)
+		}
+	}
+	return data, nil
+}
+
+// firstByte returns the first non-whitespace byte of data
+func firstByte(data []byte) byte {
+	for _, b := range data {
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return b
+	}
+	return 0
+}
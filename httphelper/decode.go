@@ -0,0 +1,228 @@
+package httphelper
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/aeramu/apihelper/exception"
+)
+
+// CONTRACT_VIOLATION is the error code used when an upstream envelope breaks the standard format
+const CONTRACT_VIOLATION = "CONTRACT_VIOLATION"
+
+// DecodeResponse decodes a response envelope using the package configuration.
+// With WithStrictEnvelope enabled, envelopes drifting from the standard format
+// are rejected with a CONTRACT_VIOLATION exception.
+//
+// Parameters:
+//   - body: The raw response body
+//
+// Returns:
+//   - The decoded Response
+//   - An error if the body cannot be decoded or violates the strict envelope rules
+func DecodeResponse(body []byte) (Response, error) {
+	return std().DecodeResponse(body)
+}
+
+// DecodeResponse decodes a JSON response envelope
+func (h *Helper) DecodeResponse(body []byte) (Response, error) {
+	var raw rawResponse
+	dec := json.NewDecoder(bytes.NewReader(body))
+	if h.cfg.strictEnvelope {
+		dec.DisallowUnknownFields()
+	}
+	err := dec.Decode(&raw)
+	resp := raw.response()
+	resp.raw = bytes.Clone(body)
+	return h.checkDecoded(resp, err)
+}
+
+// DecodeResponseAs decodes a response envelope of the given content type
+// using the configured codecs. The decoded Data can be read with ReadData.
+//
+// Parameters:
+//   - contentType: The Content-Type header of the response
+//   - body: The raw response body
+//
+// Returns:
+//   - The decoded Response
+//   - An error if no codec handles contentType or the body cannot be decoded
+func DecodeResponseAs(contentType string, body []byte) (Response, error) {
+	return std().DecodeResponseAs(contentType, body)
+}
+
+// DecodeResponseAs decodes a response envelope of the given content type
+func (h *Helper) DecodeResponseAs(contentType string, body []byte) (Response, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return Response{}, fmt.Errorf("invalid content type %q: %w", contentType, err)
+	}
+	if mediaType == ContentTypeJSON {
+		return h.DecodeResponse(body)
+	}
+	c, ok := h.codec(mediaType)
+	if !ok {
+		return Response{}, fmt.Errorf("no codec registered for content type %q", mediaType)
+	}
+
+	var resp Response
+	err = c.Unmarshal(body, &resp)
+	resp.raw = bytes.Clone(body)
+	return h.checkDecoded(resp, err)
+}
+
+// FromHTTPResponse reads and closes the body of resp and decodes its
+// envelope, using the codec registered for its Content-Type or JSON
+// otherwise. Bodies that are not an envelope, such as proxy error pages,
+// yield a Response carrying only the HTTP status code, whose error is
+// UNKNOWN_ERROR. With WithStrictEnvelope enabled, envelope violations are
+// returned as errors instead.
+//
+// Example usage:
+//
+//	resp, err := http.Get(url)
+//	if err != nil {
+//	    return err
+//	}
+//	result, err := httphelper.FromHTTPResponse(resp)
+//	if err != nil {
+//	    return err
+//	}
+//	if err := result.Err(); err != nil {
+//	    return err
+//	}
+//	user, err := httphelper.ReadData[User](result)
+//
+// Parameters:
+//   - resp: The HTTP response to decode
+//
+// Returns:
+//   - The decoded Response
+//   - An error if the body cannot be read or exceeds the maximum response size
+func FromHTTPResponse(resp *http.Response) (Response, error) {
+	return std().FromHTTPResponse(resp)
+}
+
+// FromHTTPResponse reads, closes and decodes the body of resp
+func (h *Helper) FromHTTPResponse(resp *http.Response) (Response, error) {
+	defer resp.Body.Close()
+	limit := h.cfg.maxResponseSize
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return Response{}, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if int64(len(body)) > limit {
+		return Response{}, fmt.Errorf("response body exceeds %d bytes", limit)
+	}
+
+	var result Response
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if _, ok := h.codec(mediaType); ok && mediaType != ContentTypeJSON {
+		result, err = h.DecodeResponseAs(mediaType, body)
+	} else {
+		result, err = h.DecodeResponse(body)
+	}
+	if err != nil && h.cfg.strictEnvelope {
+		return result, err
+	}
+	if err != nil || result.Status == 0 {
+		result = Response{Status: resp.StatusCode, raw: body}
+	}
+	return result, nil
+}
+
+// ParseResponse decodes a response envelope and returns its data as T in
+// one call, for plain net/http clients. Failed envelopes are converted into
+// exceptions keeping their code and message, with the status mapped by
+// exception.StatusFromHTTP. Bodies that are not an envelope fail with
+// UNKNOWN_ERROR, and a successful envelope without data yields the zero T.
+//
+// Example usage:
+//
+//	resp, err := http.Get(url)
+//	if err != nil {
+//	    return err
+//	}
+//	defer resp.Body.Close()
+//	body, err := io.ReadAll(resp.Body)
+//	if err != nil {
+//	    return err
+//	}
+//	user, err := httphelper.ParseResponse[User](resp.StatusCode, body)
+//
+// Parameters:
+//   - statusCode: The HTTP status code of the response
+//   - body: The raw response body
+//
+// Returns:
+//   - The response data
+//   - An exception if the response is an error, or an error if decoding fails
+func ParseResponse[T any](statusCode int, body []byte) (T, error) {
+	var data T
+	h := std()
+	result, err := h.DecodeResponse(body)
+	if err != nil && h.cfg.strictEnvelope {
+		return data, err
+	}
+	if err != nil || result.Status == 0 {
+		result = Response{Status: statusCode, raw: body}
+	}
+	if err := result.Err(); err != nil {
+		var respErr *ResponseError
+		if errors.As(err, &respErr) {
+			return data, respErr.Exception()
+		}
+		return data, err
+	}
+	if result.Data == nil {
+		return data, nil
+	}
+	err = readData(result, &data, h.cfg.strictDecoding)
+	return data, err
+}
+
+// checkDecoded applies the strict envelope rules to a decoded response
+func (h *Helper) checkDecoded(resp Response, err error) (Response, error) {
+	if err != nil {
+		if h.cfg.strictEnvelope {
+			return resp, contractViolation(err.Error())
+		}
+		return resp, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if h.cfg.strictEnvelope {
+		if err := validateEnvelope(resp); err != nil {
+			return resp, err
+		}
+	}
+	return resp, nil
+}
+
+// validateEnvelope checks the consistency of a decoded envelope.
+// Data alongside an error is only allowed for soft errors (status 200).
+func validateEnvelope(resp Response) error {
+	switch {
+	case resp.Success && resp.ErrorInfo != nil:
+		return contractViolation("successful response contains an error")
+	case resp.Success && resp.Status >= http.StatusBadRequest:
+		return contractViolation(fmt.Sprintf("successful response has error status %d", resp.Status))
+	case !resp.Success && resp.ErrorInfo == nil:
+		return contractViolation("failed response does not contain an error")
+	case !resp.Success && resp.Data != nil && resp.Status != http.StatusOK:
+		return contractViolation("failed response contains both data and error")
+	}
+	return nil
+}
+
+func contractViolation(reason string) error {
+	return exception.New("upstream envelope violates the contract: "+reason,
+		exception.WithStatus(exception.StatusThirdParty),
+		exception.WithCode(CONTRACT_VIOLATION),
+		exception.WithMessage("upstream response does not follow the standard envelope"),
+	)
+}
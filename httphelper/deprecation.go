@@ -0,0 +1,197 @@
+package httphelper
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ENDPOINT_RETIRED is the error code returned for deprecated routes after their sunset date
+const ENDPOINT_RETIRED = "ENDPOINT_RETIRED"
+
+// Deprecation describes a deprecated route or query parameter
+type Deprecation struct {
+	// Method is the HTTP method of the route, empty matches any method
+	Method string
+	// Path is the route path, a trailing "*" matches any path with that prefix
+	Path string
+	// Param is the deprecated query parameter, empty deprecates the whole route
+	Param string
+	// DeprecatedAt is when the deprecation took effect
+	DeprecatedAt time.Time
+	// Sunset is when the route or parameter stops being supported
+	Sunset time.Time
+	// Link points to the migration guide (optional)
+	Link string
+	// Message is the human-readable warning sent to consumers
+	Message string
+	// Enforce rejects requests after the sunset date instead of only warning
+	Enforce bool
+}
+
+// matches reports whether the deprecation applies to the request
+func (d Deprecation) matches(r *http.Request) bool {
+	if d.Method != "" && d.Method != r.Method {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(d.Path, "*"); ok {
+		if !strings.HasPrefix(r.URL.Path, prefix) {
+			return false
+		}
+	} else if d.Path != r.URL.Path {
+		return false
+	}
+	return d.Param == "" || r.URL.Query().Has(d.Param)
+}
+
+// DeprecationRegistry tracks deprecated routes and parameters and enforces
+// their sunset dates through its middleware
+type DeprecationRegistry struct {
+	mu           sync.RWMutex
+	deprecations []Deprecation
+	onUse        func(r *http.Request, d Deprecation)
+}
+
+// NewDeprecationRegistry creates a registry with the given deprecations
+func NewDeprecationRegistry(deprecations ...Deprecation) *DeprecationRegistry {
+	return &DeprecationRegistry{
+		deprecations: deprecations,
+	}
+}
+
+// Add registers a deprecation
+func (reg *DeprecationRegistry) Add(d Deprecation) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	reg.deprecations = append(reg.deprecations, d)
+}
+
+// OnUse sets a hook invoked whenever a request uses a deprecated route or
+// parameter, typically used to record metrics by consumer
+func (reg *DeprecationRegistry) OnUse(hook func(r *http.Request, d Deprecation)) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	reg.onUse = hook
+}
+
+// Middleware sets the Deprecation, Sunset and Link headers on requests using
+// deprecated routes or parameters and adds a warning to the response envelope.
+// After the sunset date, enforced deprecations are rejected with a 410 Gone
+// ENDPOINT_RETIRED error instead of reaching the handler.
+func (reg *DeprecationRegistry) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reg.mu.RLock()
+		var matched []Deprecation
+		for _, d := range reg.deprecations {
+			if d.matches(r) {
+				matched = append(matched, d)
+			}
+		}
+		onUse := reg.onUse
+		reg.mu.RUnlock()
+
+		if len(matched) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		now := time.Now()
+		warnings := make([]ErrorInfo, 0, len(matched))
+		for _, d := range matched {
+			if onUse != nil {
+				onUse(r, d)
+			}
+			if d.Enforce && !d.Sunset.IsZero() && now.After(d.Sunset) {
+				writeError(w, r, retiredError{message: d.Message})
+				return
+			}
+
+			if !d.DeprecatedAt.IsZero() {
+				w.Header().Set("Deprecation", "@"+strconv.FormatInt(d.DeprecatedAt.Unix(), 10))
+			} else {
+				w.Header().Set("Deprecation", "true")
+			}
+			if !d.Sunset.IsZero() {
+				w.Header().Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+			}
+			if d.Link != "" {
+				w.Header().Add("Link", "<"+d.Link+`>; rel="deprecation"`)
+			}
+			warnings = append(warnings, ErrorInfo{
+				Code:    "DEPRECATED",
+				Message: d.Message,
+				Help:    d.Link,
+			})
+		}
+
+		next.ServeHTTP(&warningResponseWriter{
+			ResponseWriter: w,
+			warnings:       warnings,
+		}, r)
+	})
+}
+
+// retiredError is returned for enforced deprecations after their sunset date
+type retiredError struct {
+	message string
+}
+
+func (e retiredError) Error() string {
+	return "endpoint retired: " + e.message
+}
+
+func (e retiredError) HTTPStatus() int {
+	return http.StatusGone
+}
+
+func (e retiredError) Message() string {
+	if e.message == "" {
+		return "this endpoint has been retired"
+	}
+	return e.message
+}
+
+func (e retiredError) Code() string {
+	return ENDPOINT_RETIRED
+}
+
+// warningResponseWriter carries warnings added by middlewares to the writers
+type warningResponseWriter struct {
+	http.ResponseWriter
+	warnings []ErrorInfo
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (w *warningResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// unwrapWriter walks the chain of wrapped ResponseWriters and returns the first of type T
+func unwrapWriter[T http.ResponseWriter](w http.ResponseWriter) (T, bool) {
+	for {
+		if t, ok := w.(T); ok {
+			return t, true
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			var zero T
+			return zero, false
+		}
+		w = u.Unwrap()
+	}
+}
+
+// middlewareWarnings collects the warnings added by middlewares wrapping w
+func middlewareWarnings(w http.ResponseWriter) []ErrorInfo {
+	var warnings []ErrorInfo
+	for {
+		ww, ok := unwrapWriter[*warningResponseWriter](w)
+		if !ok {
+			return warnings
+		}
+		warnings = append(warnings, ww.warnings...)
+		w = ww.ResponseWriter
+	}
+}
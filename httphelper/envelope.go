@@ -0,0 +1,38 @@
+package httphelper
+
+// Envelope builds the bodies written by OK, Error and the other writers.
+// Implementing it lets organizations with a pre-existing response contract
+// swap the wire format without forking the package. The writers still set
+// the HTTP status code from Response.Status.
+//
+// Example usage:
+//
+//	type legacyEnvelope struct{}
+//
+//	func (legacyEnvelope) BuildSuccess(resp httphelper.Response) any {
+//	    return map[string]any{"result": resp.Data}
+//	}
+//
+//	func (legacyEnvelope) BuildError(resp httphelper.Response, err error) any {
+//	    return map[string]any{"errorCode": resp.Code(), "errorMessage": resp.Message()}
+//	}
+//
+//	httphelper.Configure(httphelper.WithEnvelope(legacyEnvelope{}))
+type Envelope interface {
+	// BuildSuccess returns the body of a successful response
+	BuildSuccess(resp Response) any
+	// BuildError returns the body of an error response.
+	// err is the original error passed to Error.
+	BuildError(resp Response, err error) any
+}
+
+// defaultEnvelope writes the Response struct as is
+type defaultEnvelope struct{}
+
+func (defaultEnvelope) BuildSuccess(resp Response) any {
+	return resp
+}
+
+func (defaultEnvelope) BuildError(resp Response, _ error) any {
+	return resp
+}
@@ -0,0 +1,80 @@
+package httphelper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// ETagFunc computes the entity tag of the response data, without quotes
+type ETagFunc func(data any) string
+
+// ContentETag computes the entity tag from the SHA-256 hash of the JSON
+// encoded data. It is the ETagFunc used when OKCached is given nil.
+func ContentETag(data any) string {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:16])
+}
+
+// OKCached writes a successful JSON response like OK, with an ETag computed
+// by etagFunc. If the request If-None-Match header matches the ETag, a 304
+// Not Modified response without body is written instead.
+func (h *Helper) OKCached(w http.ResponseWriter, r *http.Request, data any, etagFunc ETagFunc, opts ...WriteOption) {
+	if etagFunc == nil {
+		etagFunc = ContentETag
+	}
+	tag := etagFunc(data)
+	if tag == "" {
+		h.OK(w, data, opts...)
+		return
+	}
+	etag := `"` + tag + `"`
+	w.Header().Set("ETag", etag)
+
+	if etagMatch(r.Header.Get("If-None-Match"), etag) {
+		h.applySuccessOptions(w, opts)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	h.OK(w, data, opts...)
+}
+
+// etagMatch reports whether the If-None-Match header matches etag using the
+// weak comparison required for If-None-Match
+func etagMatch(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// OKCached writes a successful JSON response with an ETag, answering
+// requests whose If-None-Match header matches it with 304 Not Modified.
+//
+// Example usage:
+//
+//	httphelper.OKCached(w, r, product, func(data any) string {
+//	    return strconv.FormatInt(product.Version, 10)
+//	})
+//
+// Parameters:
+//   - w: The HTTP response writer
+//   - r: The HTTP request
+//   - data: The data to include in the response
+//   - etagFunc: The function computing the ETag, nil to hash the data
+//   - opts: Options customizing the response headers, such as WithCacheControl
+func OKCached(w http.ResponseWriter, r *http.Request, data any, etagFunc ETagFunc, opts ...WriteOption) {
+	std().OKCached(w, r, data, etagFunc, opts...)
+}
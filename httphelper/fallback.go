@@ -0,0 +1,56 @@
+package httphelper
+
+import (
+	"context"
+	"net/http"
+)
+
+// DEGRADED is the warning code added to responses served from a fallback value
+const DEGRADED = "DEGRADED"
+
+// Fallback decides whether err degrades to a successful response.
+// It returns the value to respond with and true to degrade, or false to
+// write the error response as usual. ctx is the context of the request
+// being served, context.Background when Error cannot tell it.
+//
+// Example usage:
+//
+//	httphelper.Configure(httphelper.WithFallback(func(ctx context.Context, err error) (any, bool) {
+//	    if exception.HasStatus(err, exception.StatusUnavailable) {
+//	        return []Recommendation{}, true
+//	    }
+//	    return nil, false
+//	}))
+type Fallback func(ctx context.Context, err error) (any, bool)
+
+// degrade writes the fallback value for err, if any, with a DEGRADED warning.
+// Degraded responses are never cached, so clients and proxies pick up the
+// real value once the failure is over. It reports whether the response was written.
+func (h *Helper) degrade(ctx context.Context, w http.ResponseWriter, err error, opts []WriteOption) bool {
+	if h.cfg.fallback == nil {
+		return false
+	}
+	data, ok := h.cfg.fallback(ctx, err)
+	if !ok {
+		return false
+	}
+	if h.cfg.onDegraded != nil {
+		h.cfg.onDegraded(ctx, err)
+	}
+
+	errInfo, _ := h.errorInfo(err)
+	h.applySuccessOptions(w, opts)
+	w.Header().Set("Cache-Control", "no-store")
+	h.writeResponse(w, Response{
+		Status:  http.StatusOK,
+		Success: true,
+		Data:    data,
+		Warnings: []ErrorInfo{{
+			Code:    DEGRADED,
+			Message: errInfo.Message,
+			Detail:  errInfo.Detail,
+			Details: map[string]any{"code": errInfo.Code},
+		}},
+	}, nil)
+	return true
+}
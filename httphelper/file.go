@@ -0,0 +1,197 @@
+package httphelper
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/aeramu/apihelper/exception"
+)
+
+const (
+	// RANGE_NOT_SATISFIABLE is the error code used when the requested range is outside the file
+	RANGE_NOT_SATISFIABLE = "RANGE_NOT_SATISFIABLE"
+	// PRECONDITION_FAILED is the error code used when a conditional request header does not match
+	PRECONDITION_FAILED = "PRECONDITION_FAILED"
+)
+
+// fileOptions configures File
+type fileOptions struct {
+	inline      bool
+	contentType string
+	size        int64
+	modTime     time.Time
+}
+
+// FileOption represents an option for File
+type FileOption func(*fileOptions)
+
+// WithInline displays the file in the browser instead of downloading it
+func WithInline() FileOption {
+	return func(o *fileOptions) {
+		o.inline = true
+	}
+}
+
+// WithFileContentType sets the content type instead of detecting it
+func WithFileContentType(contentType string) FileOption {
+	return func(o *fileOptions) {
+		o.contentType = contentType
+	}
+}
+
+// WithFileSize sets the Content-Length of content that cannot seek
+func WithFileSize(size int64) FileOption {
+	return func(o *fileOptions) {
+		o.size = size
+	}
+}
+
+// WithModTime sets the Last-Modified time used for conditional requests
+func WithModTime(t time.Time) FileOption {
+	return func(o *fileOptions) {
+		o.modTime = t
+	}
+}
+
+// File writes content as a file attachment named name.
+// The content type is detected from the name extension or, failing that,
+// from the first bytes of content. Content implementing io.ReadSeeker is
+// served with http.ServeContent, which adds Content-Length, range and
+// conditional request support; its 412 and 416 answers are written as
+// error responses like Error does.
+//
+// If reading content fails before any byte was sent, an error response is
+// written instead. Failures after that cannot change the response and are
+// only returned; with a known size the client sees a truncated body.
+//
+// Example usage:
+//
+//	f, err := storage.Open(ctx, report.Path)
+//	if err != nil {
+//	    httphelper.Error(w, err)
+//	    return
+//	}
+//	defer f.Close()
+//	httphelper.File(w, r, "report.csv", f)
+//
+// Parameters:
+//   - w: The HTTP response writer
+//   - r: The HTTP request
+//   - name: The file name suggested to the client
+//   - content: The file content
+//   - opts: Options such as WithInline or WithFileContentType
+//
+// Returns:
+//   - An error if reading content or writing to w fails
+func File(w http.ResponseWriter, r *http.Request, name string, content io.Reader, opts ...FileOption) error {
+	var o fileOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	disposition := "attachment"
+	if o.inline {
+		disposition = "inline"
+	}
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": name}))
+	if o.contentType == "" {
+		o.contentType = mime.TypeByExtension(filepath.Ext(name))
+	}
+	if o.contentType != "" {
+		w.Header().Set("Content-Type", o.contentType)
+	}
+
+	if rs, ok := content.(io.ReadSeeker); ok {
+		http.ServeContent(&serveContentWriter{ResponseWriter: w, request: r}, r, name, o.modTime, rs)
+		return nil
+	}
+
+	// Read the first chunk before writing the headers, so an unreadable
+	// content still results in an error response
+	head := make([]byte, 512)
+	n, err := io.ReadFull(content, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		w.Header().Del("Content-Disposition")
+		w.Header().Del("Content-Type")
+		writeError(w, r, err)
+		return err
+	}
+	head = head[:n]
+
+	if o.contentType == "" {
+		w.Header().Set("Content-Type", http.DetectContentType(head))
+	}
+	if o.size > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(o.size, 10))
+	}
+	if !o.modTime.IsZero() {
+		w.Header().Set("Last-Modified", o.modTime.UTC().Format(http.TimeFormat))
+	}
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodHead {
+		return nil
+	}
+
+	if _, err := w.Write(head); err != nil {
+		return err
+	}
+	_, err = io.Copy(w, content)
+	return err
+}
+
+// serveContentWriter replaces the plain text error responses written by
+// http.ServeContent with error responses in the configured format
+type serveContentWriter struct {
+	http.ResponseWriter
+	request *http.Request
+	failed  bool
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (w *serveContentWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *serveContentWriter) WriteHeader(status int) {
+	if status < http.StatusBadRequest {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	w.failed = true
+	w.Header().Del("Content-Disposition")
+	writeError(w.ResponseWriter, w.request, serveContentError(status))
+}
+
+func (w *serveContentWriter) Write(b []byte) (int, error) {
+	if w.failed {
+		// discard the plain text body of the error
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// serveContentError returns the error matching a status written by http.ServeContent
+func serveContentError(status int) error {
+	switch status {
+	case http.StatusRequestedRangeNotSatisfiable:
+		return &limitError{
+			code:       RANGE_NOT_SATISFIABLE,
+			httpStatus: status,
+			message:    "requested range not satisfiable",
+			detail:     "requested range is outside the file",
+		}
+	case http.StatusPreconditionFailed:
+		return &limitError{
+			code:       PRECONDITION_FAILED,
+			httpStatus: status,
+			message:    "precondition failed",
+			detail:     "conditional request headers do not match the file",
+		}
+	}
+	return exception.New("failed to serve file content: " + http.StatusText(status))
+}
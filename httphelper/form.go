@@ -0,0 +1,131 @@
+package httphelper
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/aeramu/apihelper/exception"
+)
+
+// maxFormMemory is the part of multipart bodies kept in memory, the rest is stored in temporary files
+const maxFormMemory = 10 << 20
+
+// BindForm maps the fields of a URL-encoded or multipart form body onto the
+// fields of T tagged with `form:"name"`, converting them like BindQuery.
+// Bodies larger than the size set with WithMaxFormSize are rejected with a
+// 413 PAYLOAD_TOO_LARGE error, and invalid fields are reported together in a
+// VALIDATION_FAILED exception with one detail per field.
+//
+// Example usage:
+//
+//	type profileForm struct {
+//	    Name string `form:"name,required"`
+//	    Age  int    `form:"age"`
+//	}
+//
+//	f, err := httphelper.BindForm[profileForm](r)
+//
+// Parameters:
+//   - r: The HTTP request
+//
+// Returns:
+//   - The bound value
+//   - An error if the body cannot be parsed or a field is invalid
+func BindForm[T any](r *http.Request) (T, error) {
+	var target T
+	if err := parseForm(r, std().cfg.maxFormSize); err != nil {
+		return target, err
+	}
+	invalid := bind(&target, "form", func(name string) []string {
+		return r.PostForm[name]
+	})
+	if len(invalid) > 0 {
+		return target, bindError(invalid, exception.StatusValidationFailed, exception.CodeValidationFailed, "invalid form fields")
+	}
+	return target, nil
+}
+
+// Files returns the files uploaded in the multipart form field.
+// A missing field is reported as a VALIDATION_FAILED exception, and bodies
+// larger than the size set with WithMaxFormSize as a 413 PAYLOAD_TOO_LARGE error.
+//
+// Example usage:
+//
+//	files, err := httphelper.Files(r, "attachments")
+//	if err != nil {
+//	    httphelper.Error(w, err)
+//	    return
+//	}
+//	for _, fh := range files {
+//	    f, err := fh.Open()
+//	    ...
+//	}
+//
+// Parameters:
+//   - r: The HTTP request
+//   - field: The name of the form field
+//
+// Returns:
+//   - The uploaded files
+//   - An error if the body cannot be parsed or the field has no file
+func Files(r *http.Request, field string) ([]*multipart.FileHeader, error) {
+	if err := parseForm(r, std().cfg.maxFormSize); err != nil {
+		return nil, err
+	}
+	if r.MultipartForm == nil {
+		return nil, exception.New("request body is not a multipart form",
+			exception.WithStatus(exception.StatusInvalidRequest),
+			exception.WithCode(exception.CodeInvalidRequest),
+			exception.WithMessage("request body must be multipart/form-data"),
+		)
+	}
+
+	files := r.MultipartForm.File[field]
+	if len(files) == 0 {
+		return nil, bindError(map[string]string{field: "is required"},
+			exception.StatusValidationFailed, exception.CodeValidationFailed, "missing file")
+	}
+	return files, nil
+}
+
+// payloadTooLarge returns the error for request bodies exceeding limit bytes
+func payloadTooLarge(limit int64) error {
+	return &limitError{
+		code:       PAYLOAD_TOO_LARGE,
+		httpStatus: http.StatusRequestEntityTooLarge,
+		message:    "request body is too large",
+		detail:     fmt.Sprintf("request body exceeds %d bytes", limit),
+	}
+}
+
+// parseForm parses the form body of r, limiting its size to maxSize bytes
+func parseForm(r *http.Request, maxSize int64) error {
+	if r.PostForm != nil {
+		return nil
+	}
+
+	r.Body = http.MaxBytesReader(nil, r.Body, maxSize)
+	var err error
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		err = r.ParseMultipartForm(min(maxSize, maxFormMemory))
+	} else {
+		err = r.ParseForm()
+	}
+	if err == nil {
+		return nil
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return payloadTooLarge(maxBytesErr.Limit)
+	}
+	return exception.Wrap(err, "failed to parse form body",
+		exception.WithStatus(exception.StatusInvalidRequest),
+		exception.WithCode(exception.CodeInvalidRequest),
+		exception.WithMessage("invalid form body"),
+	)
+}
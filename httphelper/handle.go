@@ -0,0 +1,165 @@
+package httphelper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+
+	"github.com/aeramu/apihelper/exception"
+)
+
+// HandlerFunc is a typed handler receiving the bound request and returning
+// the response data or an error. It implements TypedHandler, so it can be
+// registered on a Router directly.
+type HandlerFunc[Req, Resp any] func(ctx context.Context, req Req) (Resp, error)
+
+// TypedHandler is an http.Handler exposing its request and response types,
+// which tooling such as documentation generators reads from the Router
+type TypedHandler interface {
+	http.Handler
+	// RequestType returns the type the request is bound into
+	RequestType() reflect.Type
+	// ResponseType returns the type of the response data
+	ResponseType() reflect.Type
+}
+
+// Typed returns fn as a HandlerFunc, inferring its request and response types
+func Typed[Req, Resp any](fn func(ctx context.Context, req Req) (Resp, error)) HandlerFunc[Req, Resp] {
+	return fn
+}
+
+// ServeHTTP binds the request, calls fn and writes its result
+func (fn HandlerFunc[Req, Resp]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w = withRequest(w, r)
+	req, err := bindRequest[Req](r)
+	if err != nil {
+		ErrorContext(ctx, w, err)
+		return
+	}
+	resp, err := fn(ctx, req)
+	if err != nil {
+		ErrorContext(ctx, w, err)
+		return
+	}
+	OKContext(ctx, w, resp)
+}
+
+// RequestType returns the type the request is bound into
+func (fn HandlerFunc[Req, Resp]) RequestType() reflect.Type {
+	return reflect.TypeOf((*Req)(nil)).Elem()
+}
+
+// ResponseType returns the type of the response data
+func (fn HandlerFunc[Req, Resp]) ResponseType() reflect.Type {
+	return reflect.TypeOf((*Resp)(nil)).Elem()
+}
+
+// Handle adapts a typed handler to an http.HandlerFunc.
+// The request is bound into Req from the JSON or form body, the query
+// parameters tagged `query:"name"` and the path parameters tagged
+// `path:"name"`, in that order. The handler result is written with OK, and
+// binding or handler errors with ErrorContext, so every endpoint uses the
+// standard envelope.
+//
+// Example usage:
+//
+//	type getOrderRequest struct {
+//	    OrderID int64 `path:"order_id,required"`
+//	}
+//
+//	mux.HandleFunc("GET /orders/{order_id}", httphelper.Handle(func(ctx context.Context, req getOrderRequest) (Order, error) {
+//	    return orders.Get(ctx, req.OrderID)
+//	}))
+func Handle[Req, Resp any](fn func(ctx context.Context, req Req) (Resp, error)) http.HandlerFunc {
+	return HandlerFunc[Req, Resp](fn).ServeHTTP
+}
+
+// bindRequest binds the body, query and path parameters of r into Req
+func bindRequest[Req any](r *http.Request) (Req, error) {
+	var req Req
+	isStruct := reflect.TypeOf(req) != nil && reflect.TypeOf(req).Kind() == reflect.Struct
+	if err := bindBody(r, &req, isStruct); err != nil {
+		return req, err
+	}
+	if !isStruct {
+		return req, nil
+	}
+
+	query := r.URL.Query()
+	invalid := bind(&req, "query", func(name string) []string {
+		return query[name]
+	})
+	if len(invalid) > 0 {
+		return req, bindError(invalid, exception.StatusValidationFailed, exception.CodeValidationFailed, "invalid query parameters")
+	}
+
+	extract := std().cfg.pathExtractor
+	invalid = bind(&req, "path", func(name string) []string {
+		if value := extract(r, name); value != "" {
+			return []string{value}
+		}
+		return nil
+	})
+	if len(invalid) > 0 {
+		return req, bindError(invalid, exception.StatusInvalidRequest, exception.CodeInvalidRequest, "invalid path parameters")
+	}
+	return req, nil
+}
+
+// bindBody decodes the JSON body of r into target, or the form body when
+// target points to a struct
+func bindBody(r *http.Request, target any, isStruct bool) error {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/x-www-form-urlencoded", "multipart/form-data":
+		if !isStruct {
+			return nil
+		}
+		if err := parseForm(r, std().cfg.maxFormSize); err != nil {
+			return err
+		}
+		invalid := bind(target, "form", func(name string) []string {
+			return r.PostForm[name]
+		})
+		if len(invalid) > 0 {
+			return bindError(invalid, exception.StatusValidationFailed, exception.CodeValidationFailed, "invalid form fields")
+		}
+		return nil
+	}
+
+	cfg := std().cfg
+	dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, cfg.maxFormSize))
+	var err error
+	if cfg.strictDecoding {
+		var raw json.RawMessage
+		if err = dec.Decode(&raw); err == nil {
+			if err := checkFields(raw, target); err != nil {
+				return err
+			}
+			err = json.Unmarshal(raw, target)
+		}
+	} else {
+		err = dec.Decode(target)
+	}
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil
+	}
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return payloadTooLarge(maxBytesErr.Limit)
+	}
+	return exception.Wrap(err, "failed to decode request body",
+		exception.WithStatus(exception.StatusInvalidRequest),
+		exception.WithCode(exception.CodeInvalidRequest),
+		exception.WithMessage("invalid request body"),
+	)
+}
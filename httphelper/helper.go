@@ -0,0 +1,281 @@
+package httphelper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+
+	"github.com/aeramu/apihelper/exception"
+)
+
+// Helper writes responses using its own configuration instead of the
+// package-level one set by Configure, so several services (or a service and
+// its tests) in the same process can use different settings.
+//
+// Example usage:
+//
+//	helper := httphelper.New(
+//	    httphelper.WithDefaultErrorCode("ORDERS_ERROR"),
+//	    httphelper.WithIncludeDetails(false),
+//	)
+//	helper.OK(w, data)
+type Helper struct {
+	cfg config
+}
+
+// New creates a Helper with the given options applied on top of the defaults
+func New(opts ...Option) *Helper {
+	cfg := initialConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Helper{cfg: cfg}
+}
+
+// std returns a Helper using a snapshot of the package-level configuration
+func std() *Helper {
+	return defaultHelper.Load()
+}
+
+// OK writes a successful JSON response with the provided data
+func (h *Helper) OK(w http.ResponseWriter, data any, opts ...WriteOption) {
+	h.writeSuccess(w, http.StatusOK, data, opts)
+}
+
+// Created writes a 201 Created JSON response with the provided data
+func (h *Helper) Created(w http.ResponseWriter, data any, opts ...WriteOption) {
+	h.writeSuccess(w, http.StatusCreated, data, opts)
+}
+
+// Accepted writes a 202 Accepted JSON response with the provided data
+func (h *Helper) Accepted(w http.ResponseWriter, data any, opts ...WriteOption) {
+	h.writeSuccess(w, http.StatusAccepted, data, opts)
+}
+
+// NoContent writes a 204 No Content response
+func (h *Helper) NoContent(w http.ResponseWriter, opts ...WriteOption) {
+	h.applySuccessOptions(w, opts)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// OKWithPage writes a successful JSON response with the provided list data
+// and its pagination information
+func (h *Helper) OKWithPage(w http.ResponseWriter, data any, page Page, opts ...WriteOption) {
+	h.applySuccessOptions(w, opts)
+	h.writeResponse(w, Response{
+		Status:     http.StatusOK,
+		Success:    true,
+		Data:       data,
+		Pagination: &page,
+	}, nil)
+}
+
+// OKWithWarnings writes a successful JSON response with the provided data
+// and the non-fatal issues that occurred while producing it
+func (h *Helper) OKWithWarnings(w http.ResponseWriter, data any, warnings []ErrorInfo, opts ...WriteOption) {
+	h.applySuccessOptions(w, opts)
+	h.writeResponse(w, Response{
+		Status:   http.StatusOK,
+		Success:  true,
+		Data:     data,
+		Warnings: warnings,
+	}, nil)
+}
+
+// Error writes an error response in JSON format.
+// It handles both standard errors and custom errors implementing the HTTPError interface.
+// The context of the request served by w, when it is known, e.g. under a
+// Router, is passed to the Fallback and its configuration overrides are
+// applied, use ErrorContext to pass it explicitly.
+func (h *Helper) Error(w http.ResponseWriter, err error, opts ...WriteOption) {
+	ctx := context.Background()
+	if rw, ok := unwrapWriter[*requestResponseWriter](w); ok {
+		ctx = rw.request.Context()
+	}
+	h.forContext(ctx).error(ctx, w, err, opts)
+}
+
+// error writes the error response, or the fallback value when the configured
+// Fallback degrades err
+func (h *Helper) error(ctx context.Context, w http.ResponseWriter, err error, opts []WriteOption) {
+	err = clientClosed(ctx, err)
+	if h.degrade(ctx, w, err, opts) {
+		return
+	}
+	for _, opt := range opts {
+		opt(w.Header())
+	}
+	if h.cfg.problemDetails {
+		status := h.writeProblem(w, err)
+		runErrorHooks(w, err, status)
+		return
+	}
+
+	errInfo, httpStatus := h.errorInfo(err)
+	if message, ok := h.localize(w, err, errInfo.Code); ok {
+		errInfo.Message = message
+	}
+
+	var data any
+	var dataErr dataError
+	if errors.As(err, &dataErr) {
+		data = dataErr.Data()
+	}
+
+	status := h.writeResponse(w, Response{
+		Status:    httpStatus,
+		Success:   false,
+		Data:      data,
+		ErrorInfo: &errInfo,
+	}, err)
+	runErrorHooks(w, err, status)
+}
+
+// errorInfo builds the ErrorInfo of err and returns it with the HTTP status code
+func (h *Helper) errorInfo(err error) (ErrorInfo, int) {
+	var errInfo ErrorInfo
+	var httpStatus int
+	detail := err.Error()
+	if httpErr, ok := AsHTTPError(err); ok {
+		errInfo = ErrorInfo{
+			Code:    httpErr.Code(),
+			Message: httpErr.Message(),
+		}
+		detail = httpErr.Error()
+		httpStatus = httpErr.HTTPStatus()
+	} else {
+		errInfo = ErrorInfo{
+			Code:    h.cfg.defaultErrorCode,
+			Message: h.cfg.defaultErrorMessage,
+		}
+		httpStatus = http.StatusInternalServerError
+	}
+	if status, ok := h.cfg.statusMapping[errInfo.Code]; ok {
+		httpStatus = status
+	}
+	if h.includeDetail(httpStatus) {
+		errInfo.Detail = detail
+	}
+	if template, ok := h.cfg.messageTemplates[errInfo.Code]; ok {
+		errInfo.Message = template
+	}
+	var detailsErr detailsError
+	if errors.As(err, &detailsErr) && len(detailsErr.Details()) > 0 {
+		errInfo.Details = detailsErr.Details()
+		errInfo.Message = exception.RenderMessage(errInfo.Message, detailsErr.Details())
+	}
+	var fieldErr fieldErrorsError
+	if errors.As(err, &fieldErr) && len(fieldErr.FieldErrors()) > 0 {
+		errInfo.Details = fieldErr.FieldErrors()
+	}
+	var helpErr helpError
+	if errors.As(err, &helpErr) {
+		errInfo.Help = helpErr.HelpURL()
+	}
+
+	return errInfo, httpStatus
+}
+
+// ReadData unmarshals the response Data field into target, which must be a pointer.
+// It is the Helper counterpart of the generic ReadData function.
+func (h *Helper) ReadData(r Response, target any) error {
+	return readData(r, target, h.cfg.strictDecoding)
+}
+
+// writeSuccess writes a successful JSON response with the given status code
+func (h *Helper) writeSuccess(w http.ResponseWriter, status int, data any, opts []WriteOption) {
+	h.applySuccessOptions(w, opts)
+	h.writeResponse(w, Response{
+		Status:  status,
+		Success: true,
+		Data:    data,
+	}, nil)
+}
+
+// writeResponse writes the response using the configured envelope, in the
+// negotiated format, and returns the status written.
+// err is the error passed to Error, nil for successful responses.
+// When the body cannot be encoded, an internal error envelope is written
+// instead, and the error hooks are invoked for successful responses.
+// Error envelopes without request-specific content are written from a cache
+// of encoded bodies, so hot error paths skip marshaling.
+func (h *Helper) writeResponse(w http.ResponseWriter, resp Response, err error) int {
+	resp.Meta = h.withMeta(w, resp.Meta)
+	resp.Warnings = append(resp.Warnings, middlewareWarnings(w)...)
+	runResponseHooks(&resp)
+
+	var body any
+	if _, ok := unwrapWriter[*noEnvelopeResponseWriter](w); ok && resp.Success {
+		body = resp.Data
+	} else if resp.Success {
+		body = h.cfg.envelope.BuildSuccess(resp)
+	} else {
+		recordError(w, resp.ErrorInfo)
+		if h.writePrecomputedError(w, resp) {
+			return resp.Status
+		}
+		body = h.cfg.envelope.BuildError(resp, err)
+	}
+
+	encodeErr := h.encodeBody(w, resp.Status, body)
+	if encodeErr == nil {
+		return resp.Status
+	}
+
+	encodeErr = fmt.Errorf("encode response: %w", encodeErr)
+	errInfo, status := h.errorInfo(encodeErr)
+	fallback := Response{
+		Status:    status,
+		Success:   false,
+		ErrorInfo: &errInfo,
+	}
+	recordError(w, &errInfo)
+	if h.encodeBody(w, status, h.cfg.envelope.BuildError(fallback, encodeErr)) != nil {
+		h.encodeBody(w, status, fallback)
+	}
+	if resp.Success {
+		runErrorHooks(w, encodeErr, status)
+	}
+	return status
+}
+
+// writeProblem writes the error as an RFC 7807 problem document and returns its status
+func (h *Helper) writeProblem(w http.ResponseWriter, err error) int {
+	problem := exception.ToProblem(err)
+	if _, ok := AsHTTPError(err); !ok {
+		problem.Title = h.cfg.defaultErrorMessage
+		problem.Extensions["code"] = h.cfg.defaultErrorCode
+	}
+	code, _ := problem.Extensions["code"].(string)
+	if status, ok := h.cfg.statusMapping[code]; ok {
+		problem.Status = status
+	}
+	if !h.includeDetail(problem.Status) {
+		problem.Detail = ""
+	}
+	if title, ok := h.localize(w, err, code); ok {
+		problem.Title = title
+	}
+
+	recordError(w, &ErrorInfo{Code: code, Message: problem.Title})
+
+	w.Header().Set("Content-Type", exception.ProblemContentType)
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+	return problem.Status
+}
+
+// includeDetail reports whether the error detail should be included for a
+// response with the given status, applying sampling to internal server errors.
+func (h *Helper) includeDetail(httpStatus int) bool {
+	if !h.cfg.includeDetails {
+		return false
+	}
+	if httpStatus != http.StatusInternalServerError {
+		return true
+	}
+	return rand.Float64() < h.cfg.internalDetailRate
+}
@@ -0,0 +1,139 @@
+package httphelper
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// errorHooks stores the hooks invoked whenever an error response is written
+var errorHooks hookList[func(r *http.Request, err error, status int)]
+
+// responseHooks stores the hooks invoked before an envelope is encoded
+var responseHooks hookList[func(resp *Response)]
+
+// hookList is a copy-on-write list of hooks. Registering and unregistering
+// replace the list as a whole, so writers can run the hooks concurrently.
+type hookList[F any] struct {
+	mu    sync.Mutex
+	hooks atomic.Pointer[[]*F]
+}
+
+// load returns the registered hooks, in registration order
+func (l *hookList[F]) load() []*F {
+	if hooks := l.hooks.Load(); hooks != nil {
+		return *hooks
+	}
+	return nil
+}
+
+// add registers hook and returns a function unregistering it
+func (l *hookList[F]) add(hook F) func() {
+	entry := &hook
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	hooks := append(append([]*F(nil), l.load()...), entry)
+	l.hooks.Store(&hooks)
+
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		var hooks []*F
+		for _, h := range l.load() {
+			if h != entry {
+				hooks = append(hooks, h)
+			}
+		}
+		l.hooks.Store(&hooks)
+	}
+}
+
+// OnError registers a hook that is invoked whenever Error, ErrorContext or
+// any other writer writes an error response, with the error and the HTTP
+// status written. Hooks run in registration order, which makes them a single
+// place to attach logging, metrics and alerting without wrapping every
+// handler. OnError returns a function unregistering the hook.
+//
+// The request is available for errors written by the middlewares of this
+// package, by Handle and by Router; it is nil when Error is called directly
+// from a handler that isn't served through them.
+//
+// Example usage:
+//
+//	httphelper.OnError(func(r *http.Request, err error, status int) {
+//	    errorsTotal.WithLabelValues(strconv.Itoa(status)).Inc()
+//	})
+func OnError(hook func(r *http.Request, err error, status int)) (unregister func()) {
+	if hook == nil {
+		return func() {}
+	}
+	return errorHooks.add(hook)
+}
+
+func runErrorHooks(w http.ResponseWriter, err error, status int) {
+	hooks := errorHooks.load()
+	if len(hooks) == 0 {
+		return
+	}
+	var r *http.Request
+	if rw, ok := unwrapWriter[*requestResponseWriter](w); ok {
+		r = rw.request
+	}
+	for _, hook := range hooks {
+		(*hook)(r, err, status)
+	}
+}
+
+// OnResponse registers a hook that may mutate every response envelope just
+// before it is encoded, for both successful and error responses, e.g. to
+// inject meta, mask fields or add warnings. Hooks run in registration order
+// after the meta and middleware warnings are attached. They are not applied
+// to problem details documents. OnResponse returns a function unregistering
+// the hook.
+//
+// Example usage:
+//
+//	httphelper.OnResponse(func(resp *httphelper.Response) {
+//	    if resp.Meta == nil {
+//	        resp.Meta = map[string]any{}
+//	    }
+//	    resp.Meta["region"] = region
+//	})
+func OnResponse(hook func(resp *Response)) (unregister func()) {
+	if hook == nil {
+		return func() {}
+	}
+	return responseHooks.add(hook)
+}
+
+func runResponseHooks(resp *Response) {
+	for _, hook := range responseHooks.load() {
+		(*hook)(resp)
+	}
+}
+
+// requestResponseWriter makes the request being served available to the
+// hooks invoked by writers
+type requestResponseWriter struct {
+	http.ResponseWriter
+	request *http.Request
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (w *requestResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// withRequest wraps w to carry r, unless it already does
+func withRequest(w http.ResponseWriter, r *http.Request) http.ResponseWriter {
+	if rw, ok := unwrapWriter[*requestResponseWriter](w); ok && rw.request == r {
+		return w
+	}
+	return &requestResponseWriter{ResponseWriter: w, request: r}
+}
+
+// writeError writes err for the request r with ErrorContext, making r
+// available to the error hooks
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	ErrorContext(r.Context(), withRequest(w, r), err)
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+
+	"github.com/aeramu/apihelper/exception"
 )
 
 // Package httphelper provides utilities for standardized HTTP response handling.
@@ -38,6 +40,32 @@ type HTTPError interface {
 	Code() string
 }
 
+// dataError is implemented by errors that carry a data payload,
+// such as soft errors returning partial results
+type dataError interface {
+	error
+	Data() any
+}
+
+// helpError is implemented by errors that link to their documentation
+type helpError interface {
+	error
+	HelpURL() string
+}
+
+// fieldErrorsError is implemented by errors carrying a per-field breakdown,
+// such as exception.ValidationErrors
+type fieldErrorsError interface {
+	error
+	FieldErrors() []exception.FieldError
+}
+
+// detailsError is implemented by errors carrying structured context
+type detailsError interface {
+	error
+	Details() map[string]any
+}
+
 func AsHTTPError(err error) (HTTPError, bool) {
 	if err == nil {
 		return nil, false
@@ -56,56 +84,95 @@ func AsHTTPError(err error) (HTTPError, bool) {
 // Parameters:
 //   - w: The HTTP response writer
 //   - data: The data to include in the response
-func OK(w http.ResponseWriter, data any) {
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(Response{
-		Status:  http.StatusOK,
-		Success: true,
-		Data:    data,
-	})
+//   - opts: Options customizing the response headers, such as WithCacheControl
+func OK(w http.ResponseWriter, data any, opts ...WriteOption) {
+	std().OK(w, data, opts...)
+}
+
+// Created writes a 201 Created JSON response with the provided data,
+// typically the newly created resource.
+//
+// Parameters:
+//   - w: The HTTP response writer
+//   - data: The data to include in the response
+//   - opts: Options customizing the response headers, such as WithCacheControl
+func Created(w http.ResponseWriter, data any, opts ...WriteOption) {
+	std().Created(w, data, opts...)
+}
+
+// Accepted writes a 202 Accepted JSON response with the provided data,
+// typically a reference to the asynchronous operation.
+//
+// Parameters:
+//   - w: The HTTP response writer
+//   - data: The data to include in the response
+//   - opts: Options customizing the response headers, such as WithCacheControl
+func Accepted(w http.ResponseWriter, data any, opts ...WriteOption) {
+	std().Accepted(w, data, opts...)
+}
+
+// OKWithPage writes a successful JSON response with the provided list data
+// and its pagination information.
+//
+// Parameters:
+//   - w: The HTTP response writer
+//   - data: The list data to include in the response
+//   - page: The position of the data within the full result set
+//   - opts: Options customizing the response headers, such as WithCacheControl
+func OKWithPage(w http.ResponseWriter, data any, page Page, opts ...WriteOption) {
+	std().OKWithPage(w, data, page, opts...)
+}
+
+// OKWithWarnings writes a successful JSON response with the provided data
+// and the non-fatal issues that occurred while producing it, such as
+// deprecated parameters or partial enrichment failures.
+//
+// Example usage:
+//
+//	httphelper.OKWithWarnings(w, order, []httphelper.ErrorInfo{{
+//	    Code:    "ENRICHMENT_FAILED",
+//	    Message: "shipping estimate is unavailable",
+//	}})
+//
+// Parameters:
+//   - w: The HTTP response writer
+//   - data: The data to include in the response
+//   - warnings: The non-fatal issues to report in the warnings field
+//   - opts: Options customizing the response headers, such as WithCacheControl
+func OKWithWarnings(w http.ResponseWriter, data any, warnings []ErrorInfo, opts ...WriteOption) {
+	std().OKWithWarnings(w, data, warnings, opts...)
+}
+
+// NoContent writes a 204 No Content response.
+// No envelope is written since 204 responses cannot carry a body.
+//
+// Parameters:
+//   - w: The HTTP response writer
+//   - opts: Options customizing the response headers, such as WithCacheControl
+func NoContent(w http.ResponseWriter, opts ...WriteOption) {
+	std().NoContent(w, opts...)
 }
 
 // Error writes an error response in JSON format.
 // It handles both standard errors and custom errors implementing the HTTPError interface.
+// Errors deriving from context.Canceled are written with status 499 and the
+// CLIENT_CLOSED_REQUEST code; ErrorContext also does so when ctx is cancelled.
 //
 // Parameters:
 //   - w: The HTTP response writer
 //   - err: The error to include in the response
-func Error(w http.ResponseWriter, err error) {
-	w.Header().Set("Content-Type", "application/json")
-
-	var errInfo ErrorInfo
-	var httpStatus int
-	if httpErr, ok := AsHTTPError(err); ok {
-		errInfo = ErrorInfo{
-			Code:    httpErr.Code(),
-			Message: httpErr.Message(),
-		}
-		if defaultConfig.includeDetails {
-			errInfo.Detail = httpErr.Error()
-		}
-		httpStatus = httpErr.HTTPStatus()
-	} else {
-		errInfo = ErrorInfo{
-			Code:    defaultConfig.defaultErrorCode,
-			Message: defaultConfig.defaultErrorMessage,
-		}
-		if defaultConfig.includeDetails {
-			errInfo.Detail = err.Error()
-		}
-		httpStatus = http.StatusInternalServerError
-	}
-
-	w.WriteHeader(httpStatus)
-	json.NewEncoder(w).Encode(Response{
-		Status:    httpStatus,
-		Success:   false,
-		ErrorInfo: &errInfo,
-	})
+//   - opts: Options customizing the response headers, such as WithHeader
+func Error(w http.ResponseWriter, err error, opts ...WriteOption) {
+	std().Error(w, err, opts...)
 }
 
 // ReadData safely extracts and unmarshals the response Data field into the specified type T.
 // It handles various data formats and provides type-safe data extraction.
+// Responses decoded from JSON memoize the result per type T, so repeated
+// calls on the response or its copies skip decoding; every call gets its own
+// deep copy, so mutating the result never affects other callers.
+// With WithStrictDecoding enabled, unexpected and missing fields are reported
+// as a VALIDATION_FAILED error.
 //
 // Example usage:
 //
@@ -123,35 +190,60 @@ func Error(w http.ResponseWriter, err error) {
 //   - An error if the response contains an error or if unmarshaling fails
 func ReadData[T any](r Response) (T, error) {
 	var data T
+	err := readData(r, &data, std().cfg.strictDecoding)
+	return data, err
+}
+
+// readData unmarshals the response Data field into target, checking its
+// fields against target first when strict
+func readData(r Response, target any, strict bool) error {
 	// First check if response is successful
 	if err := r.Err(); err != nil {
-		return data, err
+		return err
 	}
 
 	// Handle nil data
 	if r.Data == nil {
-		return data, fmt.Errorf("response data is nil")
+		return fmt.Errorf("response data is nil")
+	}
+	if !strict && r.cache.load(r.Data, target) {
+		return nil
 	}
 
-	// Convert data to JSON bytes for consistent unmarshaling
-	var jsonBytes []byte
-	switch v := r.Data.(type) {
-	case []byte:
-		jsonBytes = v
-	case string:
-		jsonBytes = []byte(v)
-	default:
-		var err error
-		jsonBytes, err = json.Marshal(r.Data)
-		if err != nil {
-			return data, fmt.Errorf("failed to marshal response data: %w", err)
+	jsonBytes, err := dataJSON(r.Data)
+	if err != nil {
+		return err
+	}
+	if strict {
+		if err := checkFields(jsonBytes, target); err != nil {
+			return err
 		}
 	}
 
 	// Unmarshal JSON bytes into target type
-	if err := json.Unmarshal(jsonBytes, &data); err != nil {
-		return data, fmt.Errorf("failed to unmarshal response data: %w", err)
+	if err := json.Unmarshal(jsonBytes, target); err != nil {
+		return fmt.Errorf("failed to unmarshal response data: %w", err)
 	}
+	r.cache.store(r.Data, target)
 
-	return data, nil
+	return nil
+}
+
+// dataJSON converts the response Data field to JSON bytes for consistent
+// unmarshaling
+func dataJSON(data any) ([]byte, error) {
+	switch v := data.(type) {
+	case json.RawMessage:
+		return v, nil
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	default:
+		jsonBytes, err := json.Marshal(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal response data: %w", err)
+		}
+		return jsonBytes, nil
+	}
 }
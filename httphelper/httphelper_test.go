@@ -1,13 +1,27 @@
 package httphelper_test
 
 import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/gob"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/aeramu/apihelper/exception"
 	"github.com/aeramu/apihelper/httphelper"
@@ -28,7 +42,7 @@ type testCase struct {
 var (
 	errGeneric     = errors.New("some error")
 	errException   = exception.New("some error",
-		exception.WithStatus(exception.CodeInvalidRequest),
+		exception.WithStatus(exception.StatusInvalidRequest),
 		exception.WithCode("TEST_ERROR"),
 		exception.WithMessage("TEST_MESSAGE"),
 	)
@@ -235,20 +249,10 @@ func exampleHTTPImpl(t *testing.T, name string, ts *httptest.Server) {
 	resp, err := http.Get(ts.URL)
 	assert.NoError(t, err)
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		fmt.Printf("error read body: %v\n", err)
-		fmt.Printf("status code: %d\n", resp.StatusCode)
-		fmt.Printf("body: %s\n", string(body))
-		return
-	}
-
-	var result httphelper.Response
-	err = json.Unmarshal(body, &result)
+	result, err := httphelper.FromHTTPResponse(resp)
 	if err != nil {
-		fmt.Printf("error decode response: %v\n", err)
+		fmt.Printf("error read response: %v\n", err)
 		fmt.Printf("status code: %d\n", resp.StatusCode)
-		fmt.Printf("body: %s\n", string(body))
 		return
 	}
 
@@ -257,7 +261,7 @@ func exampleHTTPImpl(t *testing.T, name string, ts *httptest.Server) {
 		fmt.Printf("code: %s\n", result.Code())
 		fmt.Printf("message: %s\n", result.Message())
 		fmt.Printf("status code: %d\n", resp.StatusCode)
-		fmt.Printf("body: %s\n", string(body))
+		fmt.Printf("body: %s\n", string(result.Raw()))
 		return
 	}
 
@@ -303,7 +307,7 @@ func exampleRestyImpl(t *testing.T, name string, ts *httptest.Server) {
 
 func TestAsHTTPError(t *testing.T) {
 	err := exception.New("error",
-		exception.WithStatus(exception.CodeSoftError),
+		exception.WithStatus(exception.StatusSoftError),
 		exception.WithCode("TEST_CODE"),
 		exception.WithMessage("message"),
 	)
@@ -318,7 +322,7 @@ func TestAsHTTPError(t *testing.T) {
 
 func TestAsHTTPError_Join(t *testing.T) {
 	err := exception.New("error",
-		exception.WithStatus(exception.CodeSoftError),
+		exception.WithStatus(exception.StatusSoftError),
 		exception.WithCode("TEST_CODE"),
 		exception.WithMessage("message"),
 	)
@@ -335,13 +339,13 @@ func TestAsHTTPError_Join(t *testing.T) {
 
 func TestAsHTTPError_JoinException(t *testing.T) {
 	err := exception.New("error",
-		exception.WithStatus(exception.CodeSoftError),
+		exception.WithStatus(exception.StatusSoftError),
 		exception.WithCode("TEST_CODE"),
 		exception.WithMessage("message"),
 	)
 
 	err = errors.Join(err, exception.New("error2",
-		exception.WithStatus(exception.CodeInternal),
+		exception.WithStatus(exception.StatusInternal),
 		exception.WithCode("TEST_CODE2"),
 		exception.WithMessage("message2"),
 	))
@@ -352,4 +356,2163 @@ func TestAsHTTPError_JoinException(t *testing.T) {
 	assert.Equal(t, http.StatusOK, httpErr.HTTPStatus())
 	assert.Equal(t, "message", httpErr.Message())
 	assert.Equal(t, "error", httpErr.Error())
-}
\ No newline at end of file
+}
+func TestError_InternalDetailRate(t *testing.T) {
+	defer httphelper.Configure(httphelper.WithInternalDetailRate(1))
+	httphelper.Configure(httphelper.WithInternalDetailRate(0))
+
+	rec := httptest.NewRecorder()
+	httphelper.Error(rec, errGeneric)
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Empty(t, result.ErrorInfo.Detail)
+
+	rec = httptest.NewRecorder()
+	httphelper.Error(rec, errException)
+	result = httphelper.Response{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, errException.Error(), result.ErrorInfo.Detail)
+}
+
+func TestError_ProblemDetails(t *testing.T) {
+	defer httphelper.Configure(httphelper.WithProblemDetails(false))
+	httphelper.Configure(httphelper.WithProblemDetails(true))
+
+	rec := httptest.NewRecorder()
+	httphelper.Error(rec, errException)
+
+	var problem map[string]any
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, exception.ProblemContentType, rec.Header().Get("Content-Type"))
+	assert.Equal(t, "TEST_MESSAGE", problem["title"])
+	assert.Equal(t, "TEST_ERROR", problem["code"])
+	assert.Equal(t, float64(http.StatusBadRequest), problem["status"])
+}
+
+func TestError_ProblemDetailsStatuses(t *testing.T) {
+	defer httphelper.Configure(httphelper.WithProblemDetails(false), httphelper.WithMaxFormSize(32<<20))
+	httphelper.Configure(httphelper.WithProblemDetails(true), httphelper.WithMaxFormSize(8))
+
+	form := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := httphelper.BindForm[struct{}](r); err != nil {
+			httphelper.Error(w, err)
+		}
+	})
+	limits := httphelper.RequestLimits{MaxURLLength: 16, MaxHeaderCount: 2}.Middleware(form)
+	contentType := httphelper.RequireContentType()(form)
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		req     *http.Request
+		status  int
+		code    string
+	}{
+		{
+			name: "client closed request",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				httphelper.Error(w, context.Canceled)
+			}),
+			req:    httptest.NewRequest(http.MethodGet, "/", nil),
+			status: httphelper.StatusClientClosedRequest,
+			code:   httphelper.CLIENT_CLOSED_REQUEST,
+		},
+		{
+			name:    "payload too large",
+			handler: form,
+			req: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name=0123456789"))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				return req
+			}(),
+			status: http.StatusRequestEntityTooLarge,
+			code:   httphelper.PAYLOAD_TOO_LARGE,
+		},
+		{
+			name:    "uri too long",
+			handler: limits,
+			req:     httptest.NewRequest(http.MethodGet, "/orders/0123456789", nil),
+			status:  http.StatusRequestURITooLong,
+			code:    httphelper.URI_TOO_LONG,
+		},
+		{
+			name:    "unsupported media type",
+			handler: contentType,
+			req:     httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}")),
+			status:  http.StatusUnsupportedMediaType,
+			code:    exception.CodeInvalidRequest.String(),
+		},
+		{
+			name:    "headers too large",
+			handler: limits,
+			req: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.Header["X-A"] = []string{"1", "2", "3"}
+				return req
+			}(),
+			status: http.StatusRequestHeaderFieldsTooLarge,
+			code:   httphelper.HEADERS_TOO_LARGE,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.handler.ServeHTTP(rec, tt.req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, exception.ProblemContentType, rec.Header().Get("Content-Type"))
+			var problem map[string]any
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+			assert.Equal(t, float64(tt.status), problem["status"])
+			assert.Equal(t, tt.code, problem["code"])
+		})
+	}
+}
+
+func TestError_SoftErrorWithData(t *testing.T) {
+	err := exception.New("partial result",
+		exception.WithStatus(exception.StatusSoftError),
+		exception.WithCode("PARTIAL_RESULT"),
+		exception.WithMessage("some items could not be loaded"),
+		exception.WithData(Data{Foo: "foo"}),
+	)
+
+	rec := httptest.NewRecorder()
+	httphelper.Error(rec, err)
+
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "PARTIAL_RESULT", result.Code())
+	assert.JSONEq(t, `{"Foo": "foo", "Bar": ""}`, string(result.Data.(json.RawMessage)))
+}
+
+func TestError_HelpURL(t *testing.T) {
+	err := exception.New("order not found",
+		exception.WithStatus(exception.StatusNotFound),
+		exception.WithCode("ORDER_NOT_FOUND"),
+		exception.WithHelpURL("https://docs.example.com/errors/ORDER_NOT_FOUND"),
+	)
+
+	rec := httptest.NewRecorder()
+	httphelper.Error(rec, err)
+
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, "https://docs.example.com/errors/ORDER_NOT_FOUND", result.ErrorInfo.Help)
+}
+
+func TestResponseBuilder(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	httphelper.NewResponse().
+		Status(http.StatusCreated).
+		Data(Data{Foo: "foo"}).
+		Warning(httphelper.ErrorInfo{Code: "DEPRECATED_PARAM", Message: "param sort is deprecated"}).
+		Meta("request_id", "req-1").
+		Write(rec)
+
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, http.StatusCreated, result.Status)
+	assert.True(t, result.Success)
+	assert.Equal(t, "DEPRECATED_PARAM", result.Warnings[0].Code)
+	assert.Equal(t, "req-1", result.Meta["request_id"])
+
+	data, err := httphelper.ReadData[Data](result)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", data.Foo)
+}
+
+func TestSuccessWriters(t *testing.T) {
+	tests := []struct {
+		name   string
+		write  func(w http.ResponseWriter)
+		status int
+	}{
+		{"created", func(w http.ResponseWriter) { httphelper.Created(w, Data{Foo: "foo"}) }, http.StatusCreated},
+		{"accepted", func(w http.ResponseWriter) { httphelper.Accepted(w, Data{Foo: "foo"}) }, http.StatusAccepted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.write(rec)
+
+			var result httphelper.Response
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, tt.status, result.Status)
+			assert.True(t, result.Success)
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	httphelper.NoContent(rec)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, rec.Body.Bytes())
+}
+
+func TestConditionalTransport(t *testing.T) {
+	var notModified int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		httphelper.OK(w, Data{Foo: "foo"})
+	}))
+	defer ts.Close()
+
+	client := resty.New().SetTransport(httphelper.NewConditionalTransport(nil, httphelper.NewMemoryConditionalStore()))
+	for i := 0; i < 2; i++ {
+		var result httphelper.Response
+		resp, err := client.R().SetResult(&result).SetError(&result).Get(ts.URL)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+
+		data, err := httphelper.ReadData[Data](result)
+		assert.NoError(t, err)
+		assert.Equal(t, "foo", data.Foo)
+	}
+	assert.Equal(t, 1, notModified)
+}
+
+func TestConditionalTransport_Isolation(t *testing.T) {
+	var notModified int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Vary", "Accept-Language")
+		if r.URL.Path == "/no-store" {
+			w.Header().Set("Cache-Control", "no-store")
+		}
+		httphelper.OK(w, Data{Foo: r.Header.Get("Authorization") + r.Header.Get("Accept-Language")})
+	}))
+	defer ts.Close()
+
+	client := &http.Client{Transport: httphelper.NewConditionalTransport(nil, httphelper.NewMemoryConditionalStore())}
+	get := func(path, auth, lang string) string {
+		req := httptest.NewRequest(http.MethodGet, ts.URL+path, nil)
+		req.RequestURI = ""
+		req.Header.Set("Authorization", auth)
+		req.Header.Set("Accept-Language", lang)
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		result, err := httphelper.FromHTTPResponse(resp)
+		assert.NoError(t, err)
+		data, err := httphelper.ReadData[Data](result)
+		assert.NoError(t, err)
+		return data.Foo
+	}
+
+	assert.Equal(t, "alice", get("/", "alice", ""))
+	assert.Equal(t, "bob", get("/", "bob", ""))
+	assert.Equal(t, "alice", get("/", "alice", ""))
+	assert.Equal(t, 1, notModified)
+
+	assert.Equal(t, "alicefr", get("/", "alice", "fr"))
+	assert.Equal(t, 1, notModified)
+
+	get("/no-store", "alice", "")
+	get("/no-store", "alice", "")
+	assert.Equal(t, 1, notModified)
+}
+
+func TestLRUConditionalStore(t *testing.T) {
+	store := httphelper.NewLRUConditionalStore(2)
+	store.Set("a", httphelper.CachedResponse{ETag: "a"})
+	store.Set("b", httphelper.CachedResponse{ETag: "b"})
+	_, ok := store.Get("a")
+	assert.True(t, ok)
+	store.Set("c", httphelper.CachedResponse{ETag: "c"})
+
+	_, ok = store.Get("b")
+	assert.False(t, ok)
+	for _, key := range []string{"a", "c"} {
+		resp, ok := store.Get(key)
+		assert.True(t, ok)
+		assert.Equal(t, key, resp.ETag)
+	}
+}
+
+func TestOKWithPage(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	httphelper.OKWithPage(rec, []Data{{Foo: "foo"}}, httphelper.Page{Total: 3, Limit: 1, Offset: 1})
+
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	page, ok := result.Page()
+	assert.True(t, ok)
+	assert.Equal(t, 3, page.Total)
+	assert.True(t, page.HasNext())
+
+	data, err := httphelper.ReadData[[]Data](result)
+	assert.NoError(t, err)
+	assert.Len(t, data, 1)
+}
+
+type requestIDKey struct{}
+
+func TestMetaMiddleware(t *testing.T) {
+	defer httphelper.Configure(httphelper.WithMetaExtractor(nil))
+	httphelper.Configure(httphelper.WithMetaExtractor(func(ctx context.Context) map[string]any {
+		return map[string]any{"request_id": ctx.Value(requestIDKey{})}
+	}))
+
+	handler := httphelper.MetaMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httphelper.Error(w, errException)
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req = req.WithContext(context.WithValue(req.Context(), requestIDKey{}, "req-1"))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, "req-1", result.Meta["request_id"])
+	assert.Contains(t, result.Meta, httphelper.MetaTimestamp)
+	assert.Contains(t, result.Meta, httphelper.MetaDuration)
+}
+
+type legacyEnvelope struct{}
+
+func (legacyEnvelope) BuildSuccess(resp httphelper.Response) any {
+	return map[string]any{"result": resp.Data}
+}
+
+func (legacyEnvelope) BuildError(resp httphelper.Response, err error) any {
+	return map[string]any{"errorCode": resp.Code()}
+}
+
+func TestEnvelope(t *testing.T) {
+	defer httphelper.Configure(httphelper.WithEnvelope(nil))
+	httphelper.Configure(httphelper.WithEnvelope(legacyEnvelope{}))
+
+	rec := httptest.NewRecorder()
+	httphelper.OK(rec, "foo")
+	assert.JSONEq(t, `{"result":"foo"}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	httphelper.Error(rec, errException)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.JSONEq(t, `{"errorCode":"TEST_ERROR"}`, rec.Body.String())
+}
+
+func TestHelper(t *testing.T) {
+	helper := httphelper.New(
+		httphelper.WithDefaultErrorCode("HELPER_ERROR"),
+		httphelper.WithIncludeDetails(false),
+	)
+
+	rec := httptest.NewRecorder()
+	helper.Error(rec, errGeneric)
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, "HELPER_ERROR", result.Code())
+	assert.Equal(t, httphelper.INTERNAL_SERVER_MESSAGE, result.Message())
+	assert.Empty(t, result.ErrorInfo.Detail)
+
+	rec = httptest.NewRecorder()
+	helper.OK(rec, Data{Foo: "foo"})
+	result = httphelper.Response{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	var data Data
+	assert.NoError(t, helper.ReadData(result, &data))
+	assert.Equal(t, "foo", data.Foo)
+}
+
+func TestErrorContext(t *testing.T) {
+	ctx := httphelper.WithContextOptions(context.Background(), httphelper.WithIncludeDetails(false))
+	ctx = httphelper.WithContextOptions(ctx, httphelper.WithDefaultErrorCode("CONTEXT_ERROR"))
+
+	rec := httptest.NewRecorder()
+	httphelper.ErrorContext(ctx, rec, errGeneric)
+
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, "CONTEXT_ERROR", result.Code())
+	assert.Empty(t, result.ErrorInfo.Detail)
+
+	rec = httptest.NewRecorder()
+	httphelper.Error(rec, errGeneric)
+	result = httphelper.Response{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, defaultCode, result.Code())
+	assert.Equal(t, errGeneric.Error(), result.ErrorInfo.Detail)
+
+	router := httphelper.NewRouter()
+	router.GET("/items", errorRoute{helper: httphelper.New(), err: errGeneric})
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items", nil).WithContext(ctx))
+	result = httphelper.Response{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, "CONTEXT_ERROR", result.Code())
+	assert.Empty(t, result.ErrorInfo.Detail)
+}
+
+func TestDeprecationRegistry(t *testing.T) {
+	registry := httphelper.NewDeprecationRegistry(
+		httphelper.Deprecation{
+			Path:    "/v1/orders",
+			Param:   "sort",
+			Sunset:  time.Now().Add(24 * time.Hour),
+			Message: "param sort is deprecated, use order_by",
+		},
+		httphelper.Deprecation{
+			Path:    "/v0/*",
+			Sunset:  time.Now().Add(-time.Hour),
+			Message: "v0 has been retired, use v1",
+			Enforce: true,
+		},
+	)
+	var uses int
+	registry.OnUse(func(r *http.Request, d httphelper.Deprecation) {
+		uses++
+	})
+	handler := registry.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httphelper.OK(w, nil)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/orders?sort=asc", nil))
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, "true", rec.Header().Get("Deprecation"))
+	assert.NotEmpty(t, rec.Header().Get("Sunset"))
+	assert.Equal(t, "DEPRECATED", result.Warnings[0].Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/orders", nil))
+	assert.Empty(t, rec.Header().Get("Deprecation"))
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v0/orders", nil))
+	result = httphelper.Response{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, http.StatusGone, rec.Code)
+	assert.Equal(t, httphelper.ENDPOINT_RETIRED, result.Code())
+	assert.Equal(t, 2, uses)
+}
+
+func TestBatch(t *testing.T) {
+	helper := httphelper.New(httphelper.WithMultiStatus(true))
+
+	rec := httptest.NewRecorder()
+	helper.Batch(rec, []httphelper.BatchItem{
+		httphelper.BatchSuccess("1", Data{Foo: "foo"}),
+		helper.BatchFailure("2", exception.ErrorNotFound),
+	})
+
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, http.StatusMultiStatus, rec.Code)
+
+	successes, failures, err := httphelper.PartitionBatch[Data](result)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", successes["1"].Foo)
+	assert.True(t, exception.HasStatus(failures["2"], exception.StatusNotFound))
+	httpErr, ok := httphelper.AsHTTPError(failures["2"])
+	assert.True(t, ok)
+	assert.Equal(t, exception.CodeNotFound.String(), httpErr.Code())
+
+	rec = httptest.NewRecorder()
+	httphelper.Batch(rec, []httphelper.BatchItem{
+		httphelper.BatchSuccess("1", Data{Foo: "foo"}),
+		httphelper.BatchFailure("2", exception.ErrorNotFound),
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	t.Run("all failed", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		helper.Batch(rec, []httphelper.BatchItem{
+			helper.BatchFailure("1", exception.ErrorNotFound),
+			helper.BatchFailure("2", exception.ErrorNotFound),
+		})
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		result, err := helper.DecodeResponse(rec.Body.Bytes())
+		assert.NoError(t, err)
+		assert.False(t, result.Success)
+		assert.Equal(t, exception.CodeNotFound.String(), result.Code())
+
+		successes, failures, err := httphelper.PartitionBatch[Data](result)
+		assert.NoError(t, err)
+		assert.Empty(t, successes)
+		assert.Len(t, failures, 2)
+		assert.True(t, exception.HasStatus(failures["2"], exception.StatusNotFound))
+
+		rec = httptest.NewRecorder()
+		helper.Batch(rec, []httphelper.BatchItem{
+			helper.BatchFailure("1", exception.ErrorNotFound),
+			helper.BatchFailure("2", exception.New("order not found",
+				exception.WithStatus(exception.StatusNotFound),
+				exception.WithCode("ORDER_NOT_FOUND"),
+			)),
+		})
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		result, err = httphelper.New(httphelper.WithStrictEnvelope(true)).DecodeResponse(rec.Body.Bytes())
+		assert.NoError(t, err)
+		assert.Equal(t, httphelper.BATCH_FAILED, result.Code())
+	})
+}
+
+func TestError_MessageTemplates(t *testing.T) {
+	helper := httphelper.New(httphelper.WithMessageTemplates(map[string]string{
+		"ORDER_NOT_FOUND": "order {order_id} was not found",
+	}))
+	err := exception.New("order not found",
+		exception.WithStatus(exception.StatusNotFound),
+		exception.WithCode("ORDER_NOT_FOUND"),
+		exception.WithDetail("order_id", 42),
+	)
+
+	rec := httptest.NewRecorder()
+	helper.Error(rec, err)
+
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, "order 42 was not found", result.Message())
+}
+
+func TestDecodeResponse_Strict(t *testing.T) {
+	helper := httphelper.New(httphelper.WithStrictEnvelope(true))
+
+	tests := map[string]string{
+		"unknown field":         `{"status":200,"success":true,"data":{},"extra":1}`,
+		"error status":          `{"status":500,"success":true,"data":{}}`,
+		"missing error":         `{"status":500,"success":false}`,
+		"both data and error":   `{"status":400,"success":false,"data":{},"error":{"code":"X"}}`,
+		"success with an error": `{"status":200,"success":true,"error":{"code":"X"}}`,
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := helper.DecodeResponse([]byte(body))
+			code, ok := exception.AsErrorCode(err)
+			assert.True(t, ok)
+			assert.Equal(t, httphelper.CONTRACT_VIOLATION, code.Code())
+		})
+	}
+
+	resp, err := helper.DecodeResponse([]byte(`{"status":200,"success":true,"data":{"Foo":"foo"}}`))
+	assert.NoError(t, err)
+	assert.True(t, resp.Success)
+
+	_, err = httphelper.DecodeResponse([]byte(`{"status":200,"success":true,"data":{},"extra":1}`))
+	assert.NoError(t, err)
+}
+
+func TestNegotiationMiddleware(t *testing.T) {
+	handler := httphelper.NegotiationMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("fail") != "" {
+			httphelper.Error(w, errException)
+			return
+		}
+		httphelper.OK(w, Data{Foo: "foo", Bar: "bar"})
+	}))
+
+	type xmlResponse struct {
+		Status  int  `xml:"status"`
+		Success bool `xml:"success"`
+		Data    Data `xml:"data"`
+		Error   struct {
+			Code string `xml:"code"`
+		} `xml:"error"`
+	}
+
+	t.Run("xml", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", "application/xml")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, httphelper.ContentTypeXML, rec.Header().Get("Content-Type"))
+		assert.Equal(t, "Accept", rec.Header().Get("Vary"))
+		var result xmlResponse
+		assert.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &result))
+		assert.Equal(t, http.StatusOK, result.Status)
+		assert.True(t, result.Success)
+		assert.Equal(t, Data{Foo: "foo", Bar: "bar"}, result.Data)
+	})
+
+	t.Run("xml error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?fail=1", nil)
+		req.Header.Set("Accept", "text/xml, application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		var result xmlResponse
+		assert.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &result))
+		assert.False(t, result.Success)
+		assert.Equal(t, "TEST_ERROR", result.Error.Code)
+	})
+
+	t.Run("json fallback", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", "application/json, application/xml")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, httphelper.ContentTypeJSON, rec.Header().Get("Content-Type"))
+		var result httphelper.Response
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		assert.True(t, result.Success)
+	})
+
+	for accept, contentType := range map[string]string{
+		"application/json;q=0.5, application/xml":   httphelper.ContentTypeXML,
+		"application/xml;q=0.9, application/json":   httphelper.ContentTypeJSON,
+		"application/json;q=0, */*":                 httphelper.ContentTypeXML,
+		"application/xml;q=0, */*;q=0.1":            httphelper.ContentTypeJSON,
+		"text/xml;q=0.8, application/json;q=0.2":    httphelper.ContentTypeXML,
+		"application/json;q=invalid, text/xml;q=.1": httphelper.ContentTypeXML,
+	} {
+		t.Run("q-values "+accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept", accept)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, contentType, rec.Header().Get("Content-Type"))
+		})
+	}
+}
+
+func TestError_Fallback(t *testing.T) {
+	type ctxKey struct{}
+	var degraded []error
+	var contexts []any
+	helper := httphelper.New(
+		httphelper.WithDefaultCacheControl("public, max-age=60"),
+		httphelper.WithFallback(func(ctx context.Context, err error) (any, bool) {
+			contexts = append(contexts, ctx.Value(ctxKey{}))
+			if exception.HasStatus(err, exception.StatusUnavailable) {
+				return []Data{}, true
+			}
+			return nil, false
+		}),
+		httphelper.WithDegradationHook(func(ctx context.Context, err error) {
+			degraded = append(degraded, err)
+		}),
+	)
+
+	rec := httptest.NewRecorder()
+	helper.ErrorContext(context.Background(), rec, exception.ErrorUnavailable)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.True(t, result.Success)
+	assert.Equal(t, json.RawMessage(`[]`), result.Data)
+	assert.Len(t, result.Warnings, 1)
+	assert.Equal(t, httphelper.DEGRADED, result.Warnings[0].Code)
+	assert.Equal(t, []error{exception.ErrorUnavailable}, degraded)
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+
+	rec = httptest.NewRecorder()
+	helper.Error(rec, exception.ErrorNotFound)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	router := httphelper.NewRouter()
+	router.GET("/items", errorRoute{helper: helper, err: exception.ErrorUnavailable})
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+	router.ServeHTTP(rec, req.WithContext(context.WithValue(req.Context(), ctxKey{}, "request")))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []any{nil, nil, "request"}, contexts)
+}
+
+// errorRoute is a TypedHandler writing err with Error
+type errorRoute struct {
+	helper *httphelper.Helper
+	err    error
+}
+
+func (h errorRoute) ServeHTTP(w http.ResponseWriter, r *http.Request) { h.helper.Error(w, h.err) }
+
+func (errorRoute) RequestType() reflect.Type { return nil }
+
+func (errorRoute) ResponseType() reflect.Type { return nil }
+
+// gobCodec stands in for binary codecs such as msgpack in tests
+type gobCodec struct{}
+
+func (gobCodec) ContentType() string { return "application/x-gob" }
+
+func (gobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(v)
+	return buf.Bytes(), err
+}
+
+func (gobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func TestNegotiationMiddleware_XMLMeta(t *testing.T) {
+	handler := httphelper.NegotiationMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("fail") != "" {
+			httphelper.Error(w, exception.New("invalid order",
+				exception.WithStatus(exception.StatusValidationFailed),
+				exception.WithCode("INVALID_ORDER"),
+				exception.WithDetail("fields", []string{"sku", "qty"}),
+			))
+			return
+		}
+		httphelper.NewResponse().
+			Data(Data{Foo: "foo"}).
+			Meta("request_id", "req_1").
+			Meta("limits", map[string]int{"remaining": 9}).
+			Write(w)
+	}))
+
+	serve := func(target string) string {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Accept", "application/xml")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, httphelper.ContentTypeXML, rec.Header().Get("Content-Type"))
+		return rec.Body.String()
+	}
+
+	body := serve("/")
+	assert.Contains(t, body, `<meta><entry key="limits"><entry key="remaining">9</entry></entry><entry key="request_id">req_1</entry></meta>`)
+
+	body = serve("/?fail=1")
+	assert.Contains(t, body, `<details><entry key="fields"><item>sku</item><item>qty</item></entry></details>`)
+}
+
+func TestNegotiationMiddleware_Codec(t *testing.T) {
+	gob.Register(Data{})
+	helper := httphelper.New(httphelper.WithCodec(gobCodec{}))
+	handler := httphelper.NegotiationMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		helper.OK(w, Data{Foo: "foo", Bar: "bar"})
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "application/x-gob")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, "application/x-gob", rec.Header().Get("Content-Type"))
+	resp, err := helper.DecodeResponseAs(rec.Header().Get("Content-Type"), rec.Body.Bytes())
+	assert.NoError(t, err)
+	assert.True(t, resp.Success)
+	data, err := httphelper.ReadData[Data](resp)
+	assert.NoError(t, err)
+	assert.Equal(t, Data{Foo: "foo", Bar: "bar"}, data)
+
+	_, err = httphelper.DecodeResponseAs("application/x-gob", rec.Body.Bytes())
+	assert.Error(t, err)
+}
+
+func TestStreamNDJSON(t *testing.T) {
+	rows := make(chan Data)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(rows)
+		rows <- Data{Foo: "1"}
+		rows <- Data{Foo: "2"}
+		errc <- errException
+	}()
+
+	rec := httptest.NewRecorder()
+	assert.NoError(t, httphelper.StreamNDJSON(rec, rows, errc))
+
+	assert.Equal(t, httphelper.ContentTypeNDJSON, rec.Header().Get("Content-Type"))
+	assert.True(t, rec.Flushed)
+	dec := json.NewDecoder(rec.Body)
+	var row Data
+	assert.NoError(t, dec.Decode(&row))
+	assert.Equal(t, "1", row.Foo)
+	assert.NoError(t, dec.Decode(&row))
+	assert.Equal(t, "2", row.Foo)
+	var streamErr httphelper.StreamError
+	assert.NoError(t, dec.Decode(&streamErr))
+	assert.Equal(t, "TEST_ERROR", streamErr.ErrorInfo.Code)
+	assert.False(t, dec.More())
+}
+
+func TestListErrorCodes(t *testing.T) {
+	rec := httptest.NewRecorder()
+	httphelper.ListErrorCodes(rec, httptest.NewRequest(http.MethodGet, "/errors", nil))
+
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	entries, err := httphelper.ReadData[[]exception.Entry](result)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, entries)
+	for _, entry := range entries {
+		assert.NotEmpty(t, entry.Stability)
+	}
+}
+
+func TestSSE(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		events := httphelper.SSE(w)
+		stop := events.Heartbeat(time.Millisecond)
+		time.Sleep(5 * time.Millisecond)
+		stop()
+		events.Send("data", Data{Foo: "foo"})
+		events.Error(errException)
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+
+	assert.Equal(t, httphelper.ContentTypeEventStream, resp.Header.Get("Content-Type"))
+	assert.Contains(t, string(body), ": heartbeat\n\n")
+	assert.Contains(t, string(body), "event: data\ndata: {\"Foo\":\"foo\",\"Bar\":\"\"}\n\n")
+	assert.Contains(t, string(body), "event: error\ndata: {\"status\":400,\"success\":false,\"data\":null,\"error\":{\"code\":\"TEST_ERROR\"")
+}
+
+func TestOKStream(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		err := httphelper.OKStream(rec, func(enc *json.Encoder) error {
+			for _, foo := range []string{"1", "2", "3"} {
+				if err := enc.Encode(Data{Foo: foo}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+
+		var result httphelper.Response
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		assert.True(t, result.Success)
+		data, err := httphelper.ReadData[[]Data](result)
+		assert.NoError(t, err)
+		assert.Equal(t, []Data{{Foo: "1"}, {Foo: "2"}, {Foo: "3"}}, data)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		err := httphelper.OKStream(rec, func(enc *json.Encoder) error {
+			enc.Encode(Data{Foo: "1"})
+			return errException
+		})
+		assert.Equal(t, errException, err)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var result httphelper.Response
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		assert.False(t, result.Success)
+		assert.Equal(t, http.StatusBadRequest, result.Status)
+		assert.Equal(t, "TEST_ERROR", result.Code())
+	})
+}
+
+func TestRequestLimits(t *testing.T) {
+	var rejected []string
+	handler := httphelper.RequestLimits{
+		MaxURLLength:   64,
+		MaxQueryParams: 3,
+		MaxHeaderCount: 3,
+		MaxHeaderBytes: 128,
+		OnReject: func(r *http.Request, code string) {
+			rejected = append(rejected, code)
+		},
+	}.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httphelper.NoContent(w)
+	}))
+
+	tests := []struct {
+		name   string
+		target string
+		header http.Header
+		status int
+		code   string
+	}{
+		{name: "allowed", target: "/orders?a=1&b=2", status: http.StatusNoContent},
+		{name: "long url", target: "/orders/" + strings.Repeat("x", 64), status: http.StatusRequestURITooLong, code: httphelper.URI_TOO_LONG},
+		{name: "query params", target: "/orders?a=1&b=2&c=3&d=4", status: http.StatusRequestURITooLong, code: httphelper.URI_TOO_LONG},
+		{name: "header count", target: "/orders", header: http.Header{"X-A": {"1", "2"}, "X-B": {"3", "4"}}, status: http.StatusRequestHeaderFieldsTooLarge, code: httphelper.HEADERS_TOO_LARGE},
+		{name: "header size", target: "/orders", header: http.Header{"X-A": {strings.Repeat("x", 128)}}, status: http.StatusRequestHeaderFieldsTooLarge, code: httphelper.HEADERS_TOO_LARGE},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			for name, values := range tt.header {
+				req.Header[name] = values
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			if tt.code != "" {
+				var result httphelper.Response
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+				assert.Equal(t, tt.code, result.Code())
+			}
+		})
+	}
+	assert.Equal(t, []string{httphelper.URI_TOO_LONG, httphelper.URI_TOO_LONG, httphelper.HEADERS_TOO_LARGE, httphelper.HEADERS_TOO_LARGE}, rejected)
+}
+
+type failingReader struct {
+	data []byte
+	err  error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestFile(t *testing.T) {
+	t.Run("seeker", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/report", nil)
+		assert.NoError(t, httphelper.File(rec, req, "report.json", strings.NewReader(`{"a":1}`)))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `attachment; filename=report.json`, rec.Header().Get("Content-Disposition"))
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "7", rec.Header().Get("Content-Length"))
+		assert.Equal(t, `{"a":1}`, rec.Body.String())
+	})
+
+	t.Run("seeker errors", func(t *testing.T) {
+		modTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		for name, tc := range map[string]struct {
+			header, value string
+			status        int
+			code          string
+		}{
+			"range":        {"Range", "bytes=100-200", http.StatusRequestedRangeNotSatisfiable, httphelper.RANGE_NOT_SATISFIABLE},
+			"precondition": {"If-Unmodified-Since", modTime.Add(-time.Hour).Format(http.TimeFormat), http.StatusPreconditionFailed, httphelper.PRECONDITION_FAILED},
+		} {
+			t.Run(name, func(t *testing.T) {
+				rec := httptest.NewRecorder()
+				req := httptest.NewRequest(http.MethodGet, "/report", nil)
+				req.Header.Set(tc.header, tc.value)
+				assert.NoError(t, httphelper.File(rec, req, "report.json", strings.NewReader(`{"a":1}`), httphelper.WithModTime(modTime)))
+
+				assert.Equal(t, tc.status, rec.Code)
+				assert.Equal(t, httphelper.ContentTypeJSON, rec.Header().Get("Content-Type"))
+				assert.Empty(t, rec.Header().Get("Content-Disposition"))
+				var result httphelper.Response
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+				assert.Equal(t, tc.code, result.Code())
+			})
+		}
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/report", nil)
+		content := &failingReader{data: []byte("%PDF-1.4 report"), err: io.EOF}
+		assert.NoError(t, httphelper.File(rec, req, "report", content, httphelper.WithInline(), httphelper.WithFileSize(15)))
+
+		assert.Equal(t, `inline; filename=report`, rec.Header().Get("Content-Disposition"))
+		assert.Equal(t, "application/pdf", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "15", rec.Header().Get("Content-Length"))
+		assert.Equal(t, "%PDF-1.4 report", rec.Body.String())
+	})
+
+	t.Run("read failure", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/report", nil)
+		err := httphelper.File(rec, req, "report.csv", &failingReader{err: exception.ErrorUnavailable})
+		assert.Error(t, err)
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Empty(t, rec.Header().Get("Content-Disposition"))
+		var result httphelper.Response
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		assert.Equal(t, exception.CodeUnavailable.String(), result.Code())
+	})
+}
+
+func TestOKCached(t *testing.T) {
+	data := Data{Foo: "foo"}
+
+	rec := httptest.NewRecorder()
+	httphelper.OKCached(rec, httptest.NewRequest(http.MethodGet, "/", nil), data, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	etag := rec.Header().Get("ETag")
+	assert.Equal(t, `"`+httphelper.ContentETag(data)+`"`, etag)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-None-Match", `"other", W/`+etag)
+	rec = httptest.NewRecorder()
+	httphelper.OKCached(rec, req, data, nil)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+	assert.Equal(t, etag, rec.Header().Get("ETag"))
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-None-Match", `"v1"`)
+	rec = httptest.NewRecorder()
+	httphelper.OKCached(rec, req, data, func(any) string { return "v2" })
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `"v2"`, rec.Header().Get("ETag"))
+}
+
+func TestWithCacheControl(t *testing.T) {
+	helper := httphelper.New(httphelper.WithDefaultCacheControl("no-store"))
+
+	rec := httptest.NewRecorder()
+	helper.OK(rec, Data{Foo: "foo"})
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+
+	rec = httptest.NewRecorder()
+	helper.OK(rec, Data{Foo: "foo"}, httphelper.WithCacheControl("max-age=60, public"))
+	assert.Equal(t, "max-age=60, public", rec.Header().Get("Cache-Control"))
+
+	rec = httptest.NewRecorder()
+	helper.NoContent(rec, httphelper.WithCacheControl("private"))
+	assert.Equal(t, "private", rec.Header().Get("Cache-Control"))
+
+	rec = httptest.NewRecorder()
+	helper.Error(rec, errException)
+	assert.Empty(t, rec.Header().Get("Cache-Control"))
+
+	rec = httptest.NewRecorder()
+	httphelper.Created(rec, Data{Foo: "foo"}, httphelper.WithCacheControl("no-cache"))
+	assert.Equal(t, "no-cache", rec.Header().Get("Cache-Control"))
+}
+
+func TestWithHeader(t *testing.T) {
+	rec := httptest.NewRecorder()
+	httphelper.OK(rec, Data{Foo: "foo"}, httphelper.WithHeader("X-Request-Id", "req-1"))
+	assert.Equal(t, "req-1", rec.Header().Get("X-Request-Id"))
+
+	rec = httptest.NewRecorder()
+	httphelper.Error(rec, errException, httphelper.WithHeader("X-Request-Id", "req-2"))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "req-2", rec.Header().Get("X-Request-Id"))
+
+	rec = httptest.NewRecorder()
+	httphelper.New(httphelper.WithProblemDetails(true)).Error(rec, errException, httphelper.WithHeader("Retry-After", "30"))
+	assert.Equal(t, "30", rec.Header().Get("Retry-After"))
+	assert.Equal(t, exception.ProblemContentType, rec.Header().Get("Content-Type"))
+}
+
+func TestCookies(t *testing.T) {
+	rec := httptest.NewRecorder()
+	httphelper.SetSecureCookie(rec, &http.Cookie{Name: "session", Value: "token", MaxAge: 3600})
+	httphelper.OK(rec, Data{Foo: "foo"}, httphelper.WithCookie(&http.Cookie{Name: "theme", Value: "dark"}))
+
+	cookies := rec.Result().Cookies()
+	assert.Len(t, cookies, 2)
+	assert.Equal(t, "session", cookies[0].Name)
+	assert.True(t, cookies[0].Secure)
+	assert.True(t, cookies[0].HttpOnly)
+	assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+	assert.Equal(t, "/", cookies[0].Path)
+	assert.Equal(t, "theme", cookies[1].Name)
+	assert.False(t, cookies[1].Secure)
+
+	rec = httptest.NewRecorder()
+	httphelper.ClearCookie(rec, "session")
+	httphelper.NoContent(rec)
+	cookies = rec.Result().Cookies()
+	assert.Len(t, cookies, 1)
+	assert.Equal(t, -1, cookies[0].MaxAge)
+}
+
+func TestBindQuery(t *testing.T) {
+	type query struct {
+		Status  []string      `query:"status"`
+		Limit   int           `query:"limit"`
+		Active  bool          `query:"active"`
+		Since   *time.Time    `query:"since"`
+		Timeout time.Duration `query:"timeout"`
+		UserID  string        `query:"user_id,required"`
+		Ignored string
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/?status=open&status=paid&limit=10&active=true&since=2024-01-02T03:04:05Z&timeout=5s&user_id=u1&Ignored=x", nil)
+	q, err := httphelper.BindQuery[query](req)
+	assert.NoError(t, err)
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.Equal(t, query{
+		Status:  []string{"open", "paid"},
+		Limit:   10,
+		Active:  true,
+		Since:   &since,
+		Timeout: 5 * time.Second,
+		UserID:  "u1",
+	}, q)
+
+	req = httptest.NewRequest(http.MethodGet, "/?limit=ten&since=yesterday", nil)
+	_, err = httphelper.BindQuery[query](req)
+	assert.True(t, exception.HasStatus(err, exception.StatusValidationFailed))
+	var detailsErr interface{ Details() map[string]any }
+	assert.True(t, errors.As(err, &detailsErr))
+	assert.Equal(t, map[string]any{
+		"limit":   "must be an integer",
+		"since":   "must be an RFC 3339 time",
+		"user_id": "is required",
+	}, detailsErr.Details())
+}
+
+func TestBindPath(t *testing.T) {
+	type orderPath struct {
+		OrderID int64  `path:"order_id,required"`
+		Item    string `path:"item"`
+	}
+
+	mux := http.NewServeMux()
+	var bound orderPath
+	var bindErr error
+	mux.HandleFunc("/orders/{order_id}/items/{item}", func(w http.ResponseWriter, r *http.Request) {
+		bound, bindErr = httphelper.BindPath[orderPath](r)
+	})
+
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/42/items/book", nil))
+	assert.NoError(t, bindErr)
+	assert.Equal(t, orderPath{OrderID: 42, Item: "book"}, bound)
+
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/abc/items/book", nil))
+	assert.True(t, exception.HasStatus(bindErr, exception.StatusInvalidRequest))
+
+	httphelper.Configure(httphelper.WithPathExtractor(func(r *http.Request, name string) string {
+		return r.Header.Get("X-Path-" + name)
+	}))
+	defer httphelper.Configure(httphelper.WithPathExtractor(nil))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Path-order_id", "7")
+	bound, bindErr = httphelper.BindPath[orderPath](req)
+	assert.NoError(t, bindErr)
+	assert.Equal(t, int64(7), bound.OrderID)
+}
+
+func newMultipartRequest(t *testing.T, fields map[string]string, files map[string]string) *http.Request {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for name, value := range fields {
+		assert.NoError(t, mw.WriteField(name, value))
+	}
+	for field, content := range files {
+		fw, err := mw.CreateFormFile(field, field+".txt")
+		assert.NoError(t, err)
+		fw.Write([]byte(content))
+	}
+	assert.NoError(t, mw.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestBindForm(t *testing.T) {
+	type profileForm struct {
+		Name string `form:"name,required"`
+		Age  int    `form:"age"`
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name=alice&age=30"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	form, err := httphelper.BindForm[profileForm](req)
+	assert.NoError(t, err)
+	assert.Equal(t, profileForm{Name: "alice", Age: 30}, form)
+
+	form, err = httphelper.BindForm[profileForm](newMultipartRequest(t, map[string]string{"name": "bob"}, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "bob", form.Name)
+
+	_, err = httphelper.BindForm[profileForm](newMultipartRequest(t, map[string]string{"age": "old"}, nil))
+	assert.True(t, exception.HasStatus(err, exception.StatusValidationFailed))
+}
+
+func TestFiles(t *testing.T) {
+	files, err := httphelper.Files(newMultipartRequest(t, nil, map[string]string{"avatar": "image"}), "avatar")
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+	assert.Equal(t, "avatar.txt", files[0].Filename)
+
+	_, err = httphelper.Files(newMultipartRequest(t, nil, nil), "avatar")
+	assert.True(t, exception.HasStatus(err, exception.StatusValidationFailed))
+
+	httphelper.Configure(httphelper.WithMaxFormSize(64))
+	defer httphelper.Configure(httphelper.WithMaxFormSize(32 << 20))
+	_, err = httphelper.Files(newMultipartRequest(t, nil, map[string]string{"avatar": strings.Repeat("x", 128)}), "avatar")
+	httpErr, ok := httphelper.AsHTTPError(err)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, httpErr.HTTPStatus())
+	assert.Equal(t, httphelper.PAYLOAD_TOO_LARGE, httpErr.Code())
+}
+
+func TestHandle(t *testing.T) {
+	type updateOrderRequest struct {
+		OrderID int64  `path:"order_id,required"`
+		DryRun  bool   `query:"dry_run"`
+		Note    string `json:"note"`
+	}
+	type updateOrderResponse struct {
+		OrderID int64
+		DryRun  bool
+		Note    string
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /orders/{order_id}", httphelper.Handle(func(ctx context.Context, req updateOrderRequest) (updateOrderResponse, error) {
+		if req.Note == "" {
+			return updateOrderResponse{}, errException
+		}
+		return updateOrderResponse(req), nil
+	}))
+
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		status int
+	}{
+		{name: "success", target: "/orders/42?dry_run=true", body: `{"note":"gift"}`, status: http.StatusOK},
+		{name: "handler error", target: "/orders/42", body: `{}`, status: http.StatusBadRequest},
+		{name: "invalid body", target: "/orders/42", body: `{`, status: http.StatusBadRequest},
+		{name: "invalid path", target: "/orders/abc", body: `{"note":"gift"}`, status: http.StatusBadRequest},
+		{name: "invalid query", target: "/orders/42?dry_run=maybe", body: `{"note":"gift"}`, status: http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, tt.target, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			var result httphelper.Response
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			if tt.status == http.StatusOK {
+				data, err := httphelper.ReadData[updateOrderResponse](result)
+				assert.NoError(t, err)
+				assert.Equal(t, updateOrderResponse{OrderID: 42, DryRun: true, Note: "gift"}, data)
+			}
+		})
+	}
+}
+
+func TestRouter(t *testing.T) {
+	type getOrderRequest struct {
+		OrderID int64 `path:"order_id,required"`
+	}
+
+	router := httphelper.NewRouter()
+	router.GET("/orders/{order_id}", httphelper.Typed(func(ctx context.Context, req getOrderRequest) (Data, error) {
+		return Data{Foo: strconv.FormatInt(req.OrderID, 10)}, nil
+	}))
+	router.DELETE("/orders/{order_id}", httphelper.Typed(func(ctx context.Context, req getOrderRequest) (any, error) {
+		return nil, exception.ErrorNotFound
+	}))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders/42", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	data, err := httphelper.ReadData[Data](result)
+	assert.NoError(t, err)
+	assert.Equal(t, "42", data.Foo)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/orders/42", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	routes := router.Routes()
+	assert.Len(t, routes, 2)
+	assert.Equal(t, http.MethodGet, routes[0].Method)
+	assert.Equal(t, "/orders/{order_id}", routes[0].Path)
+	assert.Equal(t, reflect.TypeOf(getOrderRequest{}), routes[0].Request)
+	assert.Equal(t, reflect.TypeOf(Data{}), routes[0].Response)
+}
+
+func TestRecover(t *testing.T) {
+	handler := httphelper.Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, exception.CodeInternal.String(), result.Code())
+
+	abort := httphelper.Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		abort.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
+
+func TestLogMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	handler := httphelper.LogMiddleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httphelper.Error(w, exception.New("order not found",
+			exception.WithStatus(exception.StatusNotFound),
+			exception.WithCode(exception.CodeNotFound),
+			exception.WithMessage("order not found"),
+		))
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders/42", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	var entry map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "WARN", entry["level"])
+	assert.Equal(t, http.MethodGet, entry["method"])
+	assert.Equal(t, "/orders/42", entry["path"])
+	assert.Equal(t, float64(http.StatusNotFound), entry["status"])
+	assert.Equal(t, exception.CodeNotFound.String(), entry["error_code"])
+	assert.Equal(t, "order not found", entry["error_message"])
+
+	buf.Reset()
+	handler = httphelper.LogMiddleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httphelper.OK(w, Data{Foo: "foo"})
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	entry = nil
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "INFO", entry["level"])
+	assert.Equal(t, float64(http.StatusOK), entry["status"])
+	assert.NotContains(t, entry, "error_code")
+}
+
+func TestCORS(t *testing.T) {
+	cors := httphelper.CORS{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+	handler := cors.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httphelper.OK(w, Data{Foo: "foo"})
+	}))
+
+	req := httptest.NewRequest(http.MethodOptions, "/", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "Authorization, Content-Type", rec.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", rec.Header().Get("Access-Control-Max-Age"))
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, exception.CodePermissionDenied.String(), result.Code())
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httphelper.OK(w, Data{Foo: "foo"})
+	})
+	handler = httphelper.CORS{AllowedOrigins: []string{"*"}}.Middleware(ok)
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", "https://any.example.com")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"))
+
+	assert.Panics(t, func() {
+		httphelper.CORS{AllowedOrigins: []string{"*"}, AllowCredentials: true}.Middleware(ok)
+	})
+}
+
+func TestRateLimit(t *testing.T) {
+	limit := httphelper.RateLimit{
+		Limit:  2,
+		Period: time.Minute,
+		Key:    httphelper.HeaderKey("X-API-Key"),
+	}
+	handler := limit.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httphelper.OK(w, Data{Foo: "foo"})
+	}))
+
+	request := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-API-Key", key)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request("a")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", rec.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, http.StatusOK, request("a").Code)
+
+	rec = request("a")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+	retry, err := strconv.Atoi(rec.Header().Get("Retry-After"))
+	assert.NoError(t, err)
+	assert.InDelta(t, 30, retry, 1)
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, exception.CodeResourceExhausted.String(), result.Code())
+
+	assert.Equal(t, http.StatusOK, request("b").Code)
+}
+
+func signJWT(secret []byte, claims map[string]any) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	payload, _ := json.Marshal(claims)
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestAuth(t *testing.T) {
+	secret := []byte("secret")
+	handler := httphelper.Auth(httphelper.JWTVerifier(secret))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := httphelper.PrincipalFromContext(r.Context())
+		assert.True(t, ok)
+		if !principal.HasRole("admin") {
+			httphelper.Error(w, exception.ErrorPermissionDenied)
+			return
+		}
+		httphelper.OK(w, Data{Foo: principal.Subject})
+	}))
+
+	request := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request(signJWT(secret, map[string]any{
+		"sub":   "user-1",
+		"roles": []string{"admin"},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	data, err := httphelper.ReadData[Data](result)
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", data.Foo)
+
+	exp := time.Now().Add(time.Hour).Unix()
+	rec = request(signJWT(secret, map[string]any{"sub": "user-2", "exp": exp}))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	for _, token := range []string{
+		"",
+		signJWT([]byte("other"), map[string]any{"sub": "user-1", "exp": exp}),
+		signJWT(secret, map[string]any{"sub": "user-1", "exp": time.Now().Add(-time.Hour).Unix()}),
+		signJWT(secret, map[string]any{"sub": "user-1"}),
+		signJWT(secret, map[string]any{"sub": "user-1", "exp": "never"}),
+	} {
+		rec = request(token)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+		result = httphelper.Response{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		assert.Equal(t, exception.CodeUnauthenticated.String(), result.Code())
+	}
+}
+
+func TestJWTVerifier_Claims(t *testing.T) {
+	secret := []byte("secret")
+	verifier := httphelper.JWTVerifier(secret,
+		httphelper.WithIssuer("https://auth.example.com"),
+		httphelper.WithAudience("orders"),
+	)
+	verify := func(claims map[string]any) error {
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+signJWT(secret, claims))
+		_, err := verifier(req)
+		return err
+	}
+
+	assert.NoError(t, verify(map[string]any{"iss": "https://auth.example.com", "aud": "orders"}))
+	assert.NoError(t, verify(map[string]any{"iss": "https://auth.example.com", "aud": []string{"billing", "orders"}}))
+	assert.Error(t, verify(map[string]any{"iss": "https://evil.example.com", "aud": "orders"}))
+	assert.Error(t, verify(map[string]any{"iss": "https://auth.example.com", "aud": "billing"}))
+	assert.Error(t, verify(map[string]any{"aud": "orders"}))
+
+	assert.Panics(t, func() { httphelper.JWTVerifier(nil) })
+	assert.Panics(t, func() { httphelper.JWTVerifier([]byte("")) })
+}
+
+func TestAPIKeyVerifier(t *testing.T) {
+	verifier := httphelper.APIKeyVerifier("X-API-Key", map[string]httphelper.Principal{
+		"key-1": {Subject: "billing"},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-API-Key", "key-1")
+	principal, err := verifier(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "billing", principal.Subject)
+
+	req.Header.Set("X-API-Key", "key-2")
+	_, err = verifier(req)
+	assert.Error(t, err)
+}
+
+func TestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	late := make(chan error, 1)
+	slow := httphelper.Timeout(10 * time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		httphelper.OK(w, Data{Foo: "late"})
+		rc := http.NewResponseController(w)
+		rc.Flush()
+		late <- rc.SetWriteDeadline(time.Now())
+	}))
+
+	rec := httptest.NewRecorder()
+	slow.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, exception.CodeDeadlineExceeded.String(), result.Code())
+	close(release)
+	assert.ErrorIs(t, <-late, http.ErrNotSupported)
+	assert.False(t, rec.Flushed)
+
+	fast := httphelper.Timeout(time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httphelper.Created(w, Data{Foo: "foo"}, httphelper.WithHeader("Location", "/orders/1"))
+	}))
+	rec = httptest.NewRecorder()
+	fast.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "/orders/1", rec.Header().Get("Location"))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+}
+
+func TestRequireContentType(t *testing.T) {
+	handler := httphelper.RequireContentType()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httphelper.NoContent(w)
+	}))
+
+	tests := []struct {
+		method      string
+		contentType string
+		body        string
+		want        int
+	}{
+		{http.MethodPost, "application/json", "{}", http.StatusNoContent},
+		{http.MethodPost, "application/json; charset=UTF-8", "{}", http.StatusNoContent},
+		{http.MethodPost, "application/json; charset=latin1", "{}", http.StatusUnsupportedMediaType},
+		{http.MethodPost, "text/plain", "{}", http.StatusUnsupportedMediaType},
+		{http.MethodPost, "", "{}", http.StatusUnsupportedMediaType},
+		{http.MethodGet, "", "", http.StatusNoContent},
+		{http.MethodDelete, "", "", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.contentType, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, tt.want, rec.Code)
+			if tt.want == http.StatusUnsupportedMediaType {
+				var result httphelper.Response
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+				assert.Equal(t, exception.CodeInvalidRequest.String(), result.Code())
+			}
+		})
+	}
+}
+
+func TestOnError(t *testing.T) {
+	type call struct {
+		path   string
+		err    error
+		status int
+	}
+	var calls []call
+	unregister := httphelper.OnError(func(r *http.Request, err error, status int) {
+		c := call{err: err, status: status}
+		if r != nil {
+			c.path = r.URL.Path
+		}
+		calls = append(calls, c)
+	})
+	t.Cleanup(unregister)
+
+	httphelper.Error(httptest.NewRecorder(), errException)
+	assert.Equal(t, []call{{err: errException, status: http.StatusBadRequest}}, calls)
+
+	type getOrderRequest struct {
+		OrderID int64 `path:"order_id,required"`
+	}
+	calls = nil
+	router := httphelper.NewRouter()
+	router.GET("/orders/{order_id}", httphelper.Typed(func(ctx context.Context, req getOrderRequest) (Data, error) {
+		return Data{}, exception.ErrorNotFound
+	}))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/42", nil))
+	assert.Equal(t, []call{{path: "/orders/42", err: exception.ErrorNotFound, status: http.StatusNotFound}}, calls)
+
+	calls = nil
+	httphelper.OK(httptest.NewRecorder(), Data{})
+	assert.Empty(t, calls)
+
+	unregister()
+	httphelper.Error(httptest.NewRecorder(), errException)
+	assert.Empty(t, calls)
+}
+
+func TestOnResponse(t *testing.T) {
+	type account struct {
+		Number string
+	}
+	t.Cleanup(httphelper.OnResponse(func(resp *httphelper.Response) {
+		if acc, ok := resp.Data.(account); ok {
+			acc.Number = "****" + acc.Number[len(acc.Number)-4:]
+			resp.Data = acc
+			resp.Warnings = append(resp.Warnings, httphelper.ErrorInfo{Code: "MASKED"})
+		}
+	}))
+
+	rec := httptest.NewRecorder()
+	httphelper.OK(rec, account{Number: "1234567890"})
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	data, err := httphelper.ReadData[account](result)
+	assert.NoError(t, err)
+	assert.Equal(t, "****7890", data.Number)
+	assert.Equal(t, "MASKED", result.Warnings[0].Code)
+
+	rec = httptest.NewRecorder()
+	httphelper.Error(rec, exception.New("not found", exception.WithStatus(exception.StatusNotFound), exception.WithData(account{Number: "1234567890"})))
+	assert.Contains(t, rec.Body.String(), `"Number":"****7890"`)
+}
+
+func TestWithStatusMapping(t *testing.T) {
+	mapping := httphelper.WithStatusMapping(map[string]int{
+		exception.CodeValidationFailed.String(): http.StatusBadRequest,
+	})
+
+	rec := httptest.NewRecorder()
+	httphelper.New(mapping).Error(rec, exception.ErrorValidationFailed)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, http.StatusBadRequest, result.Status)
+	assert.Equal(t, exception.CodeValidationFailed.String(), result.Code())
+
+	rec = httptest.NewRecorder()
+	httphelper.New(mapping, httphelper.WithProblemDetails(true)).Error(rec, exception.ErrorValidationFailed)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	httphelper.New(mapping).Error(rec, exception.ErrorNotFound)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestErrorClientClosedRequest(t *testing.T) {
+	rec := httptest.NewRecorder()
+	httphelper.Error(rec, fmt.Errorf("query orders: %w", context.Canceled))
+	assert.Equal(t, httphelper.StatusClientClosedRequest, rec.Code)
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, httphelper.CLIENT_CLOSED_REQUEST, result.Code())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec = httptest.NewRecorder()
+	httphelper.ErrorContext(ctx, rec, errGeneric)
+	assert.Equal(t, httphelper.StatusClientClosedRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	httphelper.ErrorContext(context.Background(), rec, errGeneric)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestOKWithWarnings(t *testing.T) {
+	rec := httptest.NewRecorder()
+	httphelper.OKWithWarnings(rec, Data{Foo: "foo"}, []httphelper.ErrorInfo{{
+		Code:    "ENRICHMENT_FAILED",
+		Message: "shipping estimate is unavailable",
+	}})
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.True(t, result.Success)
+	data, err := httphelper.ReadData[Data](result)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", data.Foo)
+	assert.Equal(t, []httphelper.ErrorInfo{{Code: "ENRICHMENT_FAILED", Message: "shipping estimate is unavailable"}}, result.Warnings)
+}
+
+func TestErrorFieldErrors(t *testing.T) {
+	errs := exception.ValidationErrors{
+		{Field: "name", Code: "required", Message: "name is required"},
+	}
+
+	rec := httptest.NewRecorder()
+	httphelper.Error(rec, errs)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.JSONEq(t, `{
+		"status": 422,
+		"data": null,
+		"success": false,
+		"error": {
+			"code": "VALIDATION_FAILED",
+			"message": "validation failed",
+			"detail": "validation failed: name: name is required",
+			"details": [{"field": "name", "code": "required", "message": "name is required"}]
+		}
+	}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	httphelper.Error(rec, exception.Wrap(errs, "create user",
+		exception.WithStatus(exception.StatusInvalidRequest),
+		exception.WithCode("INVALID_USER"),
+	))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, "INVALID_USER", result.Code())
+	assert.Len(t, result.ErrorInfo.Details, 1)
+}
+
+func TestLocaleMiddleware(t *testing.T) {
+	helper := httphelper.New(httphelper.WithTranslator(func(lang, code string) (string, bool) {
+		messages := map[string]map[string]string{
+			"id": {"ORDER_NOT_FOUND": "pesanan {order_id} tidak ditemukan"},
+		}
+		message, ok := messages[lang][code]
+		return message, ok
+	}))
+	err := exception.New("order not found",
+		exception.WithStatus(exception.StatusNotFound),
+		exception.WithCode("ORDER_NOT_FOUND"),
+		exception.WithMessage("order {order_id} not found"),
+		exception.WithDetail("order_id", 42),
+	)
+	handler := httphelper.LocaleMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		helper.Error(w, err)
+	}))
+
+	request := func(acceptLanguage string) (*httptest.ResponseRecorder, httphelper.Response) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Language", acceptLanguage)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		var result httphelper.Response
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		return rec, result
+	}
+
+	rec, result := request("fr;q=0.5, id-ID;q=0.9")
+	assert.Equal(t, "pesanan 42 tidak ditemukan", result.Message())
+	assert.Equal(t, "id", rec.Header().Get("Content-Language"))
+
+	rec, result = request("fr")
+	assert.Equal(t, "order 42 not found", result.Message())
+	assert.Empty(t, rec.Header().Get("Content-Language"))
+}
+
+func TestOKEncodeFailure(t *testing.T) {
+	var hooked error
+	t.Cleanup(httphelper.OnError(func(r *http.Request, err error, status int) {
+		if status == http.StatusInternalServerError {
+			hooked = err
+		}
+	}))
+
+	rec := httptest.NewRecorder()
+	httphelper.New().OK(rec, map[string]any{"callback": func() {}})
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.False(t, result.Success)
+	assert.Equal(t, httphelper.INTERNAL_SERVER_ERROR, result.Code())
+	assert.Contains(t, result.ErrorInfo.Detail, "encode response")
+	assert.ErrorContains(t, hooked, "encode response")
+}
+
+func TestResponse_UnmarshalJSON(t *testing.T) {
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal([]byte(`{"status":200,"success":true,"data":{"Foo":"foo"}}`), &result))
+	assert.Equal(t, json.RawMessage(`{"Foo":"foo"}`), result.Data)
+	data, err := httphelper.ReadData[Data](result)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", data.Foo)
+
+	result = httphelper.Response{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"status":404,"success":false,"data":null,"error":{"code":"NOT_FOUND","message":"not found"}}`), &result))
+	assert.Nil(t, result.Data)
+	assert.Equal(t, "NOT_FOUND", result.Code())
+}
+
+func TestError_Precomputed(t *testing.T) {
+	helper := httphelper.New(httphelper.WithIncludeDetails(false))
+
+	var bodies []string
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		helper.Error(rec, exception.ErrorResourceExhausted)
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, httphelper.ContentTypeJSON, rec.Header().Get("Content-Type"))
+		bodies = append(bodies, rec.Body.String())
+	}
+	assert.Equal(t, bodies[0], bodies[1])
+	assert.JSONEq(t, `{
+		"status": 429,
+		"success": false,
+		"data": null,
+		"error": {"code": "RESOURCE_EXHAUSTED", "message": "resource exhausted"}
+	}`, bodies[0])
+	assert.True(t, strings.HasSuffix(bodies[0], "\n"))
+
+	rec := httptest.NewRecorder()
+	helper.Error(rec, exception.New("rate limited",
+		exception.WithStatus(exception.StatusResourceExhausted),
+		exception.WithCode(exception.CodeResourceExhausted),
+		exception.WithMessage("resource exhausted"),
+		exception.WithDetail("limit", 10),
+	))
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.NotNil(t, result.ErrorInfo.Details)
+}
+
+func TestResponse_Err(t *testing.T) {
+	result := httphelper.Response{
+		Status:  http.StatusNotFound,
+		Success: false,
+		ErrorInfo: &httphelper.ErrorInfo{
+			Code:    "ORDER_NOT_FOUND",
+			Message: "order not found",
+			Detail:  "order 42 not found",
+			Details: map[string]any{"order_id": float64(42)},
+		},
+	}
+
+	err := result.Err()
+	var respErr *httphelper.ResponseError
+	assert.True(t, errors.As(err, &respErr))
+	assert.Equal(t, http.StatusNotFound, respErr.HTTPStatus())
+	assert.Equal(t, "ORDER_NOT_FOUND", respErr.Code())
+	assert.Equal(t, "order 42 not found", respErr.Error())
+
+	ex := respErr.Exception()
+	assert.True(t, exception.HasStatus(ex, exception.StatusNotFound))
+	assert.True(t, errors.Is(ex, err))
+	httpErr, ok := httphelper.AsHTTPError(ex)
+	assert.True(t, ok)
+	assert.Equal(t, "ORDER_NOT_FOUND", httpErr.Code())
+
+	unknown := httphelper.Response{Status: http.StatusBadGateway}
+	err = unknown.Err()
+	assert.True(t, errors.As(err, &respErr))
+	assert.Equal(t, httphelper.UNKNOWN_ERROR, respErr.Code())
+	assert.Nil(t, unknown.ErrorInfo)
+
+	success := httphelper.Response{Status: http.StatusOK, Success: true}
+	assert.NoError(t, success.Err())
+}
+
+func TestConfigure_Concurrent(t *testing.T) {
+	defer httphelper.Configure(httphelper.WithIncludeDetails(true))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			httphelper.Configure(httphelper.WithIncludeDetails(i%2 == 0))
+		}(i)
+		go func() {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			httphelper.Error(rec, errException)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestReadData_Memoized(t *testing.T) {
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal([]byte(`{"status":200,"success":true,"data":[{"Foo":"foo"}]}`), &result))
+
+	first, err := httphelper.ReadData[[]Data](result)
+	assert.NoError(t, err)
+	copied := result
+	second, err := httphelper.ReadData[[]Data](copied)
+	assert.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.NotSame(t, &first[0], &second[0])
+
+	first[0].Foo = "changed"
+	var target []Data
+	assert.NoError(t, httphelper.New().ReadData(result, &target))
+	assert.Equal(t, "foo", target[0].Foo)
+	assert.Equal(t, "foo", second[0].Foo)
+
+	var object httphelper.Response
+	assert.NoError(t, json.Unmarshal([]byte(`{"status":200,"success":true,"data":{"items":[{"Foo":"foo"}]}}`), &object))
+	nested, err := httphelper.ReadData[map[string]any](object)
+	assert.NoError(t, err)
+	nested["items"].([]any)[0].(map[string]any)["Foo"] = "changed"
+	nested["added"] = true
+	nested, err = httphelper.ReadData[map[string]any](object)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"items": []any{map[string]any{"Foo": "foo"}}}, nested)
+
+	other, err := httphelper.ReadData[[]map[string]string](result)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", other[0]["Foo"])
+
+	result.Data = json.RawMessage(`[{"Foo":"bar"}]`)
+	third, err := httphelper.ReadData[[]Data](result)
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", third[0].Foo)
+}
+
+func TestRaw(t *testing.T) {
+	rec := httptest.NewRecorder()
+	httphelper.Raw(rec, http.StatusAccepted, "text/plain", []byte("ok"))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, "text/plain", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "ok", rec.Body.String())
+
+	type tokenResponse struct {
+		AccessToken string `json:"access_token"`
+	}
+	router := httphelper.NewRouter()
+	router.POST("/oauth/token", httphelper.Typed(func(ctx context.Context, req struct{}) (tokenResponse, error) {
+		return tokenResponse{AccessToken: "token"}, nil
+	}), httphelper.WithoutEnvelope())
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/oauth/token", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"access_token": "token"}`, rec.Body.String())
+	assert.True(t, router.Routes()[0].NoEnvelope)
+}
+
+func TestStreamTrailers(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ndjson":
+			items := make(chan Data, 1)
+			errc := make(chan error, 1)
+			items <- Data{Foo: "foo"}
+			close(items)
+			errc <- exception.ErrorUnavailable
+			httphelper.StreamNDJSON(w, items, errc)
+		case "/stream":
+			httphelper.OKStream(w, func(enc *json.Encoder) error {
+				return enc.Encode(Data{Foo: "foo"})
+			})
+		case "/sse":
+			events := httphelper.SSE(w)
+			events.Send("update", Data{Foo: "foo"})
+			events.Close()
+		}
+	}))
+	defer ts.Close()
+
+	get := func(path string) http.Header {
+		resp, err := http.Get(ts.URL + path)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		_, err = io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		return resp.Trailer
+	}
+
+	trailer := get("/ndjson")
+	assert.Equal(t, httphelper.StreamFailed, trailer.Get(httphelper.TrailerStreamStatus))
+	assert.Equal(t, exception.CodeUnavailable.String(), trailer.Get(httphelper.TrailerErrorCode))
+	assert.Equal(t, "service unavailable", trailer.Get(httphelper.TrailerErrorMessage))
+
+	trailer = get("/stream")
+	assert.Equal(t, httphelper.StreamComplete, trailer.Get(httphelper.TrailerStreamStatus))
+	assert.Empty(t, trailer.Get(httphelper.TrailerErrorCode))
+
+	trailer = get("/sse")
+	assert.Equal(t, httphelper.StreamComplete, trailer.Get(httphelper.TrailerStreamStatus))
+}
+
+func TestReadDataPath(t *testing.T) {
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal([]byte(`{"status":200,"success":true,"data":{"result":{"user":{"Foo":"foo"},"items":[{"Bar":"bar"}]}}}`), &result))
+
+	user, err := httphelper.ReadDataPath[Data](result, "result.user")
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", user.Foo)
+
+	bar, err := httphelper.ReadDataPath[string](result, "result.items.0.Bar")
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", bar)
+
+	_, err = httphelper.ReadDataPath[Data](result, "result.account")
+	assert.EqualError(t, err, `response data has no field at "result.account"`)
+
+	_, err = httphelper.ReadDataPath[Data](result, "result.items.1")
+	assert.EqualError(t, err, `response data has no element at "result.items.1"`)
+
+	decoded := httphelper.Response{Status: http.StatusOK, Success: true, Data: map[string]any{"items": []Data{{Foo: "foo"}}}}
+	items, err := httphelper.ReadDataPath[[]Data](decoded, "items")
+	assert.NoError(t, err)
+	assert.Equal(t, []Data{{Foo: "foo"}}, items)
+}
+
+func TestStrictDecoding(t *testing.T) {
+	type item struct {
+		SKU      string `json:"sku"`
+		Quantity int    `json:"quantity"`
+	}
+	type order struct {
+		ID    string  `json:"id"`
+		Note  *string `json:"note"`
+		Items []item  `json:"items"`
+	}
+
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal([]byte(`{"status":200,"success":true,"data":{"id":"1","items":[{"sku":"A-1","qty":2}]}}`), &result))
+
+	var lenient order
+	assert.NoError(t, httphelper.New().ReadData(result, &lenient))
+
+	var strict order
+	err := httphelper.New(httphelper.WithStrictDecoding(true)).ReadData(result, &strict)
+	var errs exception.ValidationErrors
+	assert.True(t, errors.As(err, &errs))
+	assert.ElementsMatch(t, exception.ValidationErrors{
+		{Field: "items[0].qty", Code: "unexpected", Message: "unexpected field"},
+		{Field: "items[0].quantity", Code: "required", Message: "missing field"},
+	}, errs)
+
+	type createOrderRequest struct {
+		OrderID string `path:"order_id"`
+		SKU     string `json:"sku"`
+	}
+	httphelper.Configure(httphelper.WithStrictDecoding(true))
+	defer httphelper.Configure(httphelper.WithStrictDecoding(false))
+	handler := httphelper.Handle(func(ctx context.Context, req createOrderRequest) (string, error) {
+		return req.SKU, nil
+	})
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"sku":"A-1"}`)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"sku":"A-1","price":10}`)))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"field":"price"`)
+}
+
+func TestResponse_Raw(t *testing.T) {
+	body := []byte(`{"status":200,"success":true,"data":{"Foo":"foo"},"extra":true}`)
+	result, err := httphelper.DecodeResponse(body)
+	assert.NoError(t, err)
+	assert.Equal(t, body, result.Raw())
+
+	var unmarshaled httphelper.Response
+	assert.NoError(t, json.Unmarshal(body, &unmarshaled))
+	assert.Equal(t, body, unmarshaled.Raw())
+
+	assert.Nil(t, (&httphelper.Response{Status: http.StatusOK}).Raw())
+}
+
+func TestFromHTTPResponse(t *testing.T) {
+	newResponse := func(status int, contentType, body string) *http.Response {
+		return &http.Response{
+			StatusCode: status,
+			Header:     http.Header{"Content-Type": []string{contentType}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}
+	}
+
+	t.Run("envelope", func(t *testing.T) {
+		result, err := httphelper.FromHTTPResponse(newResponse(http.StatusOK, httphelper.ContentTypeJSON,
+			`{"status":200,"success":true,"data":{"Foo":"foo"}}`))
+		assert.NoError(t, err)
+		data, err := httphelper.ReadData[Data](result)
+		assert.NoError(t, err)
+		assert.Equal(t, "foo", data.Foo)
+	})
+
+	t.Run("non-envelope body", func(t *testing.T) {
+		result, err := httphelper.FromHTTPResponse(newResponse(http.StatusBadGateway, "text/html", "<html>Bad Gateway</html>"))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadGateway, result.Status)
+		assert.Equal(t, httphelper.UNKNOWN_ERROR, result.Code())
+		assert.Equal(t, "<html>Bad Gateway</html>", string(result.Raw()))
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		helper := httphelper.New(httphelper.WithMaxResponseSize(8))
+		_, err := helper.FromHTTPResponse(newResponse(http.StatusOK, httphelper.ContentTypeJSON,
+			`{"status":200,"success":true}`))
+		assert.EqualError(t, err, "response body exceeds 8 bytes")
+	})
+}
+
+func TestParseResponse(t *testing.T) {
+	data, err := httphelper.ParseResponse[Data](http.StatusOK, []byte(`{"status":200,"success":true,"data":{"Foo":"foo"}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", data.Foo)
+
+	_, err = httphelper.ParseResponse[Data](http.StatusNotFound,
+		[]byte(`{"status":404,"success":false,"data":null,"error":{"code":"ORDER_NOT_FOUND","message":"order not found"}}`))
+	assert.True(t, exception.HasStatus(err, exception.StatusNotFound))
+	code, _ := exception.AsErrorCode(err)
+	assert.Equal(t, "ORDER_NOT_FOUND", code.Code())
+
+	_, err = httphelper.ParseResponse[Data](http.StatusBadGateway, []byte("Bad Gateway"))
+	code, _ = exception.AsErrorCode(err)
+	assert.Equal(t, httphelper.UNKNOWN_ERROR, code.Code())
+}
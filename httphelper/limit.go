@@ -0,0 +1,137 @@
+package httphelper
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// URI_TOO_LONG is the error code used when the request URL exceeds the limits
+	URI_TOO_LONG = "URI_TOO_LONG"
+	// HEADERS_TOO_LARGE is the error code used when the request headers exceed the limits
+	HEADERS_TOO_LARGE = "HEADERS_TOO_LARGE"
+	// PAYLOAD_TOO_LARGE is the error code used when the request body exceeds the limits
+	PAYLOAD_TOO_LARGE = "PAYLOAD_TOO_LARGE"
+)
+
+// RequestLimits rejects requests with oversized URLs or headers before they
+// reach the handler, protecting downstream parsers from pathological inputs.
+// Zero values disable the corresponding limit.
+//
+// Example usage:
+//
+//	limits := httphelper.RequestLimits{
+//	    MaxURLLength:   4096,
+//	    MaxQueryParams: 50,
+//	    MaxHeaderCount: 64,
+//	    MaxHeaderBytes: 16 << 10,
+//	    OnReject: func(r *http.Request, code string) {
+//	        rejected.WithLabelValues(code).Inc()
+//	    },
+//	}
+//	http.ListenAndServe(":8080", limits.Middleware(mux))
+type RequestLimits struct {
+	// MaxURLLength is the maximum length of the request URI, rejected with 414
+	MaxURLLength int
+	// MaxQueryParams is the maximum number of query parameters, rejected with 414
+	MaxQueryParams int
+	// MaxHeaderCount is the maximum number of header values, rejected with 431
+	MaxHeaderCount int
+	// MaxHeaderBytes is the maximum total size of header names and values, rejected with 431
+	MaxHeaderBytes int
+	// OnReject is invoked with the error code of every rejected request
+	OnReject func(r *http.Request, code string)
+}
+
+// Middleware returns a handler enforcing the limits before calling next
+func (l RequestLimits) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := l.check(r); err != nil {
+			if l.OnReject != nil {
+				l.OnReject(r, err.code)
+			}
+			writeError(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// check returns the first limit exceeded by r, if any
+func (l RequestLimits) check(r *http.Request) *limitError {
+	uri := r.RequestURI
+	if uri == "" {
+		uri = r.URL.RequestURI()
+	}
+	if l.MaxURLLength > 0 && len(uri) > l.MaxURLLength {
+		return &limitError{
+			code:       URI_TOO_LONG,
+			httpStatus: http.StatusRequestURITooLong,
+			message:    "request url is too long",
+			detail:     fmt.Sprintf("url length %d exceeds %d", len(uri), l.MaxURLLength),
+		}
+	}
+	if l.MaxQueryParams > 0 && r.URL.RawQuery != "" {
+		if count := strings.Count(r.URL.RawQuery, "&") + 1; count > l.MaxQueryParams {
+			return &limitError{
+				code:       URI_TOO_LONG,
+				httpStatus: http.StatusRequestURITooLong,
+				message:    "request url is too long",
+				detail:     fmt.Sprintf("query parameter count %d exceeds %d", count, l.MaxQueryParams),
+			}
+		}
+	}
+
+	if l.MaxHeaderCount == 0 && l.MaxHeaderBytes == 0 {
+		return nil
+	}
+	var count, size int
+	for name, values := range r.Header {
+		count += len(values)
+		for _, value := range values {
+			size += len(name) + len(value)
+		}
+	}
+	if l.MaxHeaderCount > 0 && count > l.MaxHeaderCount {
+		return &limitError{
+			code:       HEADERS_TOO_LARGE,
+			httpStatus: http.StatusRequestHeaderFieldsTooLarge,
+			message:    "request headers are too large",
+			detail:     fmt.Sprintf("header count %d exceeds %d", count, l.MaxHeaderCount),
+		}
+	}
+	if l.MaxHeaderBytes > 0 && size > l.MaxHeaderBytes {
+		return &limitError{
+			code:       HEADERS_TOO_LARGE,
+			httpStatus: http.StatusRequestHeaderFieldsTooLarge,
+			message:    "request headers are too large",
+			detail:     fmt.Sprintf("header size %d bytes exceeds %d", size, l.MaxHeaderBytes),
+		}
+	}
+	return nil
+}
+
+// limitError is returned for requests rejected before reaching the handler
+type limitError struct {
+	code       string
+	httpStatus int
+	message    string
+	detail     string
+}
+
+func (e *limitError) Error() string {
+	return e.detail
+}
+
+func (e *limitError) HTTPStatus() int {
+	return e.httpStatus
+}
+
+func (e *limitError) Message() string {
+	return e.message
+}
+
+func (e *limitError) Code() string {
+	return e.code
+}
@@ -0,0 +1,117 @@
+package httphelper
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aeramu/apihelper/exception"
+)
+
+// Translator returns the message of an error code in the given language
+// tag, e.g. "id" or "pt-BR", reporting false when no translation exists.
+// Messages may contain the same named placeholders as WithMessageTemplates.
+type Translator func(lang, code string) (string, bool)
+
+// localeResponseWriter carries the languages accepted by the client to the writers
+type localeResponseWriter struct {
+	http.ResponseWriter
+	languages []string
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (w *localeResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// LocaleMiddleware makes Error and ErrorContext localize error messages for
+// the wrapped handler using the Translator set with WithTranslator. The
+// languages of the Accept-Language header are tried in order of preference,
+// each followed by its base language; the first translation found replaces
+// the message and sets the Content-Language header. Errors without a
+// translation keep their original message.
+//
+// Example usage:
+//
+//	httphelper.Configure(httphelper.WithTranslator(func(lang, code string) (string, bool) {
+//	    msg, ok := messages[lang][code]
+//	    return msg, ok
+//	}))
+//	http.ListenAndServe(":8080", httphelper.LocaleMiddleware(mux))
+func LocaleMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Language")
+		next.ServeHTTP(&localeResponseWriter{
+			ResponseWriter: w,
+			languages:      parseAcceptLanguage(r.Header.Get("Accept-Language")),
+		}, r)
+	})
+}
+
+// parseAcceptLanguage returns the language tags of header ordered by
+// preference, each followed by its base language
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag: tag, q: q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].q > tags[j].q
+	})
+
+	languages := make([]string, 0, len(tags)*2)
+	for _, t := range tags {
+		languages = append(languages, t.tag)
+		if base, _, ok := strings.Cut(t.tag, "-"); ok {
+			languages = append(languages, base)
+		}
+	}
+	return languages
+}
+
+// localize returns the message of code translated into the first language
+// accepted by the client, rendered with the details of err, and sets the
+// Content-Language header. It reports false when no translation applies.
+func (h *Helper) localize(w http.ResponseWriter, err error, code string) (string, bool) {
+	if h.cfg.translator == nil {
+		return "", false
+	}
+	lw, ok := unwrapWriter[*localeResponseWriter](w)
+	if !ok {
+		return "", false
+	}
+	for _, lang := range lw.languages {
+		message, ok := h.cfg.translator(lang, code)
+		if !ok {
+			continue
+		}
+		var detailsErr detailsError
+		if errors.As(err, &detailsErr) {
+			message = exception.RenderMessage(message, detailsErr.Details())
+		}
+		w.Header().Set("Content-Language", lang)
+		return message, true
+	}
+	return "", false
+}
@@ -0,0 +1,91 @@
+package httphelper
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// logResponseWriter records the status and error info of the response for LogMiddleware
+type logResponseWriter struct {
+	http.ResponseWriter
+	status  int
+	errInfo *ErrorInfo
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (w *logResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *logResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *logResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// recordError stores the error info written to w for LogMiddleware, if any
+func recordError(w http.ResponseWriter, errInfo *ErrorInfo) {
+	if lw, ok := unwrapWriter[*logResponseWriter](w); ok {
+		lw.errInfo = errInfo
+	}
+}
+
+// LogMiddleware returns a middleware logging every request with its method,
+// path, status and duration. Error responses written by Error and the other
+// writers also log the error code and message, so failed requests can be
+// filtered by exception code. Server errors are logged at error level,
+// client errors at warn level and other responses at info level.
+// A nil logger uses slog.Default.
+//
+// Example usage:
+//
+//	http.ListenAndServe(":8080", httphelper.LogMiddleware(logger)(mux))
+func LogMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			lw := &logResponseWriter{ResponseWriter: w}
+			next.ServeHTTP(lw, r)
+
+			l := logger
+			if l == nil {
+				l = slog.Default()
+			}
+			status := lw.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			attrs := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", status),
+				slog.Int64("duration_ms", time.Since(start).Milliseconds()),
+			}
+			if lw.errInfo != nil {
+				attrs = append(attrs,
+					slog.String("error_code", lw.errInfo.Code),
+					slog.String("error_message", lw.errInfo.Message),
+				)
+			}
+
+			level := slog.LevelInfo
+			switch {
+			case status >= http.StatusInternalServerError:
+				level = slog.LevelError
+			case status >= http.StatusBadRequest:
+				level = slog.LevelWarn
+			}
+			l.LogAttrs(r.Context(), level, fmt.Sprintf("%s %s", r.Method, r.URL.Path), attrs...)
+		})
+	}
+}
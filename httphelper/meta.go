@@ -0,0 +1,71 @@
+package httphelper
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+const (
+	// MetaTimestamp is the meta key holding the server time the response was written
+	MetaTimestamp = "timestamp"
+	// MetaDuration is the meta key holding the request processing time in milliseconds
+	MetaDuration = "duration_ms"
+)
+
+// MetaExtractor returns meta entries, such as request and trace IDs, from the request context
+type MetaExtractor func(ctx context.Context) map[string]any
+
+// metaResponseWriter carries the request context and start time to the writers
+type metaResponseWriter struct {
+	http.ResponseWriter
+	ctx   context.Context
+	start time.Time
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (w *metaResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// MetaMiddleware enables the response meta section for the wrapped handler.
+// Responses written by OK, Error and the other writers then include the
+// server timestamp, the processing duration and the entries returned by the
+// configured MetaExtractor.
+//
+// Example usage:
+//
+//	httphelper.Configure(httphelper.WithMetaExtractor(func(ctx context.Context) map[string]any {
+//	    return map[string]any{"request_id": requestid.FromContext(ctx)}
+//	}))
+//	http.ListenAndServe(":8080", httphelper.MetaMiddleware(mux))
+func MetaMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(&metaResponseWriter{
+			ResponseWriter: w,
+			ctx:            r.Context(),
+			start:          time.Now(),
+		}, r)
+	})
+}
+
+// withMeta merges the meta available for w into meta
+func (h *Helper) withMeta(w http.ResponseWriter, meta map[string]any) map[string]any {
+	mw, ok := unwrapWriter[*metaResponseWriter](w)
+	if !ok {
+		return meta
+	}
+
+	merged := make(map[string]any, len(meta)+2)
+	if h.cfg.metaExtractor != nil {
+		for k, v := range h.cfg.metaExtractor(mw.ctx) {
+			merged[k] = v
+		}
+	}
+	merged[MetaTimestamp] = time.Now().UTC().Format(time.RFC3339Nano)
+	merged[MetaDuration] = time.Since(mw.start).Milliseconds()
+	for k, v := range meta {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,137 @@
+package httphelper
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ContentTypeJSON is the content type of JSON responses
+	ContentTypeJSON = "application/json"
+	// ContentTypeXML is the content type of XML responses
+	ContentTypeXML = "application/xml"
+)
+
+// negotiatedResponseWriter carries the Accept header of the request to the writers
+type negotiatedResponseWriter struct {
+	http.ResponseWriter
+	accept string
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (w *negotiatedResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// NegotiationMiddleware enables content negotiation for the wrapped handler.
+// Responses written by OK, Error and the other writers are encoded with the
+// configured Codec the Accept header prefers, by q-value and then by order,
+// ignoring the media types refused with q=0: XML for application/xml
+// or text/xml, any codec added with WithCodec, and JSON otherwise. Bodies
+// that a codec cannot represent, such as custom envelopes built from maps in
+// XML, fall back to JSON.
+//
+// Example usage:
+//
+//	http.ListenAndServe(":8080", httphelper.NegotiationMiddleware(mux))
+func NegotiationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+		next.ServeHTTP(&negotiatedResponseWriter{
+			ResponseWriter: w,
+			accept:         r.Header.Get("Accept"),
+		}, r)
+	})
+}
+
+// negotiate returns the codec for the most preferred supported media type
+// listed in accept, defaulting to JSON
+func (h *Helper) negotiate(accept string) Codec {
+	mediaTypes, refused := parseAccept(accept)
+	for _, mediaType := range mediaTypes {
+		if mediaType == "*/*" || mediaType == "application/*" {
+			if !refused[ContentTypeJSON] {
+				return jsonCodec{}
+			}
+			if c, ok := h.codec(ContentTypeXML); ok && !refused[ContentTypeXML] {
+				return c
+			}
+			continue
+		}
+		if c, ok := h.codec(mediaType); ok {
+			return c
+		}
+	}
+	return jsonCodec{}
+}
+
+// parseAccept returns the media types of an Accept header ordered by
+// preference, along with the ones refused with q=0
+func parseAccept(header string) ([]string, map[string]bool) {
+	type weighted struct {
+		mediaType string
+		q         float64
+	}
+	var types []weighted
+	refused := map[string]bool{}
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if mediaType == "text/xml" {
+			mediaType = ContentTypeXML
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			refused[mediaType] = true
+			continue
+		}
+		types = append(types, weighted{mediaType: mediaType, q: q})
+	}
+	sort.SliceStable(types, func(i, j int) bool {
+		return types[i].q > types[j].q
+	})
+
+	mediaTypes := make([]string, len(types))
+	for i, t := range types {
+		mediaTypes[i] = t.mediaType
+	}
+	return mediaTypes, refused
+}
+
+// encodeBody writes body with the given status, encoded with the codec
+// negotiated for w. The body is encoded before anything is written, so
+// nothing is written when encoding fails.
+func (h *Helper) encodeBody(w http.ResponseWriter, status int, body any) error {
+	if nw, ok := unwrapWriter[*negotiatedResponseWriter](w); ok {
+		c := h.negotiate(nw.accept)
+		if b, err := c.Marshal(body); err == nil {
+			w.Header().Set("Content-Type", c.ContentType())
+			w.WriteHeader(status)
+			w.Write(b)
+			return nil
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(body); err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", ContentTypeJSON)
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+	return nil
+}
@@ -0,0 +1,85 @@
+package httphelper
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+// maxPrecomputedErrors bounds the number of cached error envelopes, so
+// errors with dynamic messages cannot grow the cache without limit
+const maxPrecomputedErrors = 1024
+
+// precomputedKey identifies an error envelope that only depends on its
+// status, code, message and help link
+type precomputedKey struct {
+	status  int
+	code    string
+	message string
+	help    string
+}
+
+var (
+	precomputedMu     sync.RWMutex
+	precomputedErrors = make(map[precomputedKey][]byte)
+)
+
+// precomputedErrorKey returns the cache key of resp when its encoded body
+// can be reused across requests: a JSON error envelope in the default format
+// without detail, details, data, warnings, pagination or meta
+func (h *Helper) precomputedErrorKey(w http.ResponseWriter, resp Response) (precomputedKey, bool) {
+	if resp.Success || resp.ErrorInfo == nil {
+		return precomputedKey{}, false
+	}
+	if _, ok := h.cfg.envelope.(defaultEnvelope); !ok {
+		return precomputedKey{}, false
+	}
+	if resp.Data != nil || resp.Pagination != nil || len(resp.Warnings) > 0 || len(resp.Meta) > 0 {
+		return precomputedKey{}, false
+	}
+	if resp.ErrorInfo.Detail != "" || resp.ErrorInfo.Details != nil {
+		return precomputedKey{}, false
+	}
+	if nw, ok := unwrapWriter[*negotiatedResponseWriter](w); ok {
+		if _, ok := h.negotiate(nw.accept).(jsonCodec); !ok {
+			return precomputedKey{}, false
+		}
+	}
+	return precomputedKey{
+		status:  resp.Status,
+		code:    resp.ErrorInfo.Code,
+		message: resp.ErrorInfo.Message,
+		help:    resp.ErrorInfo.Help,
+	}, true
+}
+
+// writePrecomputedError writes the cached JSON envelope of resp, encoding
+// and caching it on first use. It reports false when resp cannot be cached.
+func (h *Helper) writePrecomputedError(w http.ResponseWriter, resp Response) bool {
+	key, ok := h.precomputedErrorKey(w, resp)
+	if !ok {
+		return false
+	}
+
+	precomputedMu.RLock()
+	body, ok := precomputedErrors[key]
+	precomputedMu.RUnlock()
+	if !ok {
+		var buf bytes.Buffer
+		if err := json.NewEncoder(&buf).Encode(resp); err != nil {
+			return false
+		}
+		body = buf.Bytes()
+		precomputedMu.Lock()
+		if len(precomputedErrors) < maxPrecomputedErrors {
+			precomputedErrors[key] = body
+		}
+		precomputedMu.Unlock()
+	}
+
+	w.Header().Set("Content-Type", ContentTypeJSON)
+	w.WriteHeader(resp.Status)
+	w.Write(body)
+	return true
+}
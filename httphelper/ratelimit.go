@@ -0,0 +1,171 @@
+package httphelper
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aeramu/apihelper/exception"
+)
+
+// KeyFunc extracts the key a request is rate limited by
+type KeyFunc func(r *http.Request) string
+
+// ClientIP is a KeyFunc limiting requests per client IP, taken from the
+// request remote address
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// HeaderKey returns a KeyFunc limiting requests per value of the given
+// header, such as an API key
+func HeaderKey(name string) KeyFunc {
+	return func(r *http.Request) string {
+		return r.Header.Get(name)
+	}
+}
+
+// RateLimit limits requests with a token bucket per key. Every request
+// consumes one token; buckets hold up to Burst tokens and refill at
+// Limit tokens per Period. Requests exceeding the budget are rejected with
+// a RESOURCE_EXHAUSTED envelope and a Retry-After header. Every response
+// carries the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers.
+//
+// Example usage:
+//
+//	limit := httphelper.RateLimit{
+//	    Limit:  100,
+//	    Period: time.Minute,
+//	    Key:    httphelper.HeaderKey("X-API-Key"),
+//	}
+//	http.ListenAndServe(":8080", limit.Middleware(mux))
+type RateLimit struct {
+	// Limit is the number of requests allowed per Period
+	Limit int
+	// Period is the window Limit applies to, defaulting to one second
+	Period time.Duration
+	// Burst is the bucket capacity, defaulting to Limit
+	Burst int
+	// Key extracts the rate limiting key, defaulting to ClientIP
+	Key KeyFunc
+}
+
+// Middleware returns a handler enforcing the rate limit before calling next.
+// Each call creates its own set of buckets.
+func (l RateLimit) Middleware(next http.Handler) http.Handler {
+	if l.Period <= 0 {
+		l.Period = time.Second
+	}
+	if l.Burst <= 0 {
+		l.Burst = l.Limit
+	}
+	if l.Key == nil {
+		l.Key = ClientIP
+	}
+	limiter := &rateLimiter{
+		capacity: float64(l.Burst),
+		rate:     float64(l.Limit) / l.Period.Seconds(),
+		buckets:  make(map[string]*bucket),
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowed, remaining, reset, retry := limiter.take(l.Key(r), time.Now())
+
+		header := w.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(l.Burst))
+		header.Set("RateLimit-Remaining", strconv.Itoa(remaining))
+		header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(reset)))
+		if !allowed {
+			header.Set("Retry-After", strconv.Itoa(ceilSeconds(retry)))
+			writeError(w, r, exception.New("rate limit exceeded",
+				exception.WithStatus(exception.StatusResourceExhausted),
+				exception.WithCode(exception.CodeResourceExhausted),
+				exception.WithMessage("rate limit exceeded"),
+			))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ceilSeconds rounds d up to whole seconds
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// bucket is the token bucket of a single key
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter holds the token buckets of a RateLimit middleware
+type rateLimiter struct {
+	mu        sync.Mutex
+	capacity  float64
+	rate      float64
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// take consumes a token from the bucket of key, returning whether the
+// request is allowed, the remaining tokens, the time until the bucket is
+// full again and, when rejected, the time until a token is available
+func (l *rateLimiter) take(key string, now time.Time) (allowed bool, remaining int, reset, retry time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.capacity, last: now}
+		l.buckets[key] = b
+	}
+	if l.rate > 0 {
+		b.tokens = math.Min(l.capacity, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		allowed = true
+	} else if l.rate > 0 {
+		retry = l.duration(1 - b.tokens)
+	} else {
+		retry = time.Duration(math.MaxInt64)
+	}
+	if l.rate > 0 {
+		reset = l.duration(l.capacity - b.tokens)
+	}
+	return allowed, int(b.tokens), reset, retry
+}
+
+// duration returns the time needed to refill the given number of tokens
+func (l *rateLimiter) duration(tokens float64) time.Duration {
+	return time.Duration(tokens / l.rate * float64(time.Second))
+}
+
+// sweep drops buckets that have refilled completely, at most once per full
+// refill window, so idle keys don't accumulate
+func (l *rateLimiter) sweep(now time.Time) {
+	if l.rate <= 0 {
+		return
+	}
+	window := l.duration(l.capacity)
+	if now.Sub(l.lastSweep) < window {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= window {
+			delete(l.buckets, key)
+		}
+	}
+}
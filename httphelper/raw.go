@@ -0,0 +1,60 @@
+package httphelper
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Raw writes body as is with the given status and content type, for
+// payloads whose format is dictated by a third party, such as webhook
+// acknowledgements or OAuth callbacks. Unlike writing to w directly, error
+// statuses invoke the OnError hooks.
+//
+// Example usage:
+//
+//	httphelper.Raw(w, http.StatusOK, "text/plain", []byte("ok"))
+//
+// Parameters:
+//   - w: The HTTP response writer
+//   - status: The HTTP status code of the response
+//   - contentType: The Content-Type header of the response, omitted when empty
+//   - body: The exact bytes of the response body
+func Raw(w http.ResponseWriter, status int, contentType string, body []byte) {
+	std().Raw(w, status, contentType, body)
+}
+
+// Raw writes body as is with the given status and content type
+func (h *Helper) Raw(w http.ResponseWriter, status int, contentType string, body []byte) {
+	if contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.WriteHeader(status)
+	w.Write(body)
+	if status >= http.StatusBadRequest {
+		runErrorHooks(w, fmt.Errorf("raw response with status %d", status), status)
+	}
+}
+
+// noEnvelopeResponseWriter makes the writers encode successful data without the envelope
+type noEnvelopeResponseWriter struct {
+	http.ResponseWriter
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (w *noEnvelopeResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// NoEnvelope makes OK and the other success writers encode the data as the
+// whole body, without the envelope, for the wrapped handler. Errors still
+// use the envelope, and the OnResponse hooks still run, so masking and
+// logging keep working. Router routes can use WithoutEnvelope instead.
+//
+// Example usage:
+//
+//	mux.Handle("POST /webhooks/stripe", httphelper.NoEnvelope(stripeWebhook))
+func NoEnvelope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(&noEnvelopeResponseWriter{ResponseWriter: w}, r)
+	})
+}
@@ -0,0 +1,42 @@
+package httphelper
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/aeramu/apihelper/exception"
+)
+
+// Recover catches panics in the wrapped handler, logs them with their stack
+// trace and writes the standard INTERNAL error response, instead of letting
+// net/http close the connection without a response.
+// Panics with http.ErrAbortHandler are propagated to abort the response.
+//
+// Example usage:
+//
+//	http.ListenAndServe(":8080", httphelper.Recover(mux))
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if err, ok := v.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(v)
+			}
+
+			err := exception.FromPanic(v)
+			slog.ErrorContext(r.Context(), "panic recovered",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Any("error", err),
+				slog.String("stack", string(debug.Stack())),
+			)
+			writeError(w, r, err)
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
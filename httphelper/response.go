@@ -1,5 +1,10 @@
 package httphelper
 
+import (
+	"bytes"
+	"encoding/json"
+)
+
 const (
 	
 	// UNKNOWN_ERROR is the error code used when the error type cannot be determined
@@ -13,15 +18,72 @@ const (
 // making it easier to handle API responses in a uniform way.
 type Response struct {
 	// Status represents the HTTP status code of the response (e.g., 200, 404, 500)
-	Status int `json:"status"`
+	Status int `json:"status" xml:"status"`
 	// Success indicates whether the request was processed successfully
-	Success bool `json:"success"`
+	Success bool `json:"success" xml:"success"`
 	// Data contains the response payload for successful requests
 	// For error responses, this field will be null
-	Data any `json:"data"`
+	// Decoded JSON responses hold the raw payload as a json.RawMessage, so
+	// ReadData unmarshals it only once
+	Data any `json:"data" xml:"data,omitempty"`
 	// ErrorInfo contains error details when Success is false
 	// This field is omitted for successful responses
-	ErrorInfo *ErrorInfo `json:"error,omitempty"`
+	ErrorInfo *ErrorInfo `json:"error,omitempty" xml:"error,omitempty"`
+	// Pagination contains paging information for list responses
+	// This field is omitted for non-list responses
+	Pagination *Page `json:"pagination,omitempty" xml:"pagination,omitempty"`
+	// Warnings lists non-fatal issues that occurred while processing the request
+	// This field is omitted when there are no warnings
+	Warnings []ErrorInfo `json:"warnings,omitempty" xml:"warnings>warning,omitempty"`
+	// Meta contains additional information about the response
+	// This field is omitted when empty, XML responses list it as entry elements
+	Meta map[string]any `json:"meta,omitempty" xml:"-"`
+
+	// cache memoizes the values decoded by ReadData for decoded responses
+	cache *dataCache
+	// raw is the body the response was decoded from
+	raw []byte
+}
+
+// rawResponse is the JSON form of Response used when decoding, keeping the
+// payload undecoded
+type rawResponse struct {
+	Status     int             `json:"status"`
+	Success    bool            `json:"success"`
+	Data       json.RawMessage `json:"data"`
+	ErrorInfo  *ErrorInfo      `json:"error,omitempty"`
+	Pagination *Page           `json:"pagination,omitempty"`
+	Warnings   []ErrorInfo     `json:"warnings,omitempty"`
+	Meta       map[string]any  `json:"meta,omitempty"`
+}
+
+// response converts raw into a Response, with a nil Data for a null payload
+func (raw rawResponse) response() Response {
+	var data any
+	if len(raw.Data) > 0 && string(raw.Data) != "null" {
+		data = raw.Data
+	}
+	return Response{
+		Status:     raw.Status,
+		Success:    raw.Success,
+		Data:       data,
+		ErrorInfo:  raw.ErrorInfo,
+		Pagination: raw.Pagination,
+		Warnings:   raw.Warnings,
+		Meta:       raw.Meta,
+		cache:      &dataCache{raw: raw.Data},
+	}
+}
+
+// UnmarshalJSON decodes the envelope, capturing Data as a json.RawMessage
+func (r *Response) UnmarshalJSON(b []byte) error {
+	var raw rawResponse
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*r = raw.response()
+	r.raw = bytes.Clone(b)
+	return nil
 }
 
 // ErrorInfo provides structured error information for API responses.
@@ -30,15 +92,36 @@ type Response struct {
 type ErrorInfo struct {
 	// Code is a machine-readable identifier for the error type
 	// Examples: "INVALID_INPUT", "RESOURCE_NOT_FOUND"
-	Code string `json:"code"`
+	Code string `json:"code" xml:"code"`
 	// Message is a human-readable description of the error
-	Message string `json:"message"`
+	Message string `json:"message" xml:"message"`
 	// Detail is a technical description of the error (optional)
 	// This provides more specific information about what went wrong
-	Detail string `json:"detail,omitempty"`
+	Detail string `json:"detail,omitempty" xml:"detail,omitempty"`
 	// Details contains additional error context (optional)
 	// This can be structured data providing more information about the error
-	Details any `json:"details,omitempty"`
+	// XML responses list maps as entry elements and slices as item elements
+	Details any `json:"details,omitempty" xml:"-"`
+	// Help is a link to documentation about the error (optional)
+	Help string `json:"help,omitempty" xml:"help,omitempty"`
+}
+
+// Page describes the position of a list response within the full result set.
+// Offset-based endpoints set Offset, cursor-based endpoints set NextCursor.
+type Page struct {
+	// Total is the total number of items across all pages
+	Total int `json:"total" xml:"total"`
+	// Limit is the maximum number of items in a page
+	Limit int `json:"limit" xml:"limit"`
+	// Offset is the number of items skipped before this page
+	Offset int `json:"offset" xml:"offset"`
+	// NextCursor is an opaque cursor to fetch the next page, empty on the last page
+	NextCursor string `json:"next_cursor,omitempty" xml:"next_cursor,omitempty"`
+}
+
+// HasNext reports whether there are more items after this page
+func (p Page) HasNext() bool {
+	return p.NextCursor != "" || p.Offset+p.Limit < p.Total
 }
 
 func (r *Response) IsSuccess() bool {
@@ -77,16 +160,21 @@ func (r *Response) Message() string {
 	return err.Message
 }
 
+// Err returns a *ResponseError describing a failed response, nil for
+// successful ones. Responses without error information are reported with
+// the UNKNOWN_ERROR code. The receiver is not modified.
 func (r *Response) Err() error {
 	if r.IsSuccess() {
 		return nil
 	}
-	err := r.getError()
-	if err == nil {
+	info := r.getError()
+	if info == nil {
 		return nil
 	}
-	r.ErrorInfo = err
-	return r
+	return &ResponseError{
+		Status: r.Status,
+		Info:   *info,
+	}
 }
 
 func (r *Response) getError() *ErrorInfo {
@@ -101,3 +189,18 @@ func (r *Response) getError() *ErrorInfo {
 	}
 	return r.ErrorInfo
 }
+
+// Raw returns the body the response was decoded from, or nil for responses
+// not decoded from the wire. It lets callers log or re-parse the original
+// payload when the envelope does not match expectations.
+func (r *Response) Raw() []byte {
+	return r.raw
+}
+
+// Page returns the pagination information of the response, if any
+func (r *Response) Page() (Page, bool) {
+	if r.Pagination == nil {
+		return Page{}, false
+	}
+	return *r.Pagination, true
+}
@@ -0,0 +1,72 @@
+package httphelper
+
+import "github.com/aeramu/apihelper/exception"
+
+// ResponseError is the error of a failed Response, returned by Response.Err
+// and ReadData. It implements HTTPError, so it can be passed to Error to
+// forward an upstream failure as is, or converted with Exception.
+//
+// Example usage:
+//
+//	var respErr *httphelper.ResponseError
+//	if errors.As(err, &respErr) && respErr.Code() == "ORDER_NOT_FOUND" {
+//	    ...
+//	}
+type ResponseError struct {
+	// Status is the HTTP status code of the response
+	Status int
+	// Info is the error information of the response
+	Info ErrorInfo
+}
+
+// Error returns the error detail, falling back to the message and the code
+func (e *ResponseError) Error() string {
+	switch {
+	case e.Info.Detail != "":
+		return e.Info.Detail
+	case e.Info.Message != "":
+		return e.Info.Message
+	default:
+		return e.Info.Code
+	}
+}
+
+// HTTPStatus returns the HTTP status code of the response
+func (e *ResponseError) HTTPStatus() int {
+	return e.Status
+}
+
+// Code returns the error code of the response
+func (e *ResponseError) Code() string {
+	return e.Info.Code
+}
+
+// Message returns the error message of the response
+func (e *ResponseError) Message() string {
+	return e.Info.Message
+}
+
+// HelpURL returns the documentation link of the error, if any
+func (e *ResponseError) HelpURL() string {
+	return e.Info.Help
+}
+
+// Exception converts the error into an exception, mapping the HTTP status
+// with exception.StatusFromHTTP and keeping the code and message
+func (e *ResponseError) Exception() error {
+	opts := []exception.ErrorOption{
+		exception.WithStatus(exception.StatusFromHTTP(e.Status)),
+		exception.WithCode(exception.Code(e.Info.Code)),
+		exception.WithMessage(e.Info.Message),
+		exception.WithError(e),
+	}
+	if e.Info.Help != "" {
+		opts = append(opts, exception.WithHelpURL(e.Info.Help))
+	}
+	if details, ok := e.Info.Details.(map[string]any); ok {
+		for k, v := range details {
+			opts = append(opts, exception.WithDetail(k, v))
+		}
+	}
+	return exception.New("upstream error", opts...)
+}
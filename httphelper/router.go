@@ -0,0 +1,131 @@
+package httphelper
+
+import (
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+// Route describes a route registered on a Router
+type Route struct {
+	// Method is the HTTP method of the route
+	Method string
+	// Path is the http.ServeMux path pattern of the route
+	Path string
+	// Request is the type the request is bound into
+	Request reflect.Type
+	// Response is the type of the response data
+	Response reflect.Type
+	// NoEnvelope reports whether the response data is written without the envelope
+	NoEnvelope bool
+	// Errors lists the errors the route is documented to return, see WithErrors
+	Errors []error
+}
+
+// RouteOption customizes a route registered on a Router
+type RouteOption func(*Route)
+
+// WithoutEnvelope makes the route write its response data as the whole
+// body, without the envelope, see NoEnvelope
+func WithoutEnvelope() RouteOption {
+	return func(r *Route) {
+		r.NoEnvelope = true
+	}
+}
+
+// WithErrors declares the errors the route may return, so documentation
+// tooling lists their codes and statuses for the route. Errors that do not
+// implement HTTPError are ignored by the tooling.
+//
+// Example usage:
+//
+//	router.GET("/orders/{order_id}", httphelper.Typed(getOrder),
+//	    httphelper.WithErrors(ErrOrderNotFound, ErrOrderForbidden),
+//	)
+func WithErrors(errs ...error) RouteOption {
+	return func(r *Route) {
+		r.Errors = append(r.Errors, errs...)
+	}
+}
+
+// Router registers typed handlers on an http.ServeMux and records their
+// request and response types, so documentation and validation tooling can
+// be built from the routes while the router stays a plain http.Handler.
+//
+// Example usage:
+//
+//	router := httphelper.NewRouter()
+//	router.GET("/orders/{order_id}", httphelper.Typed(getOrder))
+//	router.POST("/orders", httphelper.Typed(createOrder))
+//	http.ListenAndServe(":8080", router)
+type Router struct {
+	mux    *http.ServeMux
+	mu     sync.RWMutex
+	routes []Route
+}
+
+// NewRouter creates an empty Router
+func NewRouter() *Router {
+	return &Router{mux: http.NewServeMux()}
+}
+
+// GET registers a handler for GET requests on path
+func (rt *Router) GET(path string, h TypedHandler, opts ...RouteOption) {
+	rt.Handle(http.MethodGet, path, h, opts...)
+}
+
+// POST registers a handler for POST requests on path
+func (rt *Router) POST(path string, h TypedHandler, opts ...RouteOption) {
+	rt.Handle(http.MethodPost, path, h, opts...)
+}
+
+// PUT registers a handler for PUT requests on path
+func (rt *Router) PUT(path string, h TypedHandler, opts ...RouteOption) {
+	rt.Handle(http.MethodPut, path, h, opts...)
+}
+
+// PATCH registers a handler for PATCH requests on path
+func (rt *Router) PATCH(path string, h TypedHandler, opts ...RouteOption) {
+	rt.Handle(http.MethodPatch, path, h, opts...)
+}
+
+// DELETE registers a handler for DELETE requests on path
+func (rt *Router) DELETE(path string, h TypedHandler, opts ...RouteOption) {
+	rt.Handle(http.MethodDelete, path, h, opts...)
+}
+
+// Handle registers a handler for requests with the given method on path.
+// It panics if the pattern conflicts with an existing route, like http.ServeMux.
+func (rt *Router) Handle(method, path string, h TypedHandler, opts ...RouteOption) {
+	route := Route{
+		Method:   method,
+		Path:     path,
+		Request:  h.RequestType(),
+		Response: h.ResponseType(),
+	}
+	for _, opt := range opts {
+		opt(&route)
+	}
+
+	var handler http.Handler = h
+	if route.NoEnvelope {
+		handler = NoEnvelope(handler)
+	}
+	rt.mux.Handle(method+" "+path, handler)
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.routes = append(rt.routes, route)
+}
+
+// Routes returns the registered routes in registration order
+func (rt *Router) Routes() []Route {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+	return append([]Route(nil), rt.routes...)
+}
+
+// ServeHTTP dispatches the request to the matching route
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rt.mux.ServeHTTP(withRequest(w, r), r)
+}
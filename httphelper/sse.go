@@ -0,0 +1,146 @@
+package httphelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ContentTypeEventStream is the content type of Server-Sent Events streams
+	ContentTypeEventStream = "text/event-stream"
+	// EventError is the event name of error events written by EventSender.Error
+	EventError = "error"
+)
+
+// EventSender writes Server-Sent Events to a response.
+// It is safe for concurrent use, so heartbeats can run alongside the sender.
+type EventSender struct {
+	mu     sync.Mutex
+	w      http.ResponseWriter
+	rc     *http.ResponseController
+	failed bool
+}
+
+// SSE starts a Server-Sent Events stream on w and returns its sender.
+// Event data is JSON encoded, and errors are sent as error events carrying
+// the standard error envelope so clients reuse the package's error codes.
+// Call Close when the stream ends successfully so the Stream-Status trailer
+// reports it as complete; Error sets the error trailers instead.
+//
+// Example usage:
+//
+//	events := httphelper.SSE(w)
+//	stop := events.Heartbeat(15 * time.Second)
+//	defer stop()
+//	for update := range updates {
+//	    if err := events.Send("order.updated", update); err != nil {
+//	        return
+//	    }
+//	}
+//	events.Close()
+//
+// Parameters:
+//   - w: The HTTP response writer
+//
+// Returns:
+//   - The sender writing events to w
+func SSE(w http.ResponseWriter) *EventSender {
+	w.Header().Set("Content-Type", ContentTypeEventStream)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	announceTrailers(w)
+	w.WriteHeader(http.StatusOK)
+
+	s := &EventSender{w: w, rc: http.NewResponseController(w)}
+	s.rc.Flush()
+	return s
+}
+
+// Send writes an event with the JSON encoded data.
+// An empty event name sends an unnamed message event.
+func (s *EventSender) Send(event string, data any) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode event data: %w", err)
+	}
+
+	var frame strings.Builder
+	if event != "" {
+		frame.WriteString("event: ")
+		frame.WriteString(strings.NewReplacer("\r", "", "\n", "").Replace(event))
+		frame.WriteString("\n")
+	}
+	frame.WriteString("data: ")
+	frame.Write(b)
+	frame.WriteString("\n\n")
+	return s.write(frame.String())
+}
+
+// Error writes an error event carrying the error envelope of err and sets
+// the error trailers of the stream
+func (s *EventSender) Error(err error) error {
+	errInfo, httpStatus := std().errorInfo(err)
+	s.mu.Lock()
+	writeTrailers(s.w, &errInfo)
+	s.failed = true
+	s.mu.Unlock()
+	return s.Send(EventError, Response{
+		Status:    httpStatus,
+		Success:   false,
+		ErrorInfo: &errInfo,
+	})
+}
+
+// Close marks the stream as complete in the Stream-Status trailer, unless
+// an error was sent. The trailer is written when the handler returns.
+func (s *EventSender) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.failed {
+		writeTrailers(s.w, nil)
+	}
+}
+
+// Heartbeat writes a comment every interval to keep idle connections open
+// through proxies, until a write fails or the returned stop function is
+// called. stop waits for the heartbeat to finish and must be called before
+// the handler returns.
+func (s *EventSender) Heartbeat(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := s.write(": heartbeat\n\n"); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+		<-stopped
+	}
+}
+
+// write writes a frame and flushes it to the client
+func (s *EventSender) write(frame string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.w.Write([]byte(frame)); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
@@ -0,0 +1,153 @@
+package httphelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ContentTypeNDJSON is the content type of newline-delimited JSON streams
+const ContentTypeNDJSON = "application/x-ndjson"
+
+// StreamError is the terminal record written when a stream fails after the
+// response headers were sent
+type StreamError struct {
+	// ErrorInfo describes the failure in the standard error shape
+	ErrorInfo ErrorInfo `json:"error"`
+}
+
+// StreamNDJSON writes the values received from items as newline-delimited
+// JSON, flushing whenever no item is immediately available so consumers see
+// rows as they are produced without a flush per row.
+//
+// Once items is closed, the error received from errc, if any, is written as
+// a terminal StreamError record. The producer must send at most one value on
+// errc, or close it, after closing items; errc may be nil.
+//
+// Writing stops at the first failed write, e.g. when the client disconnects,
+// and the error is returned so the caller can stop the producer, typically
+// by cancelling the request context.
+//
+// The Stream-Status, Error-Code and Error-Message trailers report how the
+// stream ended, so clients can tell truncated streams from complete ones.
+//
+// Example usage:
+//
+//	rows := make(chan Row)
+//	errc := make(chan error, 1)
+//	go func() {
+//	    defer close(rows)
+//	    errc <- repo.Export(r.Context(), rows)
+//	}()
+//	httphelper.StreamNDJSON(w, rows, errc)
+//
+// Parameters:
+//   - w: The HTTP response writer
+//   - items: The channel of values to write
+//   - errc: The channel carrying the producer error
+//
+// Returns:
+//   - An error if writing to w fails
+func StreamNDJSON[T any](w http.ResponseWriter, items <-chan T, errc <-chan error) error {
+	w.Header().Set("Content-Type", ContentTypeNDJSON)
+	announceTrailers(w)
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	enc := json.NewEncoder(w)
+	for {
+		var item T
+		var ok bool
+		select {
+		case item, ok = <-items:
+		default:
+			rc.Flush()
+			item, ok = <-items
+		}
+		if !ok {
+			break
+		}
+		if err := enc.Encode(item); err != nil {
+			return err
+		}
+	}
+
+	var failure *ErrorInfo
+	if errc != nil {
+		if err := <-errc; err != nil {
+			errInfo, _ := std().errorInfo(err)
+			if err := enc.Encode(StreamError{ErrorInfo: errInfo}); err != nil {
+				return err
+			}
+			failure = &errInfo
+		}
+	}
+	writeTrailers(w, failure)
+	rc.Flush()
+	return nil
+}
+
+// OKStream writes a successful response whose data field is an array
+// streamed by fn, without buffering the whole payload in memory. Each value
+// encoded with enc becomes one element of the array.
+//
+// The response is always written with status 200 since the headers are sent
+// before fn runs. If fn fails, the envelope is completed with success set to
+// false and the error info of the failure, so clients decoding the body see
+// the error, and the Stream-Status, Error-Code and Error-Message trailers
+// report it too. Streamed responses use the standard envelope regardless of
+// the configured Envelope.
+//
+// Example usage:
+//
+//	httphelper.OKStream(w, func(enc *json.Encoder) error {
+//	    return repo.EachOrder(r.Context(), func(order Order) error {
+//	        return enc.Encode(order)
+//	    })
+//	})
+//
+// Parameters:
+//   - w: The HTTP response writer
+//   - fn: The function encoding the array elements
+//
+// Returns:
+//   - The error returned by fn, or an error if writing to w fails
+func OKStream(w http.ResponseWriter, fn func(enc *json.Encoder) error) error {
+	w.Header().Set("Content-Type", ContentTypeJSON)
+	announceTrailers(w)
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := io.WriteString(w, `{"data":[`); err != nil {
+		return err
+	}
+	if err := fn(json.NewEncoder(&elementWriter{w: w})); err != nil {
+		errInfo, httpStatus := std().errorInfo(err)
+		b, _ := json.Marshal(errInfo)
+		fmt.Fprintf(w, `],"status":%d,"success":false,"error":%s}`, httpStatus, b)
+		writeTrailers(w, &errInfo)
+		return err
+	}
+	if _, err := fmt.Fprintf(w, `],"status":%d,"success":true}`, http.StatusOK); err != nil {
+		return err
+	}
+	writeTrailers(w, nil)
+	return nil
+}
+
+// elementWriter separates the values written by a json.Encoder with commas,
+// relying on the encoder writing each value with a single Write call
+type elementWriter struct {
+	w       io.Writer
+	written bool
+}
+
+func (w *elementWriter) Write(p []byte) (int, error) {
+	if w.written {
+		if _, err := io.WriteString(w.w, ","); err != nil {
+			return 0, err
+		}
+	}
+	w.written = true
+	return w.w.Write(p)
+}
@@ -0,0 +1,151 @@
+package httphelper
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/aeramu/apihelper/exception"
+)
+
+// checkFields compares the JSON object keys in data with the fields of the
+// type target points to, returning a ValidationErrors listing every
+// unexpected field and every missing field that is neither a pointer, tagged
+// omitempty nor bound from the query or path. Values that are not objects are left to json.Unmarshal.
+func checkFields(data []byte, target any) error {
+	var errs exception.ValidationErrors
+	checkValue(&errs, "", data, reflect.TypeOf(target))
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// checkValue checks data against t, appending the problems found at path
+func checkValue(errs *exception.ValidationErrors, path string, data json.RawMessage, t reflect.Type) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		var object map[string]json.RawMessage
+		if json.Unmarshal(data, &object) != nil || object == nil {
+			return
+		}
+		checkObject(errs, path, object, t)
+	case reflect.Slice, reflect.Array:
+		var array []json.RawMessage
+		if json.Unmarshal(data, &array) != nil {
+			return
+		}
+		for i, item := range array {
+			checkValue(errs, fmt.Sprintf("%s[%d]", path, i), item, t.Elem())
+		}
+	case reflect.Map:
+		var object map[string]json.RawMessage
+		if t.Key().Kind() != reflect.String || json.Unmarshal(data, &object) != nil {
+			return
+		}
+		for key, value := range object {
+			checkValue(errs, joinPath(path, key), value, t.Elem())
+		}
+	}
+}
+
+// checkObject checks the keys of object against the fields of struct t
+func checkObject(errs *exception.ValidationErrors, path string, object map[string]json.RawMessage, t reflect.Type) {
+	fields := jsonFields(t)
+	seen := make(map[string]bool, len(object))
+	for key, value := range object {
+		field, ok := matchField(fields, key)
+		if !ok {
+			*errs = append(*errs, exception.FieldError{Field: joinPath(path, key), Code: "unexpected", Message: "unexpected field"})
+			continue
+		}
+		seen[field.name] = true
+		checkValue(errs, joinPath(path, key), value, field.typ)
+	}
+	for _, field := range fields {
+		if !seen[field.name] && !field.optional {
+			*errs = append(*errs, exception.FieldError{Field: joinPath(path, field.name), Code: "required", Message: "missing field"})
+		}
+	}
+}
+
+// jsonField is a struct field as seen by encoding/json
+type jsonField struct {
+	name     string
+	typ      reflect.Type
+	optional bool
+}
+
+// jsonFields returns the fields encoding/json decodes into struct t,
+// flattening untagged embedded structs
+func jsonFields(t reflect.Type) []jsonField {
+	var fields []jsonField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				fields = append(fields, jsonFields(ft)...)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		// fields bound from the query or path are not expected in the body
+		_, query := f.Tag.Lookup("query")
+		_, param := f.Tag.Lookup("path")
+		optional := ft.Kind() == reflect.Pointer || strings.Contains(opts, "omitempty") || strings.Contains(opts, "omitzero") || query || param
+		fields = append(fields, jsonField{name: name, typ: ft, optional: optional})
+	}
+	return fields
+}
+
+// matchField finds the field decoding key, preferring an exact match over
+// the case-insensitive one encoding/json also accepts
+func matchField(fields []jsonField, key string) (jsonField, bool) {
+	for _, f := range fields {
+		if f.name == key {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return f, true
+		}
+	}
+	return jsonField{}, false
+}
+
+// joinPath appends key to the dotted field path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
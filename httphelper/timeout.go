@@ -0,0 +1,130 @@
+package httphelper
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aeramu/apihelper/exception"
+)
+
+// Timeout returns a middleware running handlers with a context deadline of d.
+// Handler output is buffered; when the deadline is exceeded a
+// DEADLINE_EXCEEDED envelope (504) is written instead and later writes by
+// the handler fail with http.ErrHandlerTimeout. Unlike http.TimeoutHandler,
+// the timeout response uses the standard envelope rather than plain text.
+// Handlers should watch r.Context() to stop work early.
+//
+// Example usage:
+//
+//	http.ListenAndServe(":8080", httphelper.Timeout(5*time.Second)(mux))
+func Timeout(d time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+			r = r.WithContext(ctx)
+
+			tw := &timeoutWriter{w: w, header: make(http.Header)}
+			done := make(chan struct{})
+			panicc := make(chan any, 1)
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						panicc <- p
+					}
+				}()
+				next.ServeHTTP(tw, r)
+				close(done)
+			}()
+
+			select {
+			case p := <-panicc:
+				panic(p)
+			case <-done:
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				dst := w.Header()
+				for k, v := range tw.header {
+					dst[k] = v
+				}
+				if tw.status == 0 {
+					tw.status = http.StatusOK
+				}
+				w.WriteHeader(tw.status)
+				w.Write(tw.buf.Bytes())
+			case <-ctx.Done():
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				tw.timedOut = true
+				writeError(w, r, exception.Wrap(ctx.Err(), "handler timed out",
+					exception.WithStatus(exception.StatusDeadlineExceeded),
+					exception.WithCode(exception.CodeDeadlineExceeded),
+					exception.WithMessage("request timed out"),
+				))
+			}
+		})
+	}
+}
+
+// timeoutWriter buffers the handler response for Timeout
+type timeoutWriter struct {
+	w        http.ResponseWriter
+	mu       sync.Mutex
+	header   http.Header
+	buf      bytes.Buffer
+	status   int
+	timedOut bool
+}
+
+// Unwrap returns the underlying ResponseWriter so writers can find the
+// middlewares wrapping it, or nil once the timeout response is written so
+// late handlers cannot reach it, e.g. through http.ResponseController
+func (tw *timeoutWriter) Unwrap() http.ResponseWriter {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return nil
+	}
+	return tw.w
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(status int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.status != 0 {
+		return
+	}
+	tw.status = status
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if tw.status == 0 {
+		tw.status = http.StatusOK
+	}
+	return tw.buf.Write(b)
+}
+
+// Flush is a no-op besides committing the status, the output is buffered
+// until the handler returns
+func (tw *timeoutWriter) Flush() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return
+	}
+	if tw.status == 0 {
+		tw.status = http.StatusOK
+	}
+}
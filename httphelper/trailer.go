@@ -0,0 +1,51 @@
+package httphelper
+
+import (
+	"net/http"
+	"strings"
+)
+
+const (
+	// TrailerStreamStatus is the trailer reporting how a stream ended:
+	// StreamComplete or StreamFailed. A missing trailer means the stream
+	// was truncated.
+	TrailerStreamStatus = "Stream-Status"
+	// TrailerErrorCode is the trailer carrying the error code of a failed stream
+	TrailerErrorCode = "Error-Code"
+	// TrailerErrorMessage is the trailer carrying the error message of a failed stream
+	TrailerErrorMessage = "Error-Message"
+
+	// StreamComplete is the Stream-Status of streams that ended successfully
+	StreamComplete = "complete"
+	// StreamFailed is the Stream-Status of streams that ended with an error
+	StreamFailed = "error"
+)
+
+// announceTrailers declares the stream trailers; it must be called before
+// the response headers are written
+func announceTrailers(w http.ResponseWriter) {
+	w.Header().Add("Trailer", strings.Join([]string{TrailerStreamStatus, TrailerErrorCode, TrailerErrorMessage}, ", "))
+}
+
+// writeTrailers sets the stream trailers for a stream ending with errInfo,
+// nil for a successful stream
+func writeTrailers(w http.ResponseWriter, errInfo *ErrorInfo) {
+	header := w.Header()
+	if errInfo == nil {
+		header.Set(TrailerStreamStatus, StreamComplete)
+		return
+	}
+	header.Set(TrailerStreamStatus, StreamFailed)
+	header.Set(TrailerErrorCode, trailerValue(errInfo.Code))
+	header.Set(TrailerErrorMessage, trailerValue(errInfo.Message))
+}
+
+// trailerValue strips the characters not allowed in header values
+func trailerValue(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\r' || r == '\n' || r < ' ' && r != '\t' {
+			return ' '
+		}
+		return r
+	}, s)
+}
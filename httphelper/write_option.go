@@ -0,0 +1,33 @@
+package httphelper
+
+import "net/http"
+
+// WriteOption customizes the headers of a single response written by the writers
+type WriteOption func(h http.Header)
+
+// WithCacheControl sets the Cache-Control header of the response,
+// overriding the default set with WithDefaultCacheControl
+func WithCacheControl(value string) WriteOption {
+	return func(h http.Header) {
+		h.Set("Cache-Control", value)
+	}
+}
+
+// WithHeader sets a header of the response, replacing existing values.
+// Setting headers through the writers guarantees they are set before the
+// status code is written.
+func WithHeader(key, value string) WriteOption {
+	return func(h http.Header) {
+		h.Set(key, value)
+	}
+}
+
+// applySuccessOptions sets the default and per-call headers of a successful response
+func (h *Helper) applySuccessOptions(w http.ResponseWriter, opts []WriteOption) {
+	if h.cfg.cacheControl != "" {
+		w.Header().Set("Cache-Control", h.cfg.cacheControl)
+	}
+	for _, opt := range opts {
+		opt(w.Header())
+	}
+}
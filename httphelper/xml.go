@@ -0,0 +1,116 @@
+package httphelper
+
+import (
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// xmlResponse is the XML form of Response, encoding Meta with xmlValue
+type xmlResponse struct {
+	Status     int         `xml:"status"`
+	Success    bool        `xml:"success"`
+	Data       any         `xml:"data,omitempty"`
+	ErrorInfo  *ErrorInfo  `xml:"error,omitempty"`
+	Pagination *Page       `xml:"pagination,omitempty"`
+	Warnings   []ErrorInfo `xml:"warnings>warning,omitempty"`
+	Meta       *xmlValue   `xml:"meta,omitempty"`
+}
+
+// MarshalXML encodes the envelope, writing Meta as entry elements
+func (r Response) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(xmlResponse{
+		Status:     r.Status,
+		Success:    r.Success,
+		Data:       r.Data,
+		ErrorInfo:  r.ErrorInfo,
+		Pagination: r.Pagination,
+		Warnings:   r.Warnings,
+		Meta:       newXMLValue(r.Meta),
+	}, start)
+}
+
+// xmlErrorInfo is the XML form of ErrorInfo, encoding Details with xmlValue
+type xmlErrorInfo struct {
+	Code    string    `xml:"code"`
+	Message string    `xml:"message"`
+	Detail  string    `xml:"detail,omitempty"`
+	Details *xmlValue `xml:"details,omitempty"`
+	Help    string    `xml:"help,omitempty"`
+}
+
+// MarshalXML encodes the error info, writing Details as entry elements
+func (e ErrorInfo) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	return enc.EncodeElement(xmlErrorInfo{
+		Code:    e.Code,
+		Message: e.Message,
+		Detail:  e.Detail,
+		Details: newXMLValue(e.Details),
+		Help:    e.Help,
+	}, start)
+}
+
+// xmlValue encodes values encoding/xml cannot represent on its own. Maps
+// become <entry key="..."> elements sorted by key and slices become <item>
+// elements, recursively; other values use the default XML encoding.
+type xmlValue struct {
+	v reflect.Value
+}
+
+// newXMLValue wraps v, returning nil for nil and empty values so they are omitted
+func newXMLValue(v any) *xmlValue {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || ((rv.Kind() == reflect.Map || rv.Kind() == reflect.Slice) && rv.Len() == 0) {
+		return nil
+	}
+	return &xmlValue{v: rv}
+}
+
+func (x xmlValue) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	v := x.v
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch {
+	case v.Kind() == reflect.Map:
+		keys := v.MapKeys()
+		sorted := make([]string, len(keys))
+		values := make(map[string]reflect.Value, len(keys))
+		for i, k := range keys {
+			sorted[i] = fmt.Sprint(k.Interface())
+			values[sorted[i]] = v.MapIndex(k)
+		}
+		sort.Strings(sorted)
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		for _, k := range sorted {
+			entry := xml.StartElement{
+				Name: xml.Name{Local: "entry"},
+				Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: k}},
+			}
+			if err := (xmlValue{v: values[k]}).MarshalXML(e, entry); err != nil {
+				return err
+			}
+		}
+		return e.EncodeToken(start.End())
+	case (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() != reflect.Uint8:
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		for i := 0; i < v.Len(); i++ {
+			item := xml.StartElement{Name: xml.Name{Local: "item"}}
+			if err := (xmlValue{v: v.Index(i)}).MarshalXML(e, item); err != nil {
+				return err
+			}
+		}
+		return e.EncodeToken(start.End())
+	default:
+		return e.EncodeElement(v.Interface(), start)
+	}
+}
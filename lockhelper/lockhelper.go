@@ -0,0 +1,81 @@
+package lockhelper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aeramu/apihelper/exception"
+)
+
+// Package lockhelper serializes mutations on a shared resource with
+// distributed locks, reporting contention with exception semantics.
+//
+// Example usage:
+//
+//	err := lockhelper.WithLock(ctx, locker, "order:"+id, 10*time.Second,
+//	    func(ctx context.Context) error {
+//	        return service.UpdateOrder(ctx, id, req)
+//	    },
+//	)
+
+// Locker acquires distributed locks
+type Locker interface {
+	// TryLock attempts to acquire the lock on key without waiting.
+	// It returns false when the lock is held by someone else.
+	TryLock(ctx context.Context, key string, ttl time.Duration) (Lock, bool, error)
+}
+
+// Lock is an acquired lock
+type Lock interface {
+	// Unlock releases the lock. It returns false when the lock was no longer
+	// held, e.g. because it expired and was acquired by someone else.
+	Unlock(ctx context.Context) (bool, error)
+}
+
+// WithLock runs fn while holding the lock on key.
+//
+// It returns a RACE_CONDITION exception matching exception.ErrorRaceCondition
+// when the lock is held by someone else, and an UNAVAILABLE exception when the
+// locker fails. If the lock expires before fn returns, the result of fn is
+// wrapped in a RACE_CONDITION exception since the critical section may have
+// overlapped with another holder, along with the error of Unlock if any. The
+// context passed to fn is cancelled when the lock expires. The lock is
+// released even when fn panics.
+func WithLock(ctx context.Context, locker Locker, key string, ttl time.Duration, fn func(ctx context.Context) error) (err error) {
+	lock, ok, err := locker.TryLock(ctx, key, ttl)
+	if err != nil {
+		return exception.Wrap(err, "failed to acquire lock "+key,
+			exception.WithStatus(exception.StatusUnavailable),
+			exception.WithCode(exception.CodeUnavailable),
+			exception.WithMessage("service unavailable"),
+		)
+	}
+	if !ok {
+		return exception.Wrap(exception.ErrorRaceCondition, "lock "+key+" is held by another process",
+			exception.WithStatus(exception.StatusRaceCondition),
+			exception.WithCode(exception.CodeRaceCondition),
+			exception.WithMessage("resource is being modified by another request"),
+		)
+	}
+
+	start := time.Now()
+	lockCtx, cancel := context.WithTimeout(ctx, ttl)
+	defer func() {
+		cancel()
+		held, unlockErr := lock.Unlock(context.WithoutCancel(ctx))
+		if unlockErr != nil {
+			held = false
+			unlockErr = fmt.Errorf("failed to release lock %s: %w", key, unlockErr)
+		}
+		if !held || time.Since(start) > ttl {
+			err = exception.Wrap(errors.Join(err, unlockErr), "lock "+key+" expired before the critical section completed",
+				exception.WithStatus(exception.StatusRaceCondition),
+				exception.WithCode(exception.CodeRaceCondition),
+				exception.WithMessage("resource lock expired during the operation"),
+			)
+		}
+	}()
+	return fn(lockCtx)
+}
@@ -0,0 +1,89 @@
+package lockhelper_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aeramu/apihelper/exception"
+	"github.com/aeramu/apihelper/lockhelper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithLock(t *testing.T) {
+	locker := lockhelper.NewMemoryLocker()
+	var called bool
+
+	err := lockhelper.WithLock(context.Background(), locker, "order:1", time.Second, func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
+
+func TestWithLock_Contention(t *testing.T) {
+	locker := lockhelper.NewMemoryLocker()
+
+	err := lockhelper.WithLock(context.Background(), locker, "order:1", time.Second, func(ctx context.Context) error {
+		return lockhelper.WithLock(ctx, locker, "order:1", time.Second, func(ctx context.Context) error {
+			return nil
+		})
+	})
+
+	assert.True(t, errors.Is(err, exception.ErrorRaceCondition))
+	assert.True(t, exception.HasStatus(err, exception.StatusRaceCondition))
+}
+
+func TestWithLock_Expired(t *testing.T) {
+	locker := lockhelper.NewMemoryLocker()
+	errFn := errors.New("fn error")
+
+	err := lockhelper.WithLock(context.Background(), locker, "order:1", time.Millisecond, func(ctx context.Context) error {
+		<-ctx.Done()
+		return errFn
+	})
+
+	assert.True(t, errors.Is(err, errFn))
+	assert.True(t, exception.HasStatus(err, exception.StatusRaceCondition))
+}
+
+func TestWithLock_Panic(t *testing.T) {
+	locker := lockhelper.NewMemoryLocker()
+
+	assert.Panics(t, func() {
+		lockhelper.WithLock(context.Background(), locker, "order:1", time.Second, func(ctx context.Context) error {
+			panic("boom")
+		})
+	})
+
+	err := lockhelper.WithLock(context.Background(), locker, "order:1", time.Second, func(ctx context.Context) error {
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+type failingLocker struct{ err error }
+
+func (l failingLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (lockhelper.Lock, bool, error) {
+	return l, true, nil
+}
+
+func (l failingLocker) Unlock(ctx context.Context) (bool, error) {
+	return false, l.err
+}
+
+func TestWithLock_UnlockError(t *testing.T) {
+	errUnlock := errors.New("connection reset")
+	errFn := errors.New("fn error")
+
+	err := lockhelper.WithLock(context.Background(), failingLocker{err: errUnlock}, "order:1", time.Second, func(ctx context.Context) error {
+		return errFn
+	})
+
+	assert.ErrorIs(t, err, errFn)
+	assert.ErrorIs(t, err, errUnlock)
+	assert.True(t, exception.HasStatus(err, exception.StatusRaceCondition))
+}
@@ -0,0 +1,53 @@
+package lockhelper
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryLocker is an in-process Locker, mainly useful for tests and single-instance services
+type MemoryLocker struct {
+	mu    sync.Mutex
+	locks map[string]*memoryLock
+}
+
+// NewMemoryLocker creates an empty MemoryLocker
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{
+		locks: make(map[string]*memoryLock),
+	}
+}
+
+// TryLock attempts to acquire the lock on key
+func (l *MemoryLocker) TryLock(_ context.Context, key string, ttl time.Duration) (Lock, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if current, ok := l.locks[key]; ok && time.Now().Before(current.expiresAt) {
+		return nil, false, nil
+	}
+	lock := &memoryLock{
+		locker:    l,
+		key:       key,
+		expiresAt: time.Now().Add(ttl),
+	}
+	l.locks[key] = lock
+	return lock, true, nil
+}
+
+type memoryLock struct {
+	locker    *MemoryLocker
+	key       string
+	expiresAt time.Time
+}
+
+func (l *memoryLock) Unlock(_ context.Context) (bool, error) {
+	l.locker.mu.Lock()
+	defer l.locker.mu.Unlock()
+	current, ok := l.locker.locks[l.key]
+	if !ok || current != l {
+		return false, nil
+	}
+	delete(l.locker.locks, l.key)
+	return time.Now().Before(l.expiresAt), nil
+}
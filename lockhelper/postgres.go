@@ -0,0 +1,73 @@
+package lockhelper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"time"
+)
+
+// PostgresLocker acquires session-level advisory locks.
+// Advisory locks do not expire on their own; the ttl is only used to detect
+// critical sections that overran their budget.
+type PostgresLocker struct {
+	db *sql.DB
+}
+
+// NewPostgresLocker creates a PostgresLocker using the given database
+func NewPostgresLocker(db *sql.DB) *PostgresLocker {
+	return &PostgresLocker{
+		db: db,
+	}
+}
+
+// TryLock attempts to acquire the advisory lock on key.
+// The lock holds a dedicated connection until it is unlocked.
+func (l *PostgresLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (Lock, bool, error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var ok bool
+	err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", key).Scan(&ok)
+	if err != nil {
+		// the lock may have been acquired before the query failed
+		discard(conn)
+		return nil, false, err
+	}
+	if !ok {
+		conn.Close()
+		return nil, false, nil
+	}
+	return &postgresLock{
+		conn: conn,
+		key:  key,
+	}, true, nil
+}
+
+type postgresLock struct {
+	conn *sql.Conn
+	key  string
+}
+
+func (l *postgresLock) Unlock(ctx context.Context) (bool, error) {
+	var ok bool
+	err := l.conn.QueryRowContext(ctx, "SELECT pg_advisory_unlock(hashtext($1))", l.key).Scan(&ok)
+	if err != nil {
+		// the session may still hold the lock, so it must not go back to the pool
+		discard(l.conn)
+		return false, err
+	}
+	l.conn.Close()
+	return ok, nil
+}
+
+// discard closes the underlying connection instead of returning it to the
+// pool, which releases every advisory lock held by its session
+func discard(conn *sql.Conn) {
+	conn.Raw(func(any) error {
+		return driver.ErrBadConn
+	})
+	conn.Close()
+}
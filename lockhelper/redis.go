@@ -0,0 +1,80 @@
+package lockhelper
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// RedisClient is the subset of a Redis client used by RedisLocker.
+// Adapting a go-redis client takes a few lines, e.g.
+// client.SetNX(ctx, key, value, ttl).Result().
+type RedisClient interface {
+	// SetNX sets key to value with an expiration only if key does not exist
+	SetNX(ctx context.Context, key string, value string, ttl time.Duration) (bool, error)
+	// Eval runs a Lua script against the given keys and arguments
+	Eval(ctx context.Context, script string, keys []string, args ...any) (any, error)
+}
+
+// unlockScript deletes the key only if it still holds our token
+const unlockScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`
+
+// RedisLocker acquires locks with SET NX and a random token, so a lock can
+// only be released by its holder
+type RedisLocker struct {
+	client RedisClient
+	prefix string
+}
+
+// NewRedisLocker creates a RedisLocker storing locks under "lock:<key>"
+func NewRedisLocker(client RedisClient) *RedisLocker {
+	return &RedisLocker{
+		client: client,
+		prefix: "lock:",
+	}
+}
+
+// TryLock attempts to acquire the lock on key
+func (l *RedisLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (Lock, bool, error) {
+	token, err := randomToken()
+	if err != nil {
+		return nil, false, err
+	}
+	ok, err := l.client.SetNX(ctx, l.prefix+key, token, ttl)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return &redisLock{
+		client: l.client,
+		key:    l.prefix + key,
+		token:  token,
+	}, true, nil
+}
+
+type redisLock struct {
+	client RedisClient
+	key    string
+	token  string
+}
+
+func (l *redisLock) Unlock(ctx context.Context) (bool, error) {
+	res, err := l.client.Eval(ctx, unlockScript, []string{l.key}, l.token)
+	if err != nil {
+		return false, err
+	}
+	n, ok := res.(int64)
+	if !ok {
+		return false, fmt.Errorf("unexpected unlock result %v", res)
+	}
+	return n == 1, nil
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
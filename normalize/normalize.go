@@ -0,0 +1,207 @@
+// Package normalize maps upstream error responses to exceptions using
+// configurable rules, so third-party error normalization can be adjusted
+// by editing a YAML or JSON file instead of deploying code.
+//
+// Example rules file:
+//
+//	rules:
+//	  - host: ^api\.payment\.com$
+//	    path: ^/v1/charges
+//	    http_status: [402]
+//	    body: card_declined
+//	    status: INVALID_REQUEST
+//	    code: PAYMENT_DECLINED
+//	    message: payment was declined
+//
+// Example usage:
+//
+//	engine, err := normalize.LoadFile("rules.yaml")
+//	if err != nil {
+//	    return err
+//	}
+//	clienthelper.Configure(clienthelper.WithNormalizer(engine))
+package normalize
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	"github.com/aeramu/apihelper/exception"
+	"gopkg.in/yaml.v3"
+)
+
+// Rule maps matching upstream responses to an exception.
+// Empty matchers match everything; all non-empty matchers must match.
+type Rule struct {
+	// Host is a regular expression matched against the upstream host
+	Host string `json:"host" yaml:"host"`
+	// Path is a regular expression matched against the request path
+	Path string `json:"path" yaml:"path"`
+	// HTTPStatus lists the upstream status codes the rule applies to
+	HTTPStatus []int `json:"http_status" yaml:"http_status"`
+	// Body is a regular expression matched against the upstream response body
+	Body string `json:"body" yaml:"body"`
+
+	// Status is the exception status, defaults to THIRD_PARTY
+	Status exception.Status `json:"status" yaml:"status"`
+	// Code is the exception code, defaults to the status
+	Code exception.Code `json:"code" yaml:"code"`
+	// Message is the exception message
+	Message string `json:"message" yaml:"message"`
+}
+
+// Config is the document format accepted by the loaders
+type Config struct {
+	Rules []Rule `json:"rules" yaml:"rules"`
+}
+
+type compiledRule struct {
+	Rule
+	host *regexp.Regexp
+	path *regexp.Regexp
+	body *regexp.Regexp
+}
+
+// Engine evaluates rules in order and returns the exception of the first match.
+// It is safe for concurrent use, and rules can be replaced at runtime.
+type Engine struct {
+	mu    sync.RWMutex
+	rules []compiledRule
+}
+
+// New creates an Engine with the given rules
+func New(rules ...Rule) (*Engine, error) {
+	e := &Engine{}
+	if err := e.Replace(rules); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// LoadJSON creates an Engine from a JSON rules document
+func LoadJSON(r io.Reader) (*Engine, error) {
+	var cfg Config
+	if err := json.NewDecoder(r).Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode rules: %w", err)
+	}
+	return New(cfg.Rules...)
+}
+
+// LoadYAML creates an Engine from a YAML rules document
+func LoadYAML(r io.Reader) (*Engine, error) {
+	var cfg Config
+	if err := yaml.NewDecoder(r).Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode rules: %w", err)
+	}
+	return New(cfg.Rules...)
+}
+
+// LoadFile creates an Engine from a rules file, using JSON for ".json" files and YAML otherwise
+func LoadFile(path string) (*Engine, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if filepath.Ext(path) == ".json" {
+		return LoadJSON(f)
+	}
+	return LoadYAML(f)
+}
+
+// Replace atomically swaps the rules of the engine.
+// The current rules are kept if any of the new rules is invalid.
+func (e *Engine) Replace(rules []Rule) error {
+	compiled := make([]compiledRule, 0, len(rules))
+	for i, rule := range rules {
+		c, err := compile(rule)
+		if err != nil {
+			return fmt.Errorf("rule %d: %w", i, err)
+		}
+		compiled = append(compiled, c)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.rules = compiled
+	return nil
+}
+
+// Normalize returns the exception of the first rule matching the upstream response,
+// or nil when no rule matches.
+func (e *Engine) Normalize(host, path string, status int, body []byte) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for _, rule := range e.rules {
+		if !rule.match(host, path, status, body) {
+			continue
+		}
+		return exception.New(fmt.Sprintf("upstream %s%s returned status %d", host, path, status),
+			exception.WithStatus(rule.Status),
+			exception.WithCode(rule.Code),
+			exception.WithMessage(rule.Message),
+		)
+	}
+	return nil
+}
+
+func (r compiledRule) match(host, path string, status int, body []byte) bool {
+	if r.host != nil && !r.host.MatchString(host) {
+		return false
+	}
+	if r.path != nil && !r.path.MatchString(path) {
+		return false
+	}
+	if len(r.HTTPStatus) > 0 && !containsStatus(r.HTTPStatus, status) {
+		return false
+	}
+	if r.body != nil && !r.body.Match(body) {
+		return false
+	}
+	return true
+}
+
+func compile(rule Rule) (compiledRule, error) {
+	if rule.Status == "" {
+		rule.Status = exception.StatusThirdParty
+	}
+	if rule.Code == "" {
+		rule.Code = exception.Code(rule.Status)
+	}
+
+	c := compiledRule{Rule: rule}
+	var err error
+	if c.host, err = compileOptional(rule.Host); err != nil {
+		return c, fmt.Errorf("invalid host pattern: %w", err)
+	}
+	if c.path, err = compileOptional(rule.Path); err != nil {
+		return c, fmt.Errorf("invalid path pattern: %w", err)
+	}
+	if c.body, err = compileOptional(rule.Body); err != nil {
+		return c, fmt.Errorf("invalid body pattern: %w", err)
+	}
+	return c, nil
+}
+
+func compileOptional(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile(pattern)
+}
+
+func containsStatus(statuses []int, status int) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
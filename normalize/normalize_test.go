@@ -0,0 +1,51 @@
+package normalize_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aeramu/apihelper/exception"
+	"github.com/aeramu/apihelper/httphelper"
+	"github.com/aeramu/apihelper/normalize"
+	"github.com/stretchr/testify/assert"
+)
+
+const rules = `
+rules:
+  - host: ^api\.payment\.com$
+    path: ^/v1/charges
+    http_status: [402]
+    body: card_declined
+    status: INVALID_REQUEST
+    code: PAYMENT_DECLINED
+    message: payment was declined
+  - http_status: [503]
+    status: UNAVAILABLE
+    message: upstream unavailable
+`
+
+func TestNormalize(t *testing.T) {
+	engine, err := normalize.LoadYAML(strings.NewReader(rules))
+	assert.NoError(t, err)
+
+	err = engine.Normalize("api.payment.com", "/v1/charges", 402, []byte(`{"error":"card_declined"}`))
+	httpErr, ok := httphelper.AsHTTPError(err)
+	assert.True(t, ok)
+	assert.Equal(t, "PAYMENT_DECLINED", httpErr.Code())
+	assert.Equal(t, "payment was declined", httpErr.Message())
+	assert.Equal(t, 400, httpErr.HTTPStatus())
+
+	err = engine.Normalize("api.other.com", "/", 503, nil)
+	httpErr, ok = httphelper.AsHTTPError(err)
+	assert.True(t, ok)
+	assert.Equal(t, exception.CodeUnavailable.String(), httpErr.Code())
+
+	err = engine.Normalize("api.payment.com", "/v1/charges", 402, []byte(`{"error":"expired"}`))
+	assert.NoError(t, err)
+}
+
+func TestLoadJSON_InvalidPattern(t *testing.T) {
+	_, err := normalize.LoadJSON(strings.NewReader(`{"rules":[{"body":"("}]}`))
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,267 @@
+// Package openapi generates OpenAPI 3 documents from the typed routes of an
+// httphelper.Router. Request and response schemas are derived from the
+// route types by reflection, responses are wrapped in the standard envelope
+// unless the route was registered WithoutEnvelope,
+// and error responses are listed from the errors declared with
+// httphelper.WithErrors.
+//
+// Example usage:
+//
+//	router := httphelper.NewRouter()
+//	router.GET("/orders/{order_id}", httphelper.Typed(getOrder),
+//	    httphelper.WithErrors(ErrOrderNotFound),
+//	)
+//
+//	doc := openapi.Generate(openapi.Info{Title: "Orders", Version: "1.0.0"}, router.Routes())
+//	mux.Handle("GET /openapi.json", openapi.Handler(doc))
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aeramu/apihelper/exception"
+	"github.com/aeramu/apihelper/httphelper"
+)
+
+// Version is the OpenAPI version of the generated documents
+const Version = "3.0.3"
+
+// Document is an OpenAPI document
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem holds the operations of a path keyed by lowercase HTTP method
+type PathItem map[string]Operation
+
+// Operation describes a single API operation on a path
+type Operation struct {
+	OperationID string              `json:"operationId,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+// Parameter describes a path or query parameter
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody describes the request body of an operation
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response describes a response of an operation
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a content type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the reusable schemas referenced by the document
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Schema is a JSON schema as used by OpenAPI 3.0
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Generate builds the OpenAPI document of the routes.
+// Each operation lists the codes of the errors its route declares, grouped
+// by HTTP status, and a default response describing any other error.
+func Generate(info Info, routes []httphelper.Route) Document {
+	g := newGenerator()
+	doc := Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]PathItem{},
+	}
+
+	for _, route := range routes {
+		path := openAPIPath(route.Path)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = PathItem{}
+			doc.Paths[path] = item
+		}
+
+		success := g.schema(route.Response)
+		if !route.NoEnvelope {
+			success = envelope(success, nil)
+		}
+		op := Operation{
+			OperationID: operationID(route.Method, path),
+			Responses: map[string]Response{
+				"200": {
+					Description: "Successful response",
+					Content:     jsonContent(success),
+				},
+				"default": {
+					Description: "Error response",
+					Content:     jsonContent(envelope(nil, errorInfo(nil))),
+				},
+			},
+		}
+		op.Parameters = g.parameters(route.Request)
+		if hasBody(route.Method) {
+			if body := g.bodySchema(route.Request); body != nil {
+				op.RequestBody = &RequestBody{Required: true, Content: jsonContent(body)}
+			}
+		}
+		for status, resp := range errorResponses(routeErrors(route.Errors)) {
+			op.Responses[status] = resp
+		}
+		item[strings.ToLower(route.Method)] = op
+	}
+
+	doc.Components.Schemas = g.components
+	return doc
+}
+
+// routeErrors returns the catalog entries of the errors declared by a route
+func routeErrors(errs []error) []exception.Entry {
+	var entries []exception.Entry
+	seen := map[string]bool{}
+	for _, err := range errs {
+		var httpErr httphelper.HTTPError
+		if !errors.As(err, &httpErr) || seen[httpErr.Code()] {
+			continue
+		}
+		seen[httpErr.Code()] = true
+		entries = append(entries, exception.Entry{
+			Code:       httpErr.Code(),
+			HTTPStatus: httpErr.HTTPStatus(),
+		})
+	}
+	return entries
+}
+
+// errorResponses groups the catalog entries by HTTP status
+func errorResponses(entries []exception.Entry) map[string]Response {
+	codes := map[int][]string{}
+	for _, entry := range entries {
+		if entry.HTTPStatus < 400 {
+			continue
+		}
+		codes[entry.HTTPStatus] = append(codes[entry.HTTPStatus], entry.Code)
+	}
+
+	responses := make(map[string]Response, len(codes))
+	for status, statusCodes := range codes {
+		sort.Strings(statusCodes)
+		responses[strconv.Itoa(status)] = Response{
+			Description: http.StatusText(status),
+			Content:     jsonContent(envelope(nil, errorInfo(statusCodes))),
+		}
+	}
+	return responses
+}
+
+// errorInfo returns the schema of the error info restricted to codes
+func errorInfo(codes []string) *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"code":    {Type: "string", Enum: codes},
+			"message": {Type: "string"},
+			"detail":  {Type: "string"},
+			"details": {Type: "object"},
+			"help":    {Type: "string"},
+		},
+		Required: []string{"code", "message"},
+	}
+}
+
+// envelope wraps the data or error schema in the standard response envelope
+func envelope(data, errInfo *Schema) *Schema {
+	s := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"status":  {Type: "integer"},
+			"success": {Type: "boolean"},
+		},
+		Required: []string{"status", "success"},
+	}
+	if data != nil {
+		s.Properties["data"] = data
+	}
+	if errInfo != nil {
+		s.Properties["error"] = errInfo
+	}
+	return s
+}
+
+func jsonContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{httphelper.ContentTypeJSON: {Schema: schema}}
+}
+
+// openAPIPath converts an http.ServeMux pattern path to an OpenAPI path
+func openAPIPath(path string) string {
+	path = strings.TrimSuffix(path, "{$}")
+	return strings.ReplaceAll(path, "...}", "}")
+}
+
+// operationID derives an operation ID such as get_orders_order_id
+func operationID(method, path string) string {
+	id := strings.ToLower(method) + strings.NewReplacer("/", "_", "{", "", "}", "", "-", "_").Replace(path)
+	return strings.TrimSuffix(id, "_")
+}
+
+func hasBody(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch
+}
+
+// Handler serves the document as JSON, typically at /openapi.json
+func Handler(doc Document) http.Handler {
+	b, err := json.Marshal(doc)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err != nil {
+			httphelper.Error(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", httphelper.ContentTypeJSON)
+		w.Write(b)
+	})
+}
+
+// Write writes the document as indented JSON, e.g. to export it at build time
+func Write(w io.Writer, doc Document) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
@@ -0,0 +1,154 @@
+package openapi_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aeramu/apihelper/exception"
+	"github.com/aeramu/apihelper/httphelper"
+	"github.com/aeramu/apihelper/openapi"
+	"github.com/stretchr/testify/assert"
+)
+
+type Order struct {
+	ID        int64     `json:"id"`
+	Items     []Item    `json:"items"`
+	Note      *string   `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Item struct {
+	SKU string `json:"sku"`
+}
+
+type Audit struct {
+	CreatedBy string `json:"created_by"`
+	Note      string `json:"note"`
+}
+
+type Page[T any] struct {
+	Items []T `json:"items"`
+}
+
+type AuditedOrder struct {
+	Audit
+	ID   int64  `json:"id"`
+	Note string `json:"note,omitempty"`
+}
+
+var errOrderNotFound = exception.New("order not found",
+	exception.WithStatus(exception.StatusNotFound),
+	exception.WithCode("ORDER_NOT_FOUND"),
+)
+
+type updateOrderRequest struct {
+	OrderID int64  `path:"order_id"`
+	DryRun  bool   `query:"dry_run"`
+	Note    string `json:"note,omitempty"`
+}
+
+func newRouter() *httphelper.Router {
+	router := httphelper.NewRouter()
+	router.PUT("/orders/{order_id}", httphelper.Typed(func(ctx context.Context, req updateOrderRequest) (Order, error) {
+		return Order{}, nil
+	}), httphelper.WithErrors(errOrderNotFound))
+	return router
+}
+
+func TestGenerate(t *testing.T) {
+	doc := openapi.Generate(openapi.Info{Title: "Orders", Version: "1.0.0"}, newRouter().Routes())
+
+	assert.Equal(t, openapi.Version, doc.OpenAPI)
+	op, ok := doc.Paths["/orders/{order_id}"]["put"]
+	assert.True(t, ok)
+	assert.Equal(t, "put_orders_order_id", op.OperationID)
+	assert.Equal(t, []openapi.Parameter{
+		{Name: "order_id", In: "path", Required: true, Schema: &openapi.Schema{Type: "integer", Format: "int64"}},
+		{Name: "dry_run", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+	}, op.Parameters)
+
+	body := op.RequestBody.Content[httphelper.ContentTypeJSON].Schema
+	assert.Equal(t, []string{"note"}, keys(body.Properties))
+	assert.Empty(t, body.Required)
+
+	data := op.Responses["200"].Content[httphelper.ContentTypeJSON].Schema.Properties["data"]
+	assert.Equal(t, "#/components/schemas/openapi_test.Order", data.Ref)
+	order := doc.Components.Schemas["openapi_test.Order"]
+	assert.Equal(t, "#/components/schemas/openapi_test.Item", order.Properties["items"].Items.Ref)
+	assert.True(t, order.Properties["note"].Nullable)
+	assert.Equal(t, "date-time", order.Properties["created_at"].Format)
+	assert.ElementsMatch(t, []string{"id", "items", "created_at"}, order.Required)
+
+	notFound := op.Responses["404"].Content[httphelper.ContentTypeJSON].Schema.Properties["error"]
+	assert.Equal(t, []string{"ORDER_NOT_FOUND"}, notFound.Properties["code"].Enum)
+	assert.ElementsMatch(t, []string{"200", "404", "default"}, responseKeys(op.Responses))
+}
+
+func TestGenerate_Schemas(t *testing.T) {
+	router := httphelper.NewRouter()
+	router.GET("/orders", httphelper.Typed(func(ctx context.Context, req struct{}) (Page[AuditedOrder], error) {
+		return Page[AuditedOrder]{}, nil
+	}))
+
+	doc := openapi.Generate(openapi.Info{Title: "Orders", Version: "1.0.0"}, router.Routes())
+	op := doc.Paths["/orders"]["get"]
+	assert.ElementsMatch(t, []string{"200", "default"}, responseKeys(op.Responses))
+
+	data := op.Responses["200"].Content[httphelper.ContentTypeJSON].Schema.Properties["data"]
+	assert.Equal(t, "#/components/schemas/openapi_test.Page_openapi_test.AuditedOrder", data.Ref)
+	for name := range doc.Components.Schemas {
+		assert.Regexp(t, `^[A-Za-z0-9._-]+$`, name)
+	}
+
+	order := doc.Components.Schemas["openapi_test.AuditedOrder"]
+	assert.ElementsMatch(t, []string{"id", "note", "created_by"}, keys(order.Properties))
+	assert.ElementsMatch(t, []string{"id", "created_by"}, order.Required)
+}
+
+func TestGenerate_WithoutEnvelope(t *testing.T) {
+	router := httphelper.NewRouter()
+	router.GET("/orders/{order_id}", httphelper.Typed(func(ctx context.Context, req updateOrderRequest) (Order, error) {
+		return Order{}, nil
+	}), httphelper.WithoutEnvelope())
+
+	doc := openapi.Generate(openapi.Info{Title: "Orders", Version: "1.0.0"}, router.Routes())
+	schema := doc.Paths["/orders/{order_id}"]["get"].Responses["200"].Content[httphelper.ContentTypeJSON].Schema
+	assert.Equal(t, "#/components/schemas/openapi_test.Order", schema.Ref)
+}
+
+func TestHandler(t *testing.T) {
+	doc := openapi.Generate(openapi.Info{Title: "Orders", Version: "1.0.0"}, newRouter().Routes())
+
+	rec := httptest.NewRecorder()
+	openapi.Handler(doc).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	assert.Equal(t, httphelper.ContentTypeJSON, rec.Header().Get("Content-Type"))
+
+	var served openapi.Document
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &served))
+	assert.Equal(t, "Orders", served.Info.Title)
+
+	var buf bytes.Buffer
+	assert.NoError(t, openapi.Write(&buf, doc))
+	assert.JSONEq(t, rec.Body.String(), buf.String())
+}
+
+func responseKeys(m map[string]openapi.Response) []string {
+	var ks []string
+	for k := range m {
+		ks = append(ks, k)
+	}
+	return ks
+}
+
+func keys(m map[string]*openapi.Schema) []string {
+	var ks []string
+	for k := range m {
+		ks = append(ks, k)
+	}
+	return ks
+}
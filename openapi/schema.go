@@ -0,0 +1,225 @@
+package openapi
+
+import (
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// parameterTags maps the httphelper binding tags to OpenAPI parameter locations
+var parameterTags = []struct{ tag, in string }{
+	{tag: "path", in: "path"},
+	{tag: "query", in: "query"},
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+var (
+	// importPath matches the import paths qualifying the type arguments of generic type names
+	importPath = regexp.MustCompile(`[^\[\],]*/`)
+	// invalidName matches the characters not allowed in component names
+	invalidName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// generator builds schemas, collecting named struct types as components
+type generator struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+	types      map[string]reflect.Type
+}
+
+func newGenerator() *generator {
+	return &generator{
+		components: map[string]*Schema{},
+		names:      map[reflect.Type]string{},
+		types:      map[string]reflect.Type{},
+	}
+}
+
+// componentName returns the component name of a named type, qualified by its
+// package name, e.g. "orders.Order" or "pagination.Page_orders.Order".
+// Types from different packages with the same package name fall back to
+// their full import path.
+func (g *generator) componentName(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := sanitizeName(path.Base(t.PkgPath()) + "." + importPath.ReplaceAllString(t.Name(), ""))
+	if other, ok := g.types[name]; ok && other != t {
+		name = sanitizeName(t.PkgPath() + "." + t.Name())
+	}
+	g.names[t] = name
+	g.types[name] = t
+	return name
+}
+
+// sanitizeName replaces the characters not allowed in component names by underscores
+func sanitizeName(name string) string {
+	return strings.Trim(invalidName.ReplaceAllString(name, "_"), "_")
+}
+
+// schema returns the schema of t, referencing named structs as components
+func (g *generator) schema(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := g.schema(t.Elem())
+		if s.Ref != "" {
+			return s
+		}
+		s.Nullable = true
+		return s
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t, false)
+		}
+		name := g.componentName(t)
+		if _, ok := g.components[name]; !ok {
+			// Register before building the properties so recursive types terminate
+			g.components[name] = &Schema{}
+			*g.components[name] = *g.structSchema(t, false)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	default:
+		return &Schema{}
+	}
+}
+
+// structSchema returns the object schema of the JSON fields of t.
+// With bodyOnly, fields bound from the path, query or form are skipped.
+// Fields of untagged embedded structs are promoted like encoding/json does,
+// fields declared on t taking precedence over promoted ones.
+func (g *generator) structSchema(t reflect.Type, bodyOnly bool) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	var embedded []*Schema
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || (bodyOnly && isParameter(field)) {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				promoted := g.structSchema(ft, bodyOnly)
+				if field.Type.Kind() == reflect.Pointer {
+					promoted.Required = nil
+				}
+				embedded = append(embedded, promoted)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		s.Properties[name] = g.schema(field.Type)
+		if field.Type.Kind() != reflect.Pointer && !strings.Contains(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+
+	declared := make(map[string]bool, len(s.Properties))
+	for name := range s.Properties {
+		declared[name] = true
+	}
+	for _, promoted := range embedded {
+		for _, name := range promoted.Required {
+			if !declared[name] {
+				s.Required = append(s.Required, name)
+			}
+		}
+		for name, prop := range promoted.Properties {
+			if !declared[name] {
+				s.Properties[name] = prop
+				declared[name] = true
+			}
+		}
+	}
+	return s
+}
+
+// bodySchema returns the schema of the JSON body fields of a request type,
+// nil when it has none
+func (g *generator) bodySchema(t reflect.Type) *Schema {
+	if t == nil {
+		return nil
+	}
+	if t.Kind() != reflect.Struct {
+		return g.schema(t)
+	}
+	s := g.structSchema(t, true)
+	if len(s.Properties) == 0 {
+		return nil
+	}
+	return s
+}
+
+// parameters returns the path and query parameters of a request type
+func (g *generator) parameters(t reflect.Type) []Parameter {
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var params []Parameter
+	for _, pt := range parameterTags {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, opts, _ := strings.Cut(field.Tag.Get(pt.tag), ",")
+			if name == "" || name == "-" || !field.IsExported() {
+				continue
+			}
+			params = append(params, Parameter{
+				Name:     name,
+				In:       pt.in,
+				Required: pt.in == "path" || opts == "required",
+				Schema:   g.schema(field.Type),
+			})
+		}
+	}
+	return params
+}
+
+// isParameter reports whether the field is bound from the path, query or form
+func isParameter(field reflect.StructField) bool {
+	for _, tag := range []string{"path", "query", "form"} {
+		if name, _, _ := strings.Cut(field.Tag.Get(tag), ","); name != "" && name != "-" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package pagination
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Configuration options
+type config struct {
+	defaultLimit Limit
+	maxLimit     Limit
+}
+
+// Option represents a configuration option for the pagination package
+type Option func(*config)
+
+// defaultConfig holds the configuration changed by Configure. It is replaced
+// as a whole and never mutated, so requests can be parsed concurrently with
+// Configure.
+var defaultConfig atomic.Pointer[config]
+
+// configureMu serializes Configure calls so concurrent updates are not lost
+var configureMu sync.Mutex
+
+func init() {
+	defaultConfig.Store(&config{
+		defaultLimit: 20,
+		maxLimit:     100,
+	})
+}
+
+// WithDefaultLimit sets the limit used when a request doesn't specify one
+func WithDefaultLimit(limit Limit) Option {
+	return func(c *config) {
+		c.defaultLimit = limit
+	}
+}
+
+// WithMaxLimit sets the largest limit a request may ask for
+func WithMaxLimit(limit Limit) Option {
+	return func(c *config) {
+		c.maxLimit = limit
+	}
+}
+
+// Configure applies the provided options to the default configuration.
+//
+// Parameters:
+//   - opts: A variadic list of Option functions to apply
+func Configure(opts ...Option) {
+	configureMu.Lock()
+	defer configureMu.Unlock()
+
+	cfg := *defaultConfig.Load()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	defaultConfig.Store(&cfg)
+}
@@ -0,0 +1,181 @@
+// Package pagination parses paging parameters from list requests and writes
+// paged responses, so list endpoints across services page identically.
+// Offset pagination uses the limit and offset query parameters, cursor
+// pagination the limit and cursor query parameters.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/aeramu/apihelper/exception"
+	"github.com/aeramu/apihelper/httphelper"
+)
+
+const (
+	// QueryLimit is the query parameter holding the page size
+	QueryLimit = "limit"
+	// QueryOffset is the query parameter holding the number of items to skip
+	QueryOffset = "offset"
+	// QueryCursor is the query parameter holding the cursor of the page
+	QueryCursor = "cursor"
+)
+
+// Limit is the maximum number of items in a page
+type Limit int
+
+// Int returns the limit as an int
+func (l Limit) Int() int {
+	return int(l)
+}
+
+// Cursor is an opaque token identifying the position of a page, typically
+// the sort key of the last item of the previous page
+type Cursor string
+
+// EncodeCursor returns a Cursor encoding v as base64url JSON
+//
+// Example usage:
+//
+//	next, err := pagination.EncodeCursor(lastOrder.ID)
+//
+// Parameters:
+//   - v: The position to encode
+//
+// Returns:
+//   - The encoded cursor
+//   - An error if v cannot be JSON encoded
+func EncodeCursor(v any) (Cursor, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return Cursor(base64.RawURLEncoding.EncodeToString(b)), nil
+}
+
+// Decode decodes the position encoded with EncodeCursor into target
+func (c Cursor) Decode(target any) error {
+	b, err := base64.RawURLEncoding.DecodeString(string(c))
+	if err != nil {
+		return invalidCursor()
+	}
+	if err := json.Unmarshal(b, target); err != nil {
+		return invalidCursor()
+	}
+	return nil
+}
+
+// String returns the encoded cursor
+func (c Cursor) String() string {
+	return string(c)
+}
+
+// invalidCursor returns the error of a cursor that cannot be decoded
+func invalidCursor() error {
+	return exception.ValidationErrors{{
+		Field:   QueryCursor,
+		Code:    "invalid",
+		Message: "cursor is invalid",
+	}}
+}
+
+// Page is the page requested by a list request
+type Page struct {
+	// Limit is the maximum number of items to return
+	Limit Limit
+	// Offset is the number of items to skip, for offset pagination
+	Offset int
+	// Cursor is the position to start from, empty for the first page or offset pagination
+	Cursor Cursor
+}
+
+// FromRequest parses the limit, offset and cursor query parameters of r.
+// A missing limit uses the configured default; offset and cursor cannot be
+// combined. Invalid parameters are reported as exception.ValidationErrors.
+//
+// Example usage:
+//
+//	page, err := pagination.FromRequest(r)
+//	if err != nil {
+//	    httphelper.Error(w, err)
+//	    return
+//	}
+//	orders, total, err := repo.List(r.Context(), page.Limit.Int(), page.Offset)
+//
+// Parameters:
+//   - r: The HTTP request
+//
+// Returns:
+//   - The requested Page
+//   - A VALIDATION_FAILED error if a parameter is invalid
+func FromRequest(r *http.Request) (Page, error) {
+	cfg := defaultConfig.Load()
+	query := r.URL.Query()
+	page := Page{Limit: cfg.defaultLimit}
+
+	var errs exception.ValidationErrors
+	if v := query.Get(QueryLimit); v != "" {
+		limit, err := strconv.Atoi(v)
+		switch {
+		case err != nil:
+			errs = append(errs, exception.FieldError{Field: QueryLimit, Code: "invalid", Message: "limit must be an integer"})
+		case limit < 1 || Limit(limit) > cfg.maxLimit:
+			errs = append(errs, exception.FieldError{Field: QueryLimit, Code: "out_of_range", Message: fmt.Sprintf("limit must be between 1 and %d", cfg.maxLimit)})
+		default:
+			page.Limit = Limit(limit)
+		}
+	}
+	if v := query.Get(QueryOffset); v != "" {
+		offset, err := strconv.Atoi(v)
+		switch {
+		case err != nil:
+			errs = append(errs, exception.FieldError{Field: QueryOffset, Code: "invalid", Message: "offset must be an integer"})
+		case offset < 0:
+			errs = append(errs, exception.FieldError{Field: QueryOffset, Code: "out_of_range", Message: "offset must not be negative"})
+		default:
+			page.Offset = offset
+		}
+	}
+	page.Cursor = Cursor(query.Get(QueryCursor))
+	if page.Cursor != "" && query.Get(QueryOffset) != "" {
+		errs = append(errs, exception.FieldError{Field: QueryCursor, Code: "conflict", Message: "cursor cannot be combined with offset"})
+	}
+
+	if len(errs) > 0 {
+		return Page{}, errs
+	}
+	return page, nil
+}
+
+// Result returns the pagination information of the response to the page,
+// given the total number of items and the cursor of the next page, empty
+// on the last page or for offset pagination
+func (p Page) Result(total int, next Cursor) httphelper.Page {
+	return httphelper.Page{
+		Total:      total,
+		Limit:      p.Limit.Int(),
+		Offset:     p.Offset,
+		NextCursor: next.String(),
+	}
+}
+
+// OK writes a successful list response with the pagination information of
+// the page, see Page.Result.
+//
+// Example usage:
+//
+//	pagination.OK(w, orders, page, total, "")
+//
+// Parameters:
+//   - w: The HTTP response writer
+//   - data: The items of the page
+//   - page: The page requested
+//   - total: The total number of items across all pages
+//   - next: The cursor of the next page, empty on the last page or for offset pagination
+//   - opts: Options customizing the response headers, such as httphelper.WithCacheControl
+func OK(w http.ResponseWriter, data any, page Page, total int, next Cursor, opts ...httphelper.WriteOption) {
+	httphelper.OKWithPage(w, data, page.Result(total, next), opts...)
+}
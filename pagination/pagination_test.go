@@ -0,0 +1,83 @@
+package pagination_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/aeramu/apihelper/exception"
+	"github.com/aeramu/apihelper/httphelper"
+	"github.com/aeramu/apihelper/pagination"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromRequest(t *testing.T) {
+	page, err := pagination.FromRequest(httptest.NewRequest(http.MethodGet, "/orders", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, pagination.Page{Limit: 20}, page)
+
+	page, err = pagination.FromRequest(httptest.NewRequest(http.MethodGet, "/orders?limit=50&offset=100", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, pagination.Page{Limit: 50, Offset: 100}, page)
+
+	_, err = pagination.FromRequest(httptest.NewRequest(http.MethodGet, "/orders?limit=500&offset=-1&cursor=abc", nil))
+	var errs exception.ValidationErrors
+	assert.True(t, errors.As(err, &errs))
+	fields := make([]string, len(errs))
+	for i, e := range errs {
+		fields[i] = e.Field
+	}
+	assert.Equal(t, []string{"limit", "offset", "cursor"}, fields)
+}
+
+func TestCursor(t *testing.T) {
+	cursor, err := pagination.EncodeCursor(map[string]int64{"id": 42})
+	assert.NoError(t, err)
+
+	page, err := pagination.FromRequest(httptest.NewRequest(http.MethodGet, "/orders?cursor="+cursor.String(), nil))
+	assert.NoError(t, err)
+	var position map[string]int64
+	assert.NoError(t, page.Cursor.Decode(&position))
+	assert.Equal(t, int64(42), position["id"])
+
+	err = pagination.Cursor("!!").Decode(&position)
+	assert.Error(t, err)
+	httpErr, ok := httphelper.AsHTTPError(err)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusUnprocessableEntity, httpErr.HTTPStatus())
+}
+
+func TestOK(t *testing.T) {
+	rec := httptest.NewRecorder()
+	pagination.OK(rec, []string{"a", "b"}, pagination.Page{Limit: 2, Offset: 2}, 10, "")
+
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	page, ok := result.Page()
+	assert.True(t, ok)
+	assert.Equal(t, httphelper.Page{Total: 10, Limit: 2, Offset: 2}, page)
+	assert.True(t, page.HasNext())
+}
+
+func TestConfigure_Concurrent(t *testing.T) {
+	defer pagination.Configure(pagination.WithMaxLimit(100))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			pagination.Configure(pagination.WithMaxLimit(pagination.Limit(50 + i)))
+		}(i)
+		go func() {
+			defer wg.Done()
+			page, err := pagination.FromRequest(httptest.NewRequest(http.MethodGet, "/orders?limit=10", nil))
+			assert.NoError(t, err)
+			assert.Equal(t, pagination.Limit(10), page.Limit)
+		}()
+	}
+	wg.Wait()
+}
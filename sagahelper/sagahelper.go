@@ -0,0 +1,81 @@
+package sagahelper
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aeramu/apihelper/exception"
+)
+
+// Package sagahelper orchestrates multi-step operations where each step can be
+// undone by a compensation, such as reserving stock, charging a payment and
+// creating an order.
+//
+// Example usage:
+//
+//	err := sagahelper.New().
+//	    Step("reserve_stock", reserveStock, releaseStock).
+//	    Step("charge_payment", chargePayment, refundPayment).
+//	    Step("create_order", createOrder, nil).
+//	    Run(ctx)
+
+// Saga is an ordered list of steps with their compensations
+type Saga struct {
+	steps []step
+}
+
+type step struct {
+	name       string
+	action     func(ctx context.Context) error
+	compensate func(ctx context.Context) error
+}
+
+// New creates an empty Saga
+func New() *Saga {
+	return &Saga{}
+}
+
+// Step appends a step to the saga. The compensation undoes the action and
+// may be nil when there is nothing to undo.
+func (s *Saga) Step(name string, action, compensate func(ctx context.Context) error) *Saga {
+	s.steps = append(s.steps, step{
+		name:       name,
+		action:     action,
+		compensate: compensate,
+	})
+	return s
+}
+
+// Run executes the steps in order. When a step fails, the compensations of
+// the completed steps run in reverse order.
+//
+// The returned exception keeps the classification of the failing step error,
+// records the step name in the "step" detail, and joins any compensation
+// failures so they remain reachable through errors.Is and errors.As.
+func (s *Saga) Run(ctx context.Context) error {
+	for i, st := range s.steps {
+		err := st.action(ctx)
+		if err == nil {
+			continue
+		}
+
+		errs := []error{err}
+		for j := i - 1; j >= 0; j-- {
+			completed := s.steps[j]
+			if completed.compensate == nil {
+				continue
+			}
+			if cerr := completed.compensate(context.WithoutCancel(ctx)); cerr != nil {
+				errs = append(errs, exception.Wrap(cerr, fmt.Sprintf("compensation of step %s failed", completed.name),
+					exception.WithDetail("step", completed.name),
+				))
+			}
+		}
+
+		return exception.Wrap(exception.Join(errs...), fmt.Sprintf("saga step %s failed", st.name),
+			exception.Inherit(err),
+			exception.WithDetail("step", st.name),
+		)
+	}
+	return nil
+}
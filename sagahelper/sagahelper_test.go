@@ -0,0 +1,49 @@
+package sagahelper_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aeramu/apihelper/exception"
+	"github.com/aeramu/apihelper/httphelper"
+	"github.com/aeramu/apihelper/sagahelper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	var calls []string
+	record := func(name string, err error) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			calls = append(calls, name)
+			return err
+		}
+	}
+	errRefund := errors.New("refund failed")
+
+	err := sagahelper.New().
+		Step("reserve", record("reserve", nil), record("release", nil)).
+		Step("charge", record("charge", nil), record("refund", errRefund)).
+		Step("create", record("create", exception.ErrorAlreadyExists), nil).
+		Run(context.Background())
+
+	assert.Equal(t, []string{"reserve", "charge", "create", "refund", "release"}, calls)
+	assert.True(t, errors.Is(err, exception.ErrorAlreadyExists))
+	assert.True(t, errors.Is(err, errRefund))
+
+	httpErr, ok := httphelper.AsHTTPError(err)
+	assert.True(t, ok)
+	assert.Equal(t, exception.CodeAlreadyExists.String(), httpErr.Code())
+
+	var detailsErr interface{ Details() map[string]any }
+	assert.True(t, errors.As(err, &detailsErr))
+	assert.Equal(t, "create", detailsErr.Details()["step"])
+}
+
+func TestRun_Success(t *testing.T) {
+	err := sagahelper.New().
+		Step("noop", func(ctx context.Context) error { return nil }, nil).
+		Run(context.Background())
+
+	assert.NoError(t, err)
+}
@@ -0,0 +1,131 @@
+// Package scaffold generates a minimal service wired with the package's
+// golden path: typed handlers on the Router with their OpenAPI document,
+// the response envelope, the exception registry, response meta, request
+// logs, expvar response metrics, trace ID propagation, a health check and
+// graceful shutdown.
+//
+// Example usage:
+//
+//	err := scaffold.Generate("./orders", scaffold.Config{
+//	    Module: "github.com/acme/orders",
+//	    Name:   "orders",
+//	})
+package scaffold
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var templatesFS embed.FS
+
+var templates = template.Must(template.ParseFS(templatesFS, "templates/*.tmpl"))
+
+// GoVersion is the go directive of the generated go.mod, the minimum Go
+// version required by this module
+const GoVersion = "1.22"
+
+// files maps the generated file names to their templates
+var files = map[string]string{
+	"go.mod":     "go.mod.tmpl",
+	"main.go":    "main.go.tmpl",
+	"errors.go":  "errors.go.tmpl",
+	"handler.go": "handler.go.tmpl",
+}
+
+// Config describes the service to generate
+type Config struct {
+	// Module is the Go module path of the service
+	Module string
+	// Name is the service name, defaults to the last element of Module
+	Name string
+	// Owner is the team owning the service error codes, defaults to Name
+	Owner string
+	// Port is the port the service listens on, defaults to 8080
+	Port int
+	// HelperVersion is the apihelper version required by the service.
+	// When empty, the requirement is left to go mod tidy.
+	HelperVersion string
+	// GoVersion is the go directive of the generated go.mod, defaults to GoVersion
+	GoVersion string
+}
+
+// ErrorPrefix returns the prefix of the service error codes
+func (c Config) ErrorPrefix() string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(c.Name))
+}
+
+func (c Config) withDefaults() (Config, error) {
+	if c.Module == "" {
+		return c, fmt.Errorf("module is required")
+	}
+	if c.Name == "" {
+		c.Name = c.Module[strings.LastIndex(c.Module, "/")+1:]
+	}
+	if c.Owner == "" {
+		c.Owner = c.Name
+	}
+	if c.Port == 0 {
+		c.Port = 8080
+	}
+	if c.GoVersion == "" {
+		c.GoVersion = GoVersion
+	}
+	return c, nil
+}
+
+// Files renders the service files keyed by their path relative to the service root
+func Files(cfg Config) (map[string][]byte, error) {
+	cfg, err := cfg.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	rendered := make(map[string][]byte, len(files))
+	for name, tmpl := range files {
+		var buf bytes.Buffer
+		if err := templates.ExecuteTemplate(&buf, tmpl, cfg); err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", name, err)
+		}
+		content := buf.Bytes()
+		if filepath.Ext(name) == ".go" {
+			if content, err = format.Source(content); err != nil {
+				return nil, fmt.Errorf("failed to format %s: %w", name, err)
+			}
+		}
+		rendered[name] = content
+	}
+	return rendered, nil
+}
+
+// Generate writes the service files into dir, creating it if needed.
+// Existing files are not overwritten. Run go mod tidy in dir afterwards to
+// resolve the dependencies.
+func Generate(dir string, cfg Config) error {
+	rendered, err := Files(cfg)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for name := range rendered {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists", path)
+		}
+	}
+	for name, content := range rendered {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
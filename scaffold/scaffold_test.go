@@ -0,0 +1,48 @@
+package scaffold_test
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/aeramu/apihelper/scaffold"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFiles(t *testing.T) {
+	files, err := scaffold.Files(scaffold.Config{Module: "github.com/acme/order-service"})
+
+	assert.NoError(t, err)
+	assert.Len(t, files, 4)
+	assert.Contains(t, string(files["go.mod"]), "module github.com/acme/order-service")
+	assert.Contains(t, string(files["go.mod"]), "go "+scaffold.GoVersion+"\n")
+	assert.Contains(t, string(files["main.go"]), `":8080"`)
+	assert.Contains(t, string(files["main.go"]), `router.GET("/greetings/{name}"`)
+	assert.Contains(t, string(files["main.go"]), `expvar.Handler()`)
+	assert.Contains(t, string(files["errors.go"]), `"ORDER_SERVICE_GREETING_NOT_FOUND"`)
+	assert.Contains(t, string(files["errors.go"]), `exception.WithOwner("order-service")`)
+
+	_, err = scaffold.Files(scaffold.Config{})
+	assert.Error(t, err)
+}
+
+func TestGoVersion(t *testing.T) {
+	goMod, err := os.ReadFile("../go.mod")
+	assert.NoError(t, err)
+	directive := regexp.MustCompile(`(?m)^go (\S+)$`).FindSubmatch(goMod)
+	assert.NotNil(t, directive)
+	assert.Equal(t, string(directive[1]), scaffold.GoVersion, "GoVersion must match the go directive of the module")
+}
+
+func TestGenerate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "orders")
+	cfg := scaffold.Config{Module: "github.com/acme/orders", Port: 9000}
+
+	assert.NoError(t, scaffold.Generate(dir, cfg))
+	main, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(main), `":9000"`)
+
+	assert.Error(t, scaffold.Generate(dir, cfg))
+}
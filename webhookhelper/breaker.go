@@ -0,0 +1,50 @@
+package webhookhelper
+
+import (
+	"sync"
+	"time"
+)
+
+// breaker is a per-endpoint circuit breaker that opens after a number of
+// consecutive failures and lets a single probe through once the open period ends
+type breaker struct {
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// allow reports whether a delivery may be attempted at the given time.
+// Once the open period ends only one caller is let through until its
+// outcome is recorded.
+func (b *breaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if now.Before(b.openUntil) {
+		return false
+	}
+	if b.openUntil.IsZero() {
+		return true
+	}
+	if b.probing {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// record updates the breaker with the outcome of a delivery
+func (b *breaker) record(success bool, threshold int, openDuration time.Duration, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if success {
+		b.failures = 0
+		b.openUntil = time.Time{}
+		return
+	}
+	b.failures++
+	if threshold > 0 && b.failures >= threshold {
+		b.openUntil = now.Add(openDuration)
+	}
+}
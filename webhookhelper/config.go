@@ -0,0 +1,122 @@
+package webhookhelper
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// Configuration options
+type config struct {
+	client           *http.Client
+	allowPrivate     bool
+	store            Store
+	maxAttempts      int
+	baseBackoff      time.Duration
+	maxBackoff       time.Duration
+	failureThreshold int
+	openDuration     time.Duration
+
+	schemaValidator   SchemaValidator
+	onSchemaViolation func(event Event, err error)
+}
+
+// Option represents a configuration option for the Dispatcher
+type Option func(*config)
+
+// defaultConfig represents the default configuration
+var defaultConfig = config{
+	store:            nopStore{},
+	maxAttempts:      5,
+	baseBackoff:      500 * time.Millisecond,
+	maxBackoff:       30 * time.Second,
+	failureThreshold: 5,
+	openDuration:     time.Minute,
+}
+
+// WithHTTPClient sets the HTTP client used to deliver webhooks. It is used as
+// is: unlike the default client, it only refuses non-public addresses if its
+// transport does.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *config) {
+		c.client = client
+	}
+}
+
+// WithPrivateAddresses allows subscription urls resolving to loopback,
+// private and link-local addresses, and the default client to deliver to
+// them, which are both refused by default. Only enable it when the
+// subscribers are trusted, e.g. in tests and development.
+func WithPrivateAddresses() Option {
+	return func(c *config) {
+		c.allowPrivate = true
+	}
+}
+
+// newClient returns the default delivery client. Unless allowPrivate, its
+// dialer checks every address it connects to with dialPublic, and it does not
+// use a proxy, which would dial on its behalf.
+func newClient(allowPrivate bool) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !allowPrivate {
+		dialer := &net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			Control:   dialPublic,
+		}
+		transport.Proxy = nil
+		transport.DialContext = dialer.DialContext
+	}
+	return &http.Client{Timeout: 10 * time.Second, Transport: transport}
+}
+
+// WithStore sets the store used to persist delivery logs
+func WithStore(store Store) Option {
+	return func(c *config) {
+		c.store = store
+	}
+}
+
+// WithMaxAttempts sets the maximum number of delivery attempts per event. It
+// panics when attempts is below 1, as no event could then be delivered.
+func WithMaxAttempts(attempts int) Option {
+	if attempts < 1 {
+		panic("webhookhelper: WithMaxAttempts requires at least 1 attempt")
+	}
+	return func(c *config) {
+		c.maxAttempts = attempts
+	}
+}
+
+// WithBackoff sets the base and maximum delay of the exponential retry backoff
+func WithBackoff(base, max time.Duration) Option {
+	return func(c *config) {
+		c.baseBackoff = base
+		c.maxBackoff = max
+	}
+}
+
+// WithCircuitBreaker sets how many consecutive failed deliveries open the
+// circuit of an endpoint and how long it stays open
+func WithCircuitBreaker(threshold int, openDuration time.Duration) Option {
+	return func(c *config) {
+		c.failureThreshold = threshold
+		c.openDuration = openDuration
+	}
+}
+
+// WithSchemaValidator validates every event payload before it is dispatched.
+// Events that fail validation are rejected with a VALIDATION_FAILED exception.
+func WithSchemaValidator(validator SchemaValidator) Option {
+	return func(c *config) {
+		c.schemaValidator = validator
+	}
+}
+
+// WithSchemaViolationHook sets a hook invoked for every rejected event,
+// typically used to record metrics on schema violations
+func WithSchemaViolationHook(hook func(event Event, err error)) Option {
+	return func(c *config) {
+		c.onSchemaViolation = hook
+	}
+}
@@ -0,0 +1,48 @@
+package webhookhelper
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/aeramu/apihelper/exception"
+)
+
+// SchemaValidator validates event payloads against a schema registry before
+// they are dispatched. Implementations typically look up the JSON Schema or
+// protobuf descriptor registered for the event type.
+type SchemaValidator interface {
+	Validate(ctx context.Context, eventType string, data []byte) error
+}
+
+// SchemaValidatorFunc adapts a function to the SchemaValidator interface
+type SchemaValidatorFunc func(ctx context.Context, eventType string, data []byte) error
+
+// Validate calls f(ctx, eventType, data)
+func (f SchemaValidatorFunc) Validate(ctx context.Context, eventType string, data []byte) error {
+	return f(ctx, eventType, data)
+}
+
+// validateSchema checks the event data with the configured validator and
+// reports violations to the configured hook
+func (d *Dispatcher) validateSchema(ctx context.Context, event Event) error {
+	if d.cfg.schemaValidator == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return exception.Wrap(err, "failed to marshal event data")
+	}
+
+	if err := d.cfg.schemaValidator.Validate(ctx, event.Type, data); err != nil {
+		if d.cfg.onSchemaViolation != nil {
+			d.cfg.onSchemaViolation(event, err)
+		}
+		return exception.Wrap(err, "event payload does not match schema",
+			exception.WithStatus(exception.StatusValidationFailed),
+			exception.WithCode(exception.CodeValidationFailed),
+			exception.WithMessage("event payload does not match schema for "+event.Type),
+		)
+	}
+	return nil
+}
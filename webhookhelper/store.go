@@ -0,0 +1,105 @@
+package webhookhelper
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aeramu/apihelper/exception"
+)
+
+// Delivery records a single attempt to deliver an event to an endpoint
+type Delivery struct {
+	// EventID identifies the delivered event
+	EventID string `json:"event_id"`
+	// EndpointID identifies the subscriber endpoint
+	EndpointID string `json:"endpoint_id"`
+	// Attempt is the 1-based attempt number
+	Attempt int `json:"attempt"`
+	// StatusCode is the HTTP status returned by the endpoint, 0 if no response was received
+	StatusCode int `json:"status_code"`
+	// Error describes why the attempt failed, empty on success
+	Error string `json:"error,omitempty"`
+	// Duration is how long the attempt took
+	Duration time.Duration `json:"duration"`
+	// Time is when the attempt started
+	Time time.Time `json:"time"`
+}
+
+// Success reports whether the attempt was delivered successfully
+func (d Delivery) Success() bool {
+	return d.Error == ""
+}
+
+// Store persists delivery logs
+type Store interface {
+	SaveDelivery(ctx context.Context, delivery Delivery) error
+}
+
+type nopStore struct{}
+
+func (nopStore) SaveDelivery(context.Context, Delivery) error {
+	return nil
+}
+
+// MemoryStore is an in-memory Store, mainly useful for tests and development
+type MemoryStore struct {
+	mu            sync.Mutex
+	deliveries    []Delivery
+	subscriptions map[string]Subscription
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		subscriptions: make(map[string]Subscription),
+	}
+}
+
+// SaveDelivery appends the delivery to the log
+func (s *MemoryStore) SaveDelivery(_ context.Context, delivery Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries = append(s.deliveries, delivery)
+	return nil
+}
+
+// Deliveries returns a copy of all recorded deliveries
+func (s *MemoryStore) Deliveries() []Delivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Delivery(nil), s.deliveries...)
+}
+
+// CreateSubscription stores a new subscription
+func (s *MemoryStore) CreateSubscription(_ context.Context, subscription Subscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subscriptions[subscription.ID]; ok {
+		return exception.ErrorAlreadyExists
+	}
+	s.subscriptions[subscription.ID] = subscription
+	return nil
+}
+
+// GetSubscription returns the subscription with the given ID
+func (s *MemoryStore) GetSubscription(_ context.Context, id string) (Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subscription, ok := s.subscriptions[id]
+	if !ok {
+		return Subscription{}, exception.ErrorNotFound
+	}
+	return subscription, nil
+}
+
+// UpdateSubscription replaces an existing subscription
+func (s *MemoryStore) UpdateSubscription(_ context.Context, subscription Subscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subscriptions[subscription.ID]; !ok {
+		return exception.ErrorNotFound
+	}
+	s.subscriptions[subscription.ID] = subscription
+	return nil
+}
@@ -0,0 +1,314 @@
+package webhookhelper
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/aeramu/apihelper/exception"
+	"github.com/aeramu/apihelper/httphelper"
+)
+
+// EventPing is the type of the event sent by the ping endpoint
+const EventPing = "webhook.ping"
+
+// Subscription is an endpoint registered to receive events
+type Subscription struct {
+	Endpoint
+	// Events lists the event types the subscriber wants to receive, empty means all events
+	Events []string `json:"events"`
+	// CreatedAt is when the subscription was registered
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SubscriptionStore persists subscriptions.
+// GetSubscription must return an error matching exception.ErrorNotFound
+// when the subscription does not exist.
+type SubscriptionStore interface {
+	CreateSubscription(ctx context.Context, subscription Subscription) error
+	GetSubscription(ctx context.Context, id string) (Subscription, error)
+	UpdateSubscription(ctx context.Context, subscription Subscription) error
+}
+
+// Handler exposes HTTP handlers that let subscribers manage their webhook endpoints.
+// All handlers accept JSON bodies and respond with the standard httphelper envelope.
+type Handler struct {
+	store      SubscriptionStore
+	dispatcher *Dispatcher
+	opts       handlerOptions
+}
+
+type handlerOptions struct {
+	authorize   func(r *http.Request, subscription Subscription) error
+	resolver    *net.Resolver
+	pingTimeout time.Duration
+}
+
+// HandlerOption configures a Handler
+type HandlerOption func(*handlerOptions)
+
+// WithAuthorizer sets the hook deciding whether the caller may operate on an
+// existing subscription. It runs before RotateSecret and Ping and its error,
+// typically a PERMISSION_DENIED exception, is returned to the caller as is.
+func WithAuthorizer(authorize func(r *http.Request, subscription Subscription) error) HandlerOption {
+	return func(o *handlerOptions) {
+		o.authorize = authorize
+	}
+}
+
+// WithResolver sets the resolver used to check the addresses of subscription urls
+func WithResolver(resolver *net.Resolver) HandlerOption {
+	return func(o *handlerOptions) {
+		o.resolver = resolver
+	}
+}
+
+// WithPingTimeout sets how long Ping waits for the endpoint to answer, 5 seconds by default
+func WithPingTimeout(timeout time.Duration) HandlerOption {
+	return func(o *handlerOptions) {
+		o.pingTimeout = timeout
+	}
+}
+
+// NewHandler creates a Handler backed by the given store and dispatcher
+func NewHandler(store SubscriptionStore, dispatcher *Dispatcher, opts ...HandlerOption) *Handler {
+	o := handlerOptions{
+		resolver:    net.DefaultResolver,
+		pingTimeout: 5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Handler{
+		store:      store,
+		dispatcher: dispatcher,
+		opts:       o,
+	}
+}
+
+// RegisterRequest is the body accepted by Handler.Register
+type RegisterRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+}
+
+// SubscriptionRequest is the body accepted by handlers operating on an existing subscription
+type SubscriptionRequest struct {
+	ID string `json:"id"`
+}
+
+// SecretResponse is returned whenever a signing secret is issued.
+// The secret is only revealed at registration and rotation time.
+type SecretResponse struct {
+	Subscription
+	Secret string `json:"secret"`
+}
+
+// Register creates a new subscription and returns it together with its signing secret
+func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
+	var req RegisterRequest
+	if err := decode(r, &req); err != nil {
+		httphelper.Error(w, err)
+		return
+	}
+	if err := h.validateURL(r.Context(), req.URL); err != nil {
+		httphelper.Error(w, err)
+		return
+	}
+
+	id, err := randomHex(12)
+	if err != nil {
+		httphelper.Error(w, err)
+		return
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		httphelper.Error(w, err)
+		return
+	}
+
+	subscription := Subscription{
+		Endpoint: Endpoint{
+			ID:     "wh_" + id,
+			URL:    req.URL,
+			Secret: secret,
+		},
+		Events:    req.Events,
+		CreatedAt: time.Now(),
+	}
+	if err := h.store.CreateSubscription(r.Context(), subscription); err != nil {
+		httphelper.Error(w, err)
+		return
+	}
+
+	httphelper.OK(w, SecretResponse{
+		Subscription: subscription,
+		Secret:       subscription.Secret,
+	})
+}
+
+// RotateSecret replaces the signing secret of a subscription and returns the new one
+func (h *Handler) RotateSecret(w http.ResponseWriter, r *http.Request) {
+	subscription, err := h.subscription(r)
+	if err != nil {
+		httphelper.Error(w, err)
+		return
+	}
+
+	subscription.Secret, err = randomHex(32)
+	if err != nil {
+		httphelper.Error(w, err)
+		return
+	}
+	if err := h.store.UpdateSubscription(r.Context(), subscription); err != nil {
+		httphelper.Error(w, err)
+		return
+	}
+
+	httphelper.OK(w, SecretResponse{
+		Subscription: subscription,
+		Secret:       subscription.Secret,
+	})
+}
+
+// Ping delivers a test event to the subscription endpoint and returns the outcome.
+// It makes a single attempt bounded by the ping timeout, without retries.
+func (h *Handler) Ping(w http.ResponseWriter, r *http.Request) {
+	subscription, err := h.subscription(r)
+	if err != nil {
+		httphelper.Error(w, err)
+		return
+	}
+	// the url is checked again since its host may resolve differently since registration
+	if err := h.validateURL(r.Context(), subscription.URL); err != nil {
+		httphelper.Error(w, err)
+		return
+	}
+
+	id, err := randomHex(12)
+	if err != nil {
+		httphelper.Error(w, err)
+		return
+	}
+	event := Event{
+		ID:   "evt_" + id,
+		Type: EventPing,
+	}
+	if err := h.dispatcher.ping(r.Context(), subscription.Endpoint, event, h.opts.pingTimeout); err != nil {
+		httphelper.Error(w, err)
+		return
+	}
+
+	httphelper.OK(w, event)
+}
+
+func (h *Handler) subscription(r *http.Request) (Subscription, error) {
+	var req SubscriptionRequest
+	if err := decode(r, &req); err != nil {
+		return Subscription{}, err
+	}
+	if req.ID == "" {
+		return Subscription{}, exception.New("subscription id is required",
+			exception.WithStatus(exception.StatusValidationFailed),
+			exception.WithCode(exception.CodeValidationFailed),
+			exception.WithMessage("subscription id is required"),
+		)
+	}
+	subscription, err := h.store.GetSubscription(r.Context(), req.ID)
+	if err != nil {
+		return Subscription{}, err
+	}
+	if h.opts.authorize != nil {
+		if err := h.opts.authorize(r, subscription); err != nil {
+			return Subscription{}, err
+		}
+	}
+	return subscription, nil
+}
+
+func decode(r *http.Request, v any) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return exception.Wrap(err, "failed to decode request body",
+			exception.WithStatus(exception.StatusInvalidRequest),
+			exception.WithCode(exception.CodeInvalidRequest),
+			exception.WithMessage("invalid request body"),
+		)
+	}
+	return nil
+}
+
+// validateURL checks that raw is an absolute http or https url and, unless
+// the dispatcher allows private addresses, that its host only resolves to
+// public addresses
+func (h *Handler) validateURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return exception.New("invalid webhook url",
+			exception.WithStatus(exception.StatusValidationFailed),
+			exception.WithCode(exception.CodeValidationFailed),
+			exception.WithMessage("url must be an absolute http or https url"),
+		)
+	}
+	if h.dispatcher.cfg.allowPrivate {
+		return nil
+	}
+
+	var addrs []netip.Addr
+	if addr, err := netip.ParseAddr(u.Hostname()); err == nil {
+		addrs = []netip.Addr{addr}
+	} else if addrs, err = h.opts.resolver.LookupNetIP(ctx, "ip", u.Hostname()); err != nil {
+		return exception.Wrap(err, "failed to resolve webhook url",
+			exception.WithStatus(exception.StatusValidationFailed),
+			exception.WithCode(exception.CodeValidationFailed),
+			exception.WithMessage("url host cannot be resolved"),
+		)
+	}
+	for _, addr := range addrs {
+		if !isPublic(addr) {
+			return exception.New("webhook url resolves to a non-public address "+addr.String(),
+				exception.WithStatus(exception.StatusValidationFailed),
+				exception.WithCode(exception.CodeValidationFailed),
+				exception.WithMessage("url must point to a public address"),
+			)
+		}
+	}
+	return nil
+}
+
+// dialPublic is the net.Dialer Control function of the default delivery
+// client, refusing to connect to non-public addresses so a subscription host
+// pointed at an internal address after it was validated receives nothing
+func dialPublic(network, address string, _ syscall.RawConn) error {
+	addr, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !isPublic(addr.Addr()) {
+		return fmt.Errorf("webhook endpoint address %s is not public", addr.Addr())
+	}
+	return nil
+}
+
+func isPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() &&
+		!addr.IsPrivate() &&
+		!addr.IsLoopback() &&
+		!addr.IsLinkLocalUnicast()
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", exception.Wrap(err, "failed to generate random bytes")
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,285 @@
+package webhookhelper
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aeramu/apihelper/exception"
+	"github.com/aeramu/apihelper/httphelper"
+)
+
+// Package webhookhelper delivers event payloads to subscriber endpoints.
+// Payloads are wrapped in the standard httphelper envelope, signed with
+// HMAC-SHA256 and retried with exponential backoff on retryable failures.
+//
+// Example usage:
+//
+//	dispatcher := webhookhelper.NewDispatcher(
+//	    webhookhelper.WithStore(store),
+//	)
+//	err := dispatcher.Dispatch(ctx, endpoint, webhookhelper.Event{
+//	    ID:   "evt_123",
+//	    Type: "order.created",
+//	    Data: order,
+//	})
+
+const (
+	// HeaderSignature carries the hex encoded HMAC-SHA256 signature of the request
+	HeaderSignature = "X-Webhook-Signature"
+	// HeaderTimestamp carries the unix timestamp used when computing the signature
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	// HeaderEventID carries the ID of the delivered event
+	HeaderEventID = "X-Webhook-Event-Id"
+	// HeaderEventType carries the type of the delivered event
+	HeaderEventType = "X-Webhook-Event-Type"
+)
+
+// Endpoint is a subscriber URL that receives events
+type Endpoint struct {
+	// ID uniquely identifies the endpoint
+	ID string `json:"id"`
+	// URL is where the events are delivered
+	URL string `json:"url"`
+	// Secret is the key used to sign the payloads
+	Secret string `json:"-"`
+}
+
+// Event is a payload delivered to subscribers
+type Event struct {
+	// ID uniquely identifies the event, subscribers use it for deduplication
+	ID string `json:"id"`
+	// Type is the name of the event, e.g. "order.created"
+	Type string `json:"type"`
+	// Data is the event payload
+	Data any `json:"data"`
+}
+
+// Dispatcher delivers events to subscriber endpoints
+type Dispatcher struct {
+	cfg config
+
+	mu       sync.Mutex
+	breakers map[string]*breaker
+}
+
+// NewDispatcher creates a Dispatcher with the given options applied on top of the defaults
+func NewDispatcher(opts ...Option) *Dispatcher {
+	cfg := defaultConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.client == nil {
+		cfg.client = newClient(cfg.allowPrivate)
+	}
+	return &Dispatcher{
+		cfg:      cfg,
+		breakers: make(map[string]*breaker),
+	}
+}
+
+// Sign computes the hex encoded HMAC-SHA256 signature of the payload
+// for the given timestamp. Subscribers can use it to verify deliveries.
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether the signature matches the payload and timestamp
+func Verify(secret string, timestamp int64, payload []byte, signature string) bool {
+	expected := Sign(secret, timestamp, payload)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// Dispatch delivers the event to the endpoint, retrying retryable failures.
+// It returns an UNAVAILABLE exception when the endpoint circuit is open and a
+// THIRD_PARTY exception when all attempts fail.
+func (d *Dispatcher) Dispatch(ctx context.Context, endpoint Endpoint, event Event) error {
+	if err := d.validateSchema(ctx, event); err != nil {
+		return err
+	}
+
+	payload, err := marshalPayload(event)
+	if err != nil {
+		return err
+	}
+
+	b := d.breaker(endpoint.ID)
+	if !b.allow(time.Now()) {
+		return exception.New("webhook endpoint circuit is open",
+			exception.WithStatus(exception.StatusUnavailable),
+			exception.WithCode(exception.CodeUnavailable),
+			exception.WithMessage("webhook endpoint is unavailable"),
+		)
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= d.cfg.maxAttempts; attempt++ {
+		if attempt > 1 {
+			if err := sleep(ctx, d.backoff(attempt-1)); err != nil {
+				return exception.Wrap(err, "webhook delivery cancelled",
+					exception.WithStatus(exception.StatusDeadlineExceeded),
+					exception.WithCode(exception.CodeDeadlineExceeded),
+				)
+			}
+		}
+
+		delivery, retryable := d.deliver(ctx, endpoint, event, payload, attempt)
+		d.saveDelivery(ctx, delivery)
+		b.record(delivery.Success(), d.cfg.failureThreshold, d.cfg.openDuration, time.Now())
+		if delivery.Success() {
+			return nil
+		}
+
+		lastErr = errors.New(delivery.Error)
+		if !retryable || !b.allow(time.Now()) {
+			break
+		}
+	}
+
+	return exception.Wrap(lastErr, "webhook delivery failed",
+		exception.WithStatus(exception.StatusThirdParty),
+		exception.WithCode(exception.CodeThirdParty),
+		exception.WithMessage("webhook delivery failed"),
+	)
+}
+
+// ping performs a single delivery attempt bounded by timeout, bypassing the
+// retry policy and the circuit breaker so a subscriber gets an immediate answer
+func (d *Dispatcher) ping(ctx context.Context, endpoint Endpoint, event Event, timeout time.Duration) error {
+	payload, err := marshalPayload(event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	delivery, _ := d.deliver(ctx, endpoint, event, payload, 1)
+	d.saveDelivery(ctx, delivery)
+	if delivery.Success() {
+		return nil
+	}
+	return exception.Wrap(errors.New(delivery.Error), "webhook ping failed",
+		exception.WithStatus(exception.StatusThirdParty),
+		exception.WithCode(exception.CodeThirdParty),
+		exception.WithMessage("webhook delivery failed"),
+	)
+}
+
+// saveDelivery persists the delivery log. A store failure must not change the
+// outcome of a delivery that already happened, so it is logged instead.
+func (d *Dispatcher) saveDelivery(ctx context.Context, delivery Delivery) {
+	if err := d.cfg.store.SaveDelivery(ctx, delivery); err != nil {
+		slog.ErrorContext(ctx, "failed to save webhook delivery",
+			slog.String("event_id", delivery.EventID),
+			slog.String("endpoint_id", delivery.EndpointID),
+			slog.Int("attempt", delivery.Attempt),
+			slog.Any("error", err),
+		)
+	}
+}
+
+func marshalPayload(event Event) ([]byte, error) {
+	payload, err := json.Marshal(httphelper.Response{
+		Status:  http.StatusOK,
+		Success: true,
+		Data:    event,
+	})
+	if err != nil {
+		return nil, exception.Wrap(err, "failed to marshal webhook payload")
+	}
+	return payload, nil
+}
+
+// deliver performs a single delivery attempt and reports whether a failure is retryable
+func (d *Dispatcher) deliver(ctx context.Context, endpoint Endpoint, event Event, payload []byte, attempt int) (Delivery, bool) {
+	start := time.Now()
+	delivery := Delivery{
+		EventID:    event.ID,
+		EndpointID: endpoint.ID,
+		Attempt:    attempt,
+		Time:       start,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(payload))
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery, false
+	}
+	timestamp := start.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventID, event.ID)
+	req.Header.Set(HeaderEventType, event.Type)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(endpoint.Secret, timestamp, payload))
+
+	resp, err := d.cfg.client.Do(req)
+	delivery.Duration = time.Since(start)
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery, ctx.Err() == nil
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	delivery.StatusCode = resp.StatusCode
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return delivery, false
+	}
+	delivery.Error = fmt.Sprintf("unexpected status code %d", resp.StatusCode)
+	return delivery, isRetryableStatus(resp.StatusCode)
+}
+
+func (d *Dispatcher) breaker(endpointID string) *breaker {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	b, ok := d.breakers[endpointID]
+	if !ok {
+		b = &breaker{}
+		d.breakers[endpointID] = b
+	}
+	return b
+}
+
+// backoff returns the delay before the given retry, doubling each time up to maxBackoff
+func (d *Dispatcher) backoff(retry int) time.Duration {
+	delay := d.cfg.baseBackoff
+	for i := 1; i < retry; i++ {
+		delay *= 2
+		if delay >= d.cfg.maxBackoff {
+			return d.cfg.maxBackoff
+		}
+	}
+	return delay
+}
+
+func isRetryableStatus(status int) bool {
+	return status == http.StatusRequestTimeout ||
+		status == http.StatusTooManyRequests ||
+		status >= http.StatusInternalServerError
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,313 @@
+package webhookhelper_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aeramu/apihelper/exception"
+	"github.com/aeramu/apihelper/httphelper"
+	"github.com/aeramu/apihelper/webhookhelper"
+	"github.com/stretchr/testify/assert"
+)
+
+var event = webhookhelper.Event{
+	ID:   "evt_1",
+	Type: "order.created",
+	Data: map[string]string{"id": "order_1"},
+}
+
+func TestDispatch_Signed(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(webhookhelper.HeaderTimestamp), 10, 64)
+		assert.True(t, webhookhelper.Verify("secret", timestamp, body, r.Header.Get(webhookhelper.HeaderSignature)))
+		assert.Equal(t, "order.created", r.Header.Get(webhookhelper.HeaderEventType))
+		httphelper.OK(w, nil)
+	}))
+	defer ts.Close()
+
+	store := webhookhelper.NewMemoryStore()
+	dispatcher := webhookhelper.NewDispatcher(webhookhelper.WithPrivateAddresses(), webhookhelper.WithStore(store))
+
+	err := dispatcher.Dispatch(context.Background(), webhookhelper.Endpoint{ID: "ep_1", URL: ts.URL, Secret: "secret"}, event)
+
+	assert.NoError(t, err)
+	assert.Len(t, store.Deliveries(), 1)
+	assert.True(t, store.Deliveries()[0].Success())
+}
+
+func TestDispatch_Retry(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		httphelper.OK(w, nil)
+	}))
+	defer ts.Close()
+
+	store := webhookhelper.NewMemoryStore()
+	dispatcher := webhookhelper.NewDispatcher(
+		webhookhelper.WithPrivateAddresses(),
+		webhookhelper.WithStore(store),
+		webhookhelper.WithBackoff(time.Millisecond, 5*time.Millisecond),
+	)
+
+	err := dispatcher.Dispatch(context.Background(), webhookhelper.Endpoint{ID: "ep_1", URL: ts.URL}, event)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), calls)
+	assert.Len(t, store.Deliveries(), 3)
+}
+
+func TestDispatch_NonRetryable(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	dispatcher := webhookhelper.NewDispatcher(webhookhelper.WithPrivateAddresses(), webhookhelper.WithBackoff(time.Millisecond, time.Millisecond))
+
+	err := dispatcher.Dispatch(context.Background(), webhookhelper.Endpoint{ID: "ep_1", URL: ts.URL}, event)
+
+	code, ok := exception.AsErrorCode(err)
+	assert.True(t, ok)
+	assert.Equal(t, exception.CodeThirdParty.String(), code.Code())
+	assert.Equal(t, int32(1), calls)
+}
+
+func TestWithMaxAttempts_Invalid(t *testing.T) {
+	assert.Panics(t, func() { webhookhelper.WithMaxAttempts(0) })
+	assert.NotPanics(t, func() { webhookhelper.WithMaxAttempts(1) })
+}
+
+func TestDispatch_CircuitBreaker(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	dispatcher := webhookhelper.NewDispatcher(
+		webhookhelper.WithPrivateAddresses(),
+		webhookhelper.WithBackoff(time.Millisecond, time.Millisecond),
+		webhookhelper.WithCircuitBreaker(2, time.Minute),
+	)
+	endpoint := webhookhelper.Endpoint{ID: "ep_1", URL: ts.URL}
+
+	err := dispatcher.Dispatch(context.Background(), endpoint, event)
+	assert.Error(t, err)
+
+	err = dispatcher.Dispatch(context.Background(), endpoint, event)
+	var httpErr httphelper.HTTPError
+	assert.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, exception.CodeUnavailable.String(), httpErr.Code())
+	assert.Equal(t, http.StatusServiceUnavailable, httpErr.HTTPStatus())
+}
+
+func TestDispatch_CircuitBreakerSingleProbe(t *testing.T) {
+	var calls int32
+	received := make(chan struct{}, 1)
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) > 1 {
+			received <- struct{}{}
+			<-release
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	dispatcher := webhookhelper.NewDispatcher(
+		webhookhelper.WithPrivateAddresses(),
+		webhookhelper.WithMaxAttempts(1),
+		webhookhelper.WithCircuitBreaker(1, 10*time.Millisecond),
+	)
+	event := webhookhelper.Event{ID: "evt_1", Type: "order.created"}
+	endpoint := webhookhelper.Endpoint{ID: "ep_1", URL: ts.URL}
+
+	assert.Error(t, dispatcher.Dispatch(context.Background(), endpoint, event))
+	time.Sleep(20 * time.Millisecond)
+
+	probe := make(chan error, 1)
+	go func() {
+		probe <- dispatcher.Dispatch(context.Background(), endpoint, event)
+	}()
+	<-received
+
+	err := dispatcher.Dispatch(context.Background(), endpoint, event)
+	var httpErr httphelper.HTTPError
+	assert.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, exception.CodeUnavailable.String(), httpErr.Code())
+
+	close(release)
+	assert.Error(t, <-probe)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestHandler(t *testing.T) {
+	var pings int32
+	subscriber := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(webhookhelper.HeaderEventType) == webhookhelper.EventPing {
+			atomic.AddInt32(&pings, 1)
+		}
+		httphelper.OK(w, nil)
+	}))
+	defer subscriber.Close()
+
+	store := webhookhelper.NewMemoryStore()
+	handler := webhookhelper.NewHandler(store, webhookhelper.NewDispatcher(webhookhelper.WithPrivateAddresses()))
+
+	call := func(h http.HandlerFunc, body string) httphelper.Response {
+		rec := httptest.NewRecorder()
+		h(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		var result httphelper.Response
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		return result
+	}
+
+	result := call(handler.Register, `{"url":"`+subscriber.URL+`","events":["order.created"]}`)
+	registered, err := httphelper.ReadData[webhookhelper.SecretResponse](result)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, registered.ID)
+	assert.NotEmpty(t, registered.Secret)
+
+	result = call(handler.RotateSecret, `{"id":"`+registered.ID+`"}`)
+	rotated, err := httphelper.ReadData[webhookhelper.SecretResponse](result)
+	assert.NoError(t, err)
+	assert.NotEqual(t, registered.Secret, rotated.Secret)
+
+	result = call(handler.Ping, `{"id":"`+registered.ID+`"}`)
+	assert.NoError(t, result.Err())
+	assert.Equal(t, int32(1), pings)
+
+	result = call(handler.Ping, `{"id":"unknown"}`)
+	assert.Equal(t, exception.CodeNotFound.String(), result.Code())
+
+	result = call(handler.Register, `{"url":"ftp://example.com"}`)
+	assert.Equal(t, exception.CodeValidationFailed.String(), result.Code())
+}
+
+func TestDispatch_PrivateAddress(t *testing.T) {
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		httphelper.OK(w, nil)
+	}))
+	defer ts.Close()
+
+	dispatcher := webhookhelper.NewDispatcher(webhookhelper.WithMaxAttempts(1))
+	err := dispatcher.Dispatch(context.Background(), webhookhelper.Endpoint{ID: "ep_1", URL: ts.URL}, event)
+
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "is not public")
+	assert.Zero(t, atomic.LoadInt32(&hits))
+}
+
+func TestHandler_PrivateAddress(t *testing.T) {
+	handler := webhookhelper.NewHandler(webhookhelper.NewMemoryStore(), webhookhelper.NewDispatcher())
+
+	for _, url := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://10.0.0.1/hook",
+		"http://192.168.1.1/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/hook",
+		"http://0.0.0.0/hook",
+	} {
+		rec := httptest.NewRecorder()
+		handler.Register(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"url":"`+url+`"}`)))
+		var result httphelper.Response
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		assert.Equal(t, exception.CodeValidationFailed.String(), result.Code(), url)
+	}
+}
+
+func TestHandler_Authorizer(t *testing.T) {
+	store := webhookhelper.NewMemoryStore()
+	assert.NoError(t, store.CreateSubscription(context.Background(), webhookhelper.Subscription{
+		Endpoint: webhookhelper.Endpoint{ID: "wh_1", URL: "http://example.com", Secret: "secret"},
+	}))
+	handler := webhookhelper.NewHandler(store, webhookhelper.NewDispatcher(),
+		webhookhelper.WithAuthorizer(func(r *http.Request, subscription webhookhelper.Subscription) error {
+			return exception.New("not the owner",
+				exception.WithStatus(exception.StatusPermissionDenied),
+				exception.WithCode(exception.CodePermissionDenied),
+			)
+		}),
+	)
+
+	for _, h := range []http.HandlerFunc{handler.RotateSecret, handler.Ping} {
+		rec := httptest.NewRecorder()
+		h(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"id":"wh_1"}`)))
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	}
+
+	subscription, err := store.GetSubscription(context.Background(), "wh_1")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", subscription.Secret)
+}
+
+func TestHandler_PingSingleAttempt(t *testing.T) {
+	var calls int32
+	subscriber := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer subscriber.Close()
+
+	store := webhookhelper.NewMemoryStore()
+	assert.NoError(t, store.CreateSubscription(context.Background(), webhookhelper.Subscription{
+		Endpoint: webhookhelper.Endpoint{ID: "wh_1", URL: subscriber.URL},
+	}))
+	handler := webhookhelper.NewHandler(store,
+		webhookhelper.NewDispatcher(webhookhelper.WithPrivateAddresses(), webhookhelper.WithBackoff(time.Millisecond, time.Millisecond)),
+		webhookhelper.WithPingTimeout(time.Second),
+	)
+
+	rec := httptest.NewRecorder()
+	handler.Ping(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"id":"wh_1"}`)))
+	var result httphelper.Response
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, exception.CodeThirdParty.String(), result.Code())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestDispatch_SchemaViolation(t *testing.T) {
+	var calls, violations int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer ts.Close()
+
+	dispatcher := webhookhelper.NewDispatcher(
+		webhookhelper.WithPrivateAddresses(),
+		webhookhelper.WithSchemaValidator(webhookhelper.SchemaValidatorFunc(func(ctx context.Context, eventType string, data []byte) error {
+			return errors.New("missing property amount")
+		})),
+		webhookhelper.WithSchemaViolationHook(func(event webhookhelper.Event, err error) {
+			atomic.AddInt32(&violations, 1)
+		}),
+	)
+
+	err := dispatcher.Dispatch(context.Background(), webhookhelper.Endpoint{ID: "ep_1", URL: ts.URL}, event)
+
+	code, ok := exception.AsErrorCode(err)
+	assert.True(t, ok)
+	assert.Equal(t, exception.CodeValidationFailed.String(), code.Code())
+	assert.Contains(t, err.Error(), "missing property amount")
+	assert.Equal(t, int32(0), calls)
+	assert.Equal(t, int32(1), violations)
+}